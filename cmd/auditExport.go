@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/mongodb"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// auditExportCmd represents the audit-export command
+var auditExportCmd = &cobra.Command{
+	Use:   "audit-export",
+	Short: "Export the security audit log as JSON lines or CSV",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+
+		filter := &domain.AuditLogFilter{}
+		filter.UserId, _ = flags.GetString("user")
+		filter.Email, _ = flags.GetString("email")
+		filter.Event, _ = flags.GetString("event")
+		filter.Outcome, _ = flags.GetString("outcome")
+		filter.IP, _ = flags.GetString("ip")
+		format, _ := flags.GetString("format")
+		output, _ := flags.GetString("output")
+
+		if format != service.AuditExportFormatJSON && format != service.AuditExportFormatCSV {
+			return fmt.Errorf("unsupported format %q, use json or csv", format)
+		}
+
+		for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+			value, _ := flags.GetString(name)
+			if value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return fmt.Errorf("--%s must be an RFC 3339 timestamp: %w", name, err)
+			}
+			*target = &t
+		}
+
+		v := helper.NewValidator()
+		dto.ValidateAuditLogFilter(v, filter)
+		if !v.Valid() {
+			var problems []string
+			for key, message := range v.Errors {
+				problems = append(problems, key+": "+message)
+			}
+			return fmt.Errorf("invalid filter: %s", strings.Join(problems, ", "))
+		}
+
+		cfg, err := config.GetInstance()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		mongo := mongodb.NewMongoDB(
+			mongodb.WithHost(cfg.MongoDB.Host),
+			mongodb.WithPort(cfg.MongoDB.Port),
+			mongodb.WithUser(cfg.MongoDB.User),
+			mongodb.WithPass(cfg.MongoDB.Pass),
+			mongodb.WithDBName(cfg.MongoDB.DBName),
+			mongodb.WithAuthSource(cfg.MongoDB.AuthSource),
+			mongodb.WithMaxPoolSize(cfg.MongoDB.MaxPoolSize),
+			mongodb.WithMinPoolSize(cfg.MongoDB.MinPoolSize),
+			mongodb.WithTimeout(cfg.MongoDB.Timeout),
+			mongodb.WithDirectConnection(cfg.MongoDB.DirectConnection),
+		)
+
+		client, mongodb, err := mongo.Connect()
+		if err != nil {
+			return fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		defer client.Disconnect(context.Background())
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+
+		auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(mongodb, "audit_log"))
+
+		count, err := auditLogService.ExportEvents(cmd.Context(), filter, format, w)
+		if err != nil {
+			return fmt.Errorf("export failed after %d events: %w", count, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "exported %d events\n", count)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditExportCmd)
+
+	auditExportCmd.Flags().String("user", "", "Only events of this user id")
+	auditExportCmd.Flags().String("email", "", "Only events for this email")
+	auditExportCmd.Flags().String("event", "", "Only this event, e.g. login or refresh")
+	auditExportCmd.Flags().String("outcome", "", "Only this outcome: success or failure")
+	auditExportCmd.Flags().String("ip", "", "Only events from this IP")
+	auditExportCmd.Flags().String("from", "", "Only events at or after this RFC 3339 time")
+	auditExportCmd.Flags().String("to", "", "Only events before this RFC 3339 time")
+	auditExportCmd.Flags().String("format", service.AuditExportFormatJSON, "Output format: json or csv")
+	auditExportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+}
@@ -4,16 +4,22 @@ import (
 	"context"
 	"fmt"
 	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/mailer"
 	"github.com/saleh-ghazimoradi/X-Gopher/infra/mongodb"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc"
 	"github.com/saleh-ghazimoradi/X-Gopher/infra/redis"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/routes"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/server"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/worker"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -55,7 +61,7 @@ var httpCmd = &cobra.Command{
 			}
 		}()
 
-		//redisClient := redisDB.Connect(context.Background())
+		redisClient := redisDB.Connect(context.Background())
 
 		mongo := mongodb.NewMongoDB(
 			mongodb.WithHost(cfg.MongoDB.Host),
@@ -67,6 +73,7 @@ var httpCmd = &cobra.Command{
 			mongodb.WithMaxPoolSize(cfg.MongoDB.MaxPoolSize),
 			mongodb.WithMinPoolSize(cfg.MongoDB.MinPoolSize),
 			mongodb.WithTimeout(cfg.MongoDB.Timeout),
+			mongodb.WithDirectConnection(cfg.MongoDB.DirectConnection),
 		)
 
 		client, mongodb, err := mongo.Connect()
@@ -82,42 +89,204 @@ var httpCmd = &cobra.Command{
 			}
 		}()
 
-		middleware := middlewares.NewMiddleware(cfg, logger)
-
 		tokenRepository := repository.NewTokenRepository(mongodb, "token")
 		userRepository := repository.NewUserRepository(mongodb, "user")
+		followRepository := repository.NewFollowRepository(mongodb, "follows", "user")
+		followRequestRepository := repository.NewFollowRequestRepository(mongodb, "followRequests")
+		blockRepository := repository.NewBlockRepository(mongodb, "blocks")
+		muteRepository := repository.NewMuteRepository(mongodb, "mutes")
+		suggestionRepository := repository.NewSuggestionRepository(mongodb, "follows", "user", "post")
+		listRepository := repository.NewListRepository(mongodb, "lists")
+		listSubscriptionRepository := repository.NewListSubscriptionRepository(mongodb, "listSubscriptions")
 		postRepository := repository.NewPostRepository(mongodb, "post")
 		commentRepository := repository.NewCommentRepository(mongodb, "comment")
 		messageRepository := repository.NewMessageRepository(mongodb, "message")
 		unreadMessageRepository := repository.NewUnreadMessageRepository(mongodb, "unreadMessage")
 		notificationRepository := repository.NewNotificationRepository(mongodb, "notification")
+		personalAccessTokenRepository := repository.NewPersonalAccessTokenRepository(mongodb, "personalAccessToken")
+		userIdentityRepository := repository.NewUserIdentityRepository(mongodb, "userIdentity")
+		oidcStateRepository := repository.NewOIDCStateRepository(redisClient, "oidc:state:")
+		oauthClientRepository := repository.NewOAuthClientRepository(mongodb, "oauthClient")
+		oauthGrantRepository := repository.NewOAuthGrantRepository(mongodb, "oauthGrant")
+		oauthTokenRepository := repository.NewOAuthTokenRepository(mongodb, "oauthToken")
+		oauthCodeRepository := repository.NewOAuthCodeRepository(redisClient, "oauth:code:")
+		loginAttemptRepository := repository.NewLoginAttemptRepository(redisClient, "login:attempts:")
+		magicLinkAttemptRepository := repository.NewLoginAttemptRepository(redisClient, "magic_link:attempts:")
+		moderationRepository := repository.NewModerationRepository(mongodb, "moderationLog")
+		verificationTokenRepository := repository.NewVerificationTokenRepository(redisClient, "verification:")
+		auditLogRepository := repository.NewAuditLogRepository(mongodb, "audit_log")
+		dataExportRepository := repository.NewDataExportRepository(mongodb, "dataExport")
+		mediaRepository := repository.NewMediaRepository(mongodb, "media")
+
+		if err := userRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create user indexes", "error", err)
+		}
+
+		if err := followRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create follow indexes", "error", err)
+		}
+
+		if err := followRequestRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create follow request indexes", "error", err)
+		}
+
+		if err := blockRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create block indexes", "error", err)
+		}
+
+		if err := muteRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create mute indexes", "error", err)
+		}
+
+		if err := listRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create list indexes", "error", err)
+		}
+
+		if err := listSubscriptionRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create list subscription indexes", "error", err)
+		}
+
+		if err := mediaRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create media indexes", "error", err)
+		}
+
+		if err := commentRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create comment indexes", "error", err)
+		}
+
+		if err := postRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create post indexes", "error", err)
+		}
 
-		authService := service.NewAuthService(cfg, userRepository, tokenRepository)
-		userService := service.NewUserService(userRepository, notificationRepository)
-		postService := service.NewPostService(userRepository, commentRepository, postRepository, notificationRepository)
-		messageService := service.NewMessageService(messageRepository, unreadMessageRepository)
+		if err := auditLogRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create audit log indexes", "error", err)
+		}
+
+		if err := personalAccessTokenRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create personal access token indexes", "error", err)
+		}
+
+		if err := userIdentityRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create user identity indexes", "error", err)
+		}
+
+		if err := oauthTokenRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create oauth token indexes", "error", err)
+		}
+
+		if err := dataExportRepository.EnsureIndexes(context.Background()); err != nil {
+			logger.Error("Failed to create data export indexes", "error", err)
+		}
+
+		var mail mailer.Mailer = mailer.NewLog(logger)
+		if cfg.Mailer.Host != "" {
+			mail = mailer.NewSMTP(
+				mailer.WithHost(cfg.Mailer.Host),
+				mailer.WithPort(cfg.Mailer.Port),
+				mailer.WithUsername(cfg.Mailer.Username),
+				mailer.WithPassword(cfg.Mailer.Password),
+				mailer.WithFrom(cfg.Mailer.From),
+				mailer.WithTimeout(cfg.Mailer.Timeout),
+			)
+		}
+
+		fileStorage, err := newStorage(cfg)
+		if err != nil {
+			logger.Error("Failed to set up file storage", "error", err)
+			os.Exit(1)
+		}
+
+		auditLogService := service.NewAuditLogService(auditLogRepository)
+		authService := service.NewAuthService(cfg, userRepository, tokenRepository, loginAttemptRepository, notificationRepository, auditLogService)
+		userService := service.NewUserService(cfg, userRepository, followRepository, followRequestRepository, blockRepository, muteRepository, suggestionRepository, notificationRepository, fileStorage)
+		mediaService := service.NewMediaService(cfg, mediaRepository, fileStorage)
+		postService := service.NewPostService(cfg, userRepository, followRepository, blockRepository, muteRepository, commentRepository, postRepository, notificationRepository, mediaService)
+		listService := service.NewListService(listRepository, listSubscriptionRepository, userRepository, blockRepository, muteRepository, notificationRepository, postService)
+		messageService := service.NewMessageService(messageRepository, unreadMessageRepository, blockRepository)
 		notificationService := service.NewNotificationService(notificationRepository)
+		personalAccessTokenService := service.NewPersonalAccessTokenService(personalAccessTokenRepository, auditLogService)
+		oauthService := service.NewOAuthService(cfg, oauthClientRepository, oauthGrantRepository, oauthTokenRepository, oauthCodeRepository, auditLogService)
+		moderationService := service.NewModerationService(userRepository, postRepository, commentRepository, moderationRepository, mediaService)
+		dataExportService := service.NewDataExportService(cfg, dataExportRepository, userRepository, followRepository, postRepository, commentRepository, messageRepository, notificationRepository, tokenRepository, personalAccessTokenRepository, oauthGrantRepository, oauthClientRepository, userIdentityRepository, auditLogRepository)
+		accountService := service.NewAccountService(cfg, userRepository, followRepository, followRequestRepository, blockRepository, muteRepository, postRepository, commentRepository, messageRepository, unreadMessageRepository, notificationRepository, tokenRepository, personalAccessTokenRepository, oauthClientRepository, oauthGrantRepository, oauthTokenRepository, userIdentityRepository, dataExportService, listService, mediaService, fileStorage)
+		magicLinkService := service.NewMagicLinkService(cfg, logger, mail, authService, userRepository, verificationTokenRepository, magicLinkAttemptRepository)
+
+		middleware := middlewares.NewMiddleware(cfg, logger, personalAccessTokenService, oauthService, accountService)
 
 		authHandler := handlers.NewAuthHandler(authService)
-		userHandler := handlers.NewUserHandler(userService, postService)
+		userHandler := handlers.NewUserHandler(userService, postService, accountService)
 		postHandler := handlers.NewPostHandler(postService)
 		messageHandler := handlers.NewMessageHandler(messageService)
 		notificationHandler := handlers.NewNotificationHandler(notificationService)
+		personalAccessTokenHandler := handlers.NewPersonalAccessTokenHandler(personalAccessTokenService)
+		oauthHandler := handlers.NewOAuthHandler(oauthService)
+		moderationHandler := handlers.NewModerationHandler(moderationService)
+		magicLinkHandler := handlers.NewMagicLinkHandler(magicLinkService)
+		auditLogHandler := handlers.NewAuditLogHandler(auditLogService)
+		dataExportHandler := handlers.NewDataExportHandler(dataExportService)
+		listHandler := handlers.NewListHandler(listService)
+		mediaHandler := handlers.NewMediaHandler(mediaService)
 
 		authRoute := routes.NewAuthRoute(authHandler)
 		userRoute := routes.NewUserRoute(middleware, userHandler)
 		postRoute := routes.NewPostRoute(middleware, postHandler)
-		messageRoute := routes.NewMessageRoute(messageHandler)
-		notificationRoute := routes.NewNotificationRoute(notificationHandler)
+		messageRoute := routes.NewMessageRoute(middleware, messageHandler)
+		notificationRoute := routes.NewNotificationRoute(middleware, notificationHandler)
+		personalAccessTokenRoute := routes.NewPersonalAccessTokenRoute(middleware, personalAccessTokenHandler)
+		oauthRoute := routes.NewOAuthRoute(middleware, oauthHandler)
+		moderationRoute := routes.NewModerationRoute(middleware, moderationHandler)
+		magicLinkRoute := routes.NewMagicLinkRoute(magicLinkHandler)
+		auditLogRoute := routes.NewAuditLogRoute(middleware, auditLogHandler)
+		dataExportRoute := routes.NewDataExportRoute(middleware, dataExportHandler)
+		listRoute := routes.NewListRoute(middleware, listHandler)
+		mediaRoute := routes.NewMediaRoute(middleware, mediaHandler)
 
-		register := routes.NewRegister(
+		registerOptions := []routes.Options{
 			routes.WithAuthRoute(authRoute),
 			routes.WithUserRoute(userRoute),
 			routes.WithPostRoute(postRoute),
 			routes.WithMessageRoute(messageRoute),
 			routes.WithNotificationRoute(notificationRoute),
+			routes.WithPersonalAccessTokenRoute(personalAccessTokenRoute),
+			routes.WithOAuthRoute(oauthRoute),
+			routes.WithModerationRoute(moderationRoute),
+			routes.WithMagicLinkRoute(magicLinkRoute),
+			routes.WithAuditLogRoute(auditLogRoute),
+			routes.WithDataExportRoute(dataExportRoute),
+			routes.WithListRoute(listRoute),
+			routes.WithMediaRoute(mediaRoute),
 			routes.WithMiddlewares(middleware),
-		)
+		}
+
+		// S3 buckets serve their own files; local storage is served by the API.
+		if local, ok := fileStorage.(*storage.Local); ok {
+			registerOptions = append(registerOptions, routes.WithUploads(local))
+		}
+
+		if cfg.OIDC.Enabled {
+			provider := oidc.NewProvider(
+				oidc.WithIssuerURL(cfg.OIDC.IssuerURL),
+				oidc.WithClientID(cfg.OIDC.ClientID),
+				oidc.WithClientSecret(cfg.OIDC.ClientSecret),
+				oidc.WithRedirectURL(cfg.OIDC.RedirectURL),
+				oidc.WithScopes(cfg.OIDC.Scopes),
+				oidc.WithLeeway(cfg.JWT.Leeway),
+				oidc.WithHTTPClient(&http.Client{Timeout: 10 * time.Second}),
+			)
+			oidcService := service.NewOIDCService(cfg, provider, authService, userRepository, userIdentityRepository, oidcStateRepository, auditLogService)
+			oidcHandler := handlers.NewOIDCHandler(oidcService)
+			oidcRoute := routes.NewOIDCRoute(oidcHandler)
+			registerOptions = append(registerOptions, routes.WithOIDCRoute(oidcRoute))
+		}
+
+		register := routes.NewRegister(registerOptions...)
+
+		workerCtx, stopWorkers := context.WithCancel(context.Background())
+		defer stopWorkers()
+
+		go worker.NewAccountPurge(accountService, cfg.Account.PurgeInterval, logger).Run(workerCtx)
+		go worker.NewDataExportCleanup(dataExportService, cfg.DataExport.CleanupInterval, logger).Run(workerCtx)
+		go worker.NewMediaCleanup(mediaService, cfg.Media.CleanupInterval, logger).Run(workerCtx)
 
 		httpServer := server.NewHTTPServer(
 			server.WithHost(cfg.HTTPServer.Host),
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/mongodb"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// migrateFollowsCmd represents the migrate-follows command
+var migrateFollowsCmd = &cobra.Command{
+	Use:   "migrate-follows",
+	Short: "Move embedded followers and following arrays into the follows collection",
+	Long: `Creates one follows document per edge found in the followers and following
+arrays of user documents, recomputes every user's follower and following
+counts and removes the arrays. The command can be run again safely.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetInstance()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		mongo := mongodb.NewMongoDB(
+			mongodb.WithHost(cfg.MongoDB.Host),
+			mongodb.WithPort(cfg.MongoDB.Port),
+			mongodb.WithUser(cfg.MongoDB.User),
+			mongodb.WithPass(cfg.MongoDB.Pass),
+			mongodb.WithDBName(cfg.MongoDB.DBName),
+			mongodb.WithAuthSource(cfg.MongoDB.AuthSource),
+			mongodb.WithMaxPoolSize(cfg.MongoDB.MaxPoolSize),
+			mongodb.WithMinPoolSize(cfg.MongoDB.MinPoolSize),
+			mongodb.WithTimeout(cfg.MongoDB.Timeout),
+			mongodb.WithDirectConnection(cfg.MongoDB.DirectConnection),
+		)
+
+		client, mongodb, err := mongo.Connect()
+		if err != nil {
+			return fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		defer client.Disconnect(context.Background())
+
+		followRepository := repository.NewFollowRepository(mongodb, "follows", "user")
+
+		// The unique index keeps reruns from creating duplicate edges.
+		if err := followRepository.EnsureIndexes(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to create follow indexes: %w", err)
+		}
+
+		edges, users, err := followRepository.MigrateEmbedded(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("migration failed after %d new edges and %d users: %w", edges, users, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "created %d follow edges, updated counters of %d users\n", edges, users)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateFollowsCmd)
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/mongodb"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// migrateMediaCmd represents the migrate-media command
+var migrateMediaCmd = &cobra.Command{
+	Use:   "migrate-media",
+	Short: "Move base64 images embedded in posts into media storage",
+	Long: `Decodes the base64 image each post used to carry in selected_file, stores
+it as media in the configured file storage and attaches it to the post in
+place of the payload. Payloads that aren't a valid image are left in place
+and reported. The command can be run again safely.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetInstance()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+
+		mongo := mongodb.NewMongoDB(
+			mongodb.WithHost(cfg.MongoDB.Host),
+			mongodb.WithPort(cfg.MongoDB.Port),
+			mongodb.WithUser(cfg.MongoDB.User),
+			mongodb.WithPass(cfg.MongoDB.Pass),
+			mongodb.WithDBName(cfg.MongoDB.DBName),
+			mongodb.WithAuthSource(cfg.MongoDB.AuthSource),
+			mongodb.WithMaxPoolSize(cfg.MongoDB.MaxPoolSize),
+			mongodb.WithMinPoolSize(cfg.MongoDB.MinPoolSize),
+			mongodb.WithTimeout(cfg.MongoDB.Timeout),
+			mongodb.WithDirectConnection(cfg.MongoDB.DirectConnection),
+		)
+
+		client, mongodb, err := mongo.Connect()
+		if err != nil {
+			return fmt.Errorf("failed to connect to MongoDB: %w", err)
+		}
+		defer client.Disconnect(context.Background())
+
+		fileStorage, err := newStorage(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to set up file storage: %w", err)
+		}
+
+		postRepository := repository.NewPostRepository(mongodb, "post")
+		mediaRepository := repository.NewMediaRepository(mongodb, "media")
+		mediaService := service.NewMediaService(cfg, mediaRepository, fileStorage)
+
+		migrated, failed, err := postRepository.MigrateLegacyFiles(cmd.Context(), mediaService.ImportLegacy)
+		if err != nil {
+			return fmt.Errorf("migration failed after %d posts: %w", migrated, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "migrated %d posts, %d payloads could not be converted\n", migrated, failed)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateMediaCmd)
+}
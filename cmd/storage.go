@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
+)
+
+// newStorage builds the file storage selected by STORAGE_DRIVER.
+func newStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Driver {
+	case "local":
+		return storage.NewLocal(
+			storage.WithDir(cfg.Storage.Dir),
+			storage.WithBaseURL(cfg.Storage.BaseURL),
+		), nil
+	case "s3":
+		return storage.NewS3(
+			storage.WithS3Endpoint(cfg.Storage.S3Endpoint),
+			storage.WithS3Region(cfg.Storage.S3Region),
+			storage.WithS3Bucket(cfg.Storage.S3Bucket),
+			storage.WithS3AccessKey(cfg.Storage.S3AccessKey),
+			storage.WithS3SecretKey(cfg.Storage.S3SecretKey),
+			storage.WithS3PathStyle(cfg.Storage.S3PathStyle),
+			storage.WithS3PublicURL(cfg.Storage.S3PublicURL),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
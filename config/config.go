@@ -19,6 +19,17 @@ type Config struct {
 	JWT         JWT
 	RateLimiter RateLimiter
 	Redis       Redis
+	OIDC        OIDC
+	OAuth       OAuth
+	Login       Login
+	Mailer      Mailer
+	MagicLink   MagicLink
+	Account     Account
+	DataExport  DataExport
+	Storage     Storage
+	Upload      Upload
+	Media       Media
+	Comment     Comment
 }
 
 type Application struct {
@@ -36,6 +47,9 @@ type MongoDB struct {
 	MaxPoolSize uint64        `env:"MONGODB_MAX_POOL_SIZE"`
 	MinPoolSize uint64        `env:"MONGODB_MIN_POOL_SIZE"`
 	Timeout     time.Duration `env:"MONGODB_TIMEOUT"`
+	// DirectConnection connects to the configured host only instead of discovering
+	// the replica set, for a single-node replica set reachable under another name.
+	DirectConnection bool `env:"MONGODB_DIRECT_CONNECTION" envDefault:"false"`
 }
 
 type Redis struct {
@@ -63,6 +77,104 @@ type JWT struct {
 	Secret              string        `env:"JWT_SECRET"`
 	ExpiresIn           time.Duration `env:"JWT_EXPIRES_IN"`
 	RefreshTokenExpires time.Duration `env:"JWT_REFRESH_TOKEN_EXPIRES"`
+	Issuer              string        `env:"JWT_ISSUER" envDefault:"x-gopher"`
+	Audience            string        `env:"JWT_AUDIENCE" envDefault:"x-gopher-api"`
+	Leeway              time.Duration `env:"JWT_LEEWAY" envDefault:"30s"`
+}
+
+type OIDC struct {
+	Enabled      bool          `env:"OIDC_ENABLED"`
+	Provider     string        `env:"OIDC_PROVIDER" envDefault:"oidc"`
+	IssuerURL    string        `env:"OIDC_ISSUER_URL"`
+	ClientID     string        `env:"OIDC_CLIENT_ID"`
+	ClientSecret string        `env:"OIDC_CLIENT_SECRET"`
+	RedirectURL  string        `env:"OIDC_REDIRECT_URL"`
+	Scopes       []string      `env:"OIDC_SCOPES" envSeparator:"," envDefault:"openid,email,profile"`
+	StateTTL     time.Duration `env:"OIDC_STATE_TTL" envDefault:"10m"`
+}
+
+type OAuth struct {
+	CodeTTL         time.Duration `env:"OAUTH_CODE_TTL" envDefault:"1m"`
+	AccessTokenTTL  time.Duration `env:"OAUTH_ACCESS_TOKEN_TTL" envDefault:"1h"`
+	RefreshTokenTTL time.Duration `env:"OAUTH_REFRESH_TOKEN_TTL" envDefault:"720h"`
+}
+
+type Login struct {
+	Window          time.Duration `env:"LOGIN_WINDOW" envDefault:"15m"`
+	FreeAttempts    int           `env:"LOGIN_FREE_ATTEMPTS" envDefault:"3"`
+	MaxAttempts     int           `env:"LOGIN_MAX_ATTEMPTS" envDefault:"10"`
+	IPMaxAttempts   int           `env:"LOGIN_IP_MAX_ATTEMPTS" envDefault:"50"`
+	BaseDelay       time.Duration `env:"LOGIN_BASE_DELAY" envDefault:"1s"`
+	MaxDelay        time.Duration `env:"LOGIN_MAX_DELAY" envDefault:"1m"`
+	LockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+}
+
+type Mailer struct {
+	Host     string        `env:"SMTP_HOST"`
+	Port     string        `env:"SMTP_PORT" envDefault:"587"`
+	Username string        `env:"SMTP_USERNAME"`
+	Password string        `env:"SMTP_PASSWORD"`
+	From     string        `env:"SMTP_FROM" envDefault:"X-Gopher <no-reply@x-gopher.local>"`
+	Timeout  time.Duration `env:"SMTP_TIMEOUT" envDefault:"10s"`
+}
+
+type MagicLink struct {
+	URL           string        `env:"MAGIC_LINK_URL" envDefault:"http://localhost:3000/auth/magic-link"`
+	TTL           time.Duration `env:"MAGIC_LINK_TTL" envDefault:"15m"`
+	Window        time.Duration `env:"MAGIC_LINK_WINDOW" envDefault:"15m"`
+	MaxRequests   int           `env:"MAGIC_LINK_MAX_REQUESTS" envDefault:"3"`
+	IPMaxRequests int           `env:"MAGIC_LINK_IP_MAX_REQUESTS" envDefault:"20"`
+}
+
+type Account struct {
+	GracePeriod          time.Duration `env:"ACCOUNT_GRACE_PERIOD" envDefault:"720h"`
+	PurgeInterval        time.Duration `env:"ACCOUNT_PURGE_INTERVAL" envDefault:"1h"`
+	HandleChangeCooldown time.Duration `env:"ACCOUNT_HANDLE_CHANGE_COOLDOWN" envDefault:"168h"`
+}
+
+type DataExport struct {
+	Dir             string        `env:"DATA_EXPORT_DIR" envDefault:"./exports"`
+	DownloadURL     string        `env:"DATA_EXPORT_DOWNLOAD_URL" envDefault:"http://localhost:8080/v1/exports"`
+	Retention       time.Duration `env:"DATA_EXPORT_RETENTION" envDefault:"168h"`
+	LinkTTL         time.Duration `env:"DATA_EXPORT_LINK_TTL" envDefault:"24h"`
+	SigningKey      string        `env:"DATA_EXPORT_SIGNING_KEY"`
+	CleanupInterval time.Duration `env:"DATA_EXPORT_CLEANUP_INTERVAL" envDefault:"1h"`
+	BuildTimeout    time.Duration `env:"DATA_EXPORT_BUILD_TIMEOUT" envDefault:"30m"`
+}
+
+// Storage configures where uploaded files are kept. Driver is "local", which
+// stores files in Dir and serves them from BaseURL, or "s3" for an S3
+// compatible bucket.
+type Storage struct {
+	Driver      string `env:"STORAGE_DRIVER" envDefault:"local"`
+	Dir         string `env:"STORAGE_DIR" envDefault:"./uploads"`
+	BaseURL     string `env:"STORAGE_BASE_URL" envDefault:"http://localhost:8080/uploads"`
+	S3Endpoint  string `env:"STORAGE_S3_ENDPOINT"`
+	S3Region    string `env:"STORAGE_S3_REGION" envDefault:"us-east-1"`
+	S3Bucket    string `env:"STORAGE_S3_BUCKET"`
+	S3AccessKey string `env:"STORAGE_S3_ACCESS_KEY"`
+	S3SecretKey string `env:"STORAGE_S3_SECRET_KEY"`
+	S3PathStyle bool   `env:"STORAGE_S3_PATH_STYLE" envDefault:"true"`
+	S3PublicURL string `env:"STORAGE_S3_PUBLIC_URL"`
+}
+
+type Upload struct {
+	MaxImageSize int64 `env:"UPLOAD_MAX_IMAGE_SIZE" envDefault:"5242880"`
+}
+
+type Media struct {
+	MaxSize int64 `env:"MEDIA_MAX_SIZE" envDefault:"15728640"`
+	// ChunkSize is the largest chunk a resumable upload accepts per request.
+	ChunkSize       int64         `env:"MEDIA_CHUNK_SIZE" envDefault:"1048576"`
+	MaxDimension    int           `env:"MEDIA_MAX_DIMENSION" envDefault:"2048"`
+	ThumbnailSize   int           `env:"MEDIA_THUMBNAIL_SIZE" envDefault:"400"`
+	UnattachedTTL   time.Duration `env:"MEDIA_UNATTACHED_TTL" envDefault:"24h"`
+	CleanupInterval time.Duration `env:"MEDIA_CLEANUP_INTERVAL" envDefault:"1h"`
+}
+
+type Comment struct {
+	// EditWindow is how long after posting a comment its author may still edit it.
+	EditWindow time.Duration `env:"COMMENT_EDIT_WINDOW" envDefault:"15m"`
 }
 
 type RateLimiter struct {
@@ -0,0 +1,138 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails such as magic links and verification codes.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+type Options func(*SMTP)
+
+func WithHost(host string) Options {
+	return func(s *SMTP) {
+		s.Host = host
+	}
+}
+
+func WithPort(port string) Options {
+	return func(s *SMTP) {
+		s.Port = port
+	}
+}
+
+func WithUsername(username string) Options {
+	return func(s *SMTP) {
+		s.Username = username
+	}
+}
+
+func WithPassword(password string) Options {
+	return func(s *SMTP) {
+		s.Password = password
+	}
+}
+
+func WithFrom(from string) Options {
+	return func(s *SMTP) {
+		s.From = from
+	}
+}
+
+func WithTimeout(timeout time.Duration) Options {
+	return func(s *SMTP) {
+		s.Timeout = timeout
+	}
+}
+
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("mailer: header values must not contain line breaks")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, s.From, []string{msg.To}, []byte(b.String()))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("mailer: %w", ctx.Err())
+	}
+}
+
+func NewSMTP(opts ...Options) *SMTP {
+	s := &SMTP{
+		Timeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Log writes emails to the logger instead of sending them. It is meant for
+// local development where no SMTP server is configured.
+type Log struct {
+	logger *slog.Logger
+}
+
+// linkQueryRX matches the query string of links, where emails carry their
+// single-use tokens.
+var linkQueryRX = regexp.MustCompile(`(https?://[^\s?#]+)\?[^\s#]+`)
+
+// Send logs the message with link query strings redacted, so live tokens do
+// not end up in log storage.
+func (l *Log) Send(ctx context.Context, msg *Message) error {
+	body := linkQueryRX.ReplaceAllString(msg.Body, "$1?REDACTED")
+	l.logger.InfoContext(ctx, "email not sent, no SMTP server configured", "to", msg.To, "subject", msg.Subject, "body", body)
+	return nil
+}
+
+func NewLog(logger *slog.Logger) *Log {
+	return &Log{
+		logger: logger,
+	}
+}
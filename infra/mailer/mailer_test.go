@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogSendRedactsLinks(t *testing.T) {
+	var buf bytes.Buffer
+	mail := NewLog(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	err := mail.Send(context.Background(), &Message{
+		To:      "jane@example.com",
+		Subject: "Your X-Gopher login link",
+		Body:    "Hi Jane,\n\nhttps://app.example.com/auth/magic-link?token=xgm_secret123&next=%2F\n\nhttp://localhost:3000/verify?code=654321#top\n",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	logged := buf.String()
+	for _, secret := range []string{"xgm_secret123", "654321"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("expected %q to be redacted, got %s", secret, logged)
+		}
+	}
+	for _, kept := range []string{"https://app.example.com/auth/magic-link?REDACTED", "http://localhost:3000/verify?REDACTED#top", "jane@example.com"} {
+		if !strings.Contains(logged, kept) {
+			t.Errorf("expected log to contain %q, got %s", kept, logged)
+		}
+	}
+}
@@ -18,6 +18,7 @@ type MongoDB struct {
 	MaxPoolSize uint64
 	MinPoolSize uint64
 	Timeout     time.Duration
+	Direct      bool
 }
 
 type Options func(*MongoDB)
@@ -76,6 +77,12 @@ func WithTimeout(timeout time.Duration) Options {
 	}
 }
 
+func WithDirectConnection(direct bool) Options {
+	return func(m *MongoDB) {
+		m.Direct = direct
+	}
+}
+
 func (m *MongoDB) uri() string {
 	return fmt.Sprintf("mongodb://%s:%s@%s:%s/%s?authSource=%s", m.User, m.Pass, m.Host, m.Port, m.DBName, m.AuthSource)
 }
@@ -85,6 +92,9 @@ func (m *MongoDB) Connect() (*mongo.Client, *mongo.Database, error) {
 	defer cancel()
 
 	clientOptions := options.Client().ApplyURI(m.uri()).SetMaxPoolSize(m.MaxPoolSize).SetMinPoolSize(m.MinPoolSize)
+	if m.Direct {
+		clientOptions.SetDirect(true)
+	}
 	client, err := mongo.Connect(clientOptions)
 	if err != nil {
 		return nil, nil, err
@@ -0,0 +1,195 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc/oidctest"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestProvider(issuer *oidctest.Issuer) *oidc.Provider {
+	return oidc.NewProvider(
+		oidc.WithIssuerURL(issuer.URL()),
+		oidc.WithClientID(issuer.ClientID),
+		oidc.WithClientSecret(issuer.ClientSecret),
+		oidc.WithRedirectURL("https://app.example.com/callback"),
+		oidc.WithScopes([]string{"openid", "email"}),
+		oidc.WithLeeway(time.Second),
+		oidc.WithHTTPClient(issuer.Client()),
+	)
+}
+
+func TestProviderAuthCodeURL(t *testing.T) {
+	issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+	provider := newTestProvider(issuer)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	if err != nil {
+		t.Fatalf("auth code url: %v", err)
+	}
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth code url: %v", err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != issuer.URL()+"/authorize" {
+		t.Errorf("expected authorization endpoint, got %q", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"redirect_uri":          "https://app.example.com/callback",
+		"scope":                 "openid email",
+		"state":                 "state",
+		"nonce":                 "nonce",
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := u.Query().Get(key); got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestProviderExchangeAndVerify(t *testing.T) {
+	ctx := context.Background()
+	verifier, err := utils.GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("generate verifier: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		claims      oidc.IDTokenClaims
+		verifier    string
+		nonce       string
+		exchangeErr bool
+		verifyErr   error
+	}{
+		{
+			name: "valid",
+			claims: oidc.IDTokenClaims{
+				Email:            "jane@example.com",
+				EmailVerified:    true,
+				RegisteredClaims: jwt.RegisteredClaims{Subject: "subject-1"},
+			},
+			verifier: verifier,
+			nonce:    "nonce",
+		},
+		{
+			name:        "pkce verifier mismatch",
+			claims:      oidc.IDTokenClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "subject-1"}},
+			verifier:    verifier + "x",
+			nonce:       "nonce",
+			exchangeErr: true,
+		},
+		{
+			name:      "nonce mismatch",
+			claims:    oidc.IDTokenClaims{Nonce: "replayed", RegisteredClaims: jwt.RegisteredClaims{Subject: "subject-1"}},
+			verifier:  verifier,
+			nonce:     "nonce",
+			verifyErr: oidc.ErrInvalidNonce,
+		},
+		{
+			name: "wrong audience",
+			claims: oidc.IDTokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+				Subject:  "subject-1",
+				Audience: jwt.ClaimStrings{"another-client"},
+			}},
+			verifier:  verifier,
+			nonce:     "nonce",
+			verifyErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name: "expired",
+			claims: oidc.IDTokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "subject-1",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+			}},
+			verifier:  verifier,
+			nonce:     "nonce",
+			verifyErr: jwt.ErrTokenExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+			provider := newTestProvider(issuer)
+
+			authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", utils.CodeChallengeS256(verifier))
+			if err != nil {
+				t.Fatalf("auth code url: %v", err)
+			}
+			code := issuer.Authorize(t, authURL, tt.claims)
+
+			token, err := provider.Exchange(ctx, code, tt.verifier)
+			if tt.exchangeErr {
+				if err == nil {
+					t.Fatal("expected exchange to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("exchange: %v", err)
+			}
+
+			claims, err := provider.VerifyIDToken(ctx, token.IDToken, tt.nonce)
+			if tt.verifyErr != nil {
+				if !errors.Is(err, tt.verifyErr) {
+					t.Fatalf("expected %v, got %v", tt.verifyErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify id token: %v", err)
+			}
+			if claims.Subject != tt.claims.Subject || claims.Email != tt.claims.Email || claims.EmailVerified != tt.claims.EmailVerified {
+				t.Errorf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestProviderCodeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+	provider := newTestProvider(issuer)
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", utils.CodeChallengeS256("verifier"))
+	if err != nil {
+		t.Fatalf("auth code url: %v", err)
+	}
+	code := issuer.Authorize(t, authURL, oidc.IDTokenClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "subject-1"}})
+
+	if _, err := provider.Exchange(ctx, code, "verifier"); err != nil {
+		t.Fatalf("first exchange: %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, "verifier"); err == nil {
+		t.Fatal("expected second exchange to fail")
+	}
+}
+
+func TestProviderRejectsUnknownClient(t *testing.T) {
+	ctx := context.Background()
+	issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+	provider := newTestProvider(issuer)
+	provider.ClientSecret = "wrong-secret"
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", utils.CodeChallengeS256("verifier"))
+	if err != nil {
+		t.Fatalf("auth code url: %v", err)
+	}
+	code := issuer.Authorize(t, authURL, oidc.IDTokenClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "subject-1"}})
+
+	if _, err := provider.Exchange(ctx, code, "verifier"); err == nil {
+		t.Fatal("expected exchange with a wrong client secret to fail")
+	}
+}
@@ -0,0 +1,189 @@
+// Package oidctest provides an in-process OpenID Connect issuer for tests.
+// It serves discovery, JWKS and the token endpoint and enforces PKCE (S256)
+// the way a real provider would.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+const keyId = "oidctest-key"
+
+type Issuer struct {
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+type grant struct {
+	codeChallenge string
+	claims        oidc.IDTokenClaims
+}
+
+// URL returns the issuer identifier, which is also the discovery base URL.
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+// Client returns an HTTP client that talks to the issuer.
+func (i *Issuer) Client() *http.Client {
+	return i.server.Client()
+}
+
+// Authorize plays the user approving the login at the provider. It reads the
+// PKCE challenge and nonce from an authorization URL and returns a code that
+// redeems to an ID token with the given claims. Issuer, audience, expiry and
+// nonce are filled in unless the claims already set them.
+func (i *Issuer) Authorize(t testing.TB, authURL string, claims oidc.IDTokenClaims) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("oidctest: parse authorization url: %v", err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("oidctest: unexpected code_challenge_method %q", query.Get("code_challenge_method"))
+	}
+
+	if claims.Issuer == "" {
+		claims.Issuer = i.URL()
+	}
+	if len(claims.Audience) == 0 {
+		claims.Audience = jwt.ClaimStrings{i.ClientID}
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	}
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(time.Now())
+	}
+	if claims.Nonce == "" {
+		claims.Nonce = query.Get("nonce")
+	}
+
+	code := rand.Text()
+	i.mu.Lock()
+	i.codes[code] = grant{codeChallenge: query.Get("code_challenge"), claims: claims}
+	i.mu.Unlock()
+
+	return code
+}
+
+func (i *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 i.URL(),
+		"authorization_endpoint": i.URL() + "/authorize",
+		"token_endpoint":         i.URL() + "/token",
+		"jwks_uri":               i.URL() + "/jwks",
+	})
+}
+
+func (i *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := i.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyId,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != url.QueryEscape(i.ClientID) || clientSecret != url.QueryEscape(i.ClientSecret) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	// Codes are single use, whether or not the exchange succeeds.
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	g, ok := i.codes[code]
+	delete(i.codes, code)
+	i.mu.Unlock()
+
+	if !ok || !utils.VerifyCodeChallengeS256(r.PostForm.Get("code_verifier"), g.codeChallenge) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, g.claims)
+	token.Header["kid"] = keyId
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: rand.Text(),
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   3600,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// NewIssuer starts an issuer that is shut down when the test ends.
+func NewIssuer(t testing.TB, clientID, clientSecret string) *Issuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("oidctest: generate key: %v", err)
+	}
+
+	issuer := &Issuer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3 stores objects in a bucket of any S3 compatible service, such as AWS S3
+// or MinIO. Requests are signed with AWS Signature Version 4.
+type S3 struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket as endpoint/bucket instead of
+	// bucket.endpoint, which MinIO and most self-hosted services expect.
+	PathStyle bool
+	// PublicURL is where objects can be read from, e.g. a CDN in front of the bucket.
+	PublicURL  string
+	HTTPClient *http.Client
+}
+
+type S3Options func(*S3)
+
+func WithS3Endpoint(endpoint string) S3Options {
+	return func(s *S3) {
+		s.Endpoint = strings.TrimRight(endpoint, "/")
+	}
+}
+
+func WithS3Region(region string) S3Options {
+	return func(s *S3) {
+		s.Region = region
+	}
+}
+
+func WithS3Bucket(bucket string) S3Options {
+	return func(s *S3) {
+		s.Bucket = bucket
+	}
+}
+
+func WithS3AccessKey(accessKey string) S3Options {
+	return func(s *S3) {
+		s.AccessKey = accessKey
+	}
+}
+
+func WithS3SecretKey(secretKey string) S3Options {
+	return func(s *S3) {
+		s.SecretKey = secretKey
+	}
+}
+
+func WithS3PathStyle(pathStyle bool) S3Options {
+	return func(s *S3) {
+		s.PathStyle = pathStyle
+	}
+}
+
+func WithS3PublicURL(publicURL string) S3Options {
+	return func(s *S3) {
+		s.PublicURL = strings.TrimRight(publicURL, "/")
+	}
+}
+
+func WithS3HTTPClient(client *http.Client) S3Options {
+	return func(s *S3) {
+		s.HTTPClient = client
+	}
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	// S3 needs the length and, for signing, the hash of the body up front.
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, body, map[string]string{"Content-Type": contentType})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp)
+	}
+
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.responseError(resp)
+	}
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError(resp)
+	}
+
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	return s.PublicURL + "/" + key
+}
+
+func (s *S3) Key(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.PublicURL+"/")
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body []byte, headers map[string]string) (*http.Response, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("storage: invalid endpoint: %w", err)
+	}
+
+	host := endpoint.Host
+	path := "/" + key
+	if s.PathStyle {
+		path = "/" + s.Bucket + path
+	} else {
+		host = s.Bucket + "." + host
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.Scheme+"://"+host+uriEncode(path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	s.sign(req, host, uriEncode(path), body, time.Now().UTC())
+
+	return s.HTTPClient.Do(req)
+}
+
+// sign adds the Signature Version 4 headers to req.
+func (s *S3) sign(req *http.Request, host, path string, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"",
+		"host:" + host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+func (s *S3) responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: s3 responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// uriEncode percent-encodes a path the way Signature Version 4 expects:
+// everything but unreserved characters and slashes.
+func uriEncode(path string) string {
+	var b strings.Builder
+	for _, c := range []byte(path) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func NewS3(opts ...S3Options) *S3 {
+	s := &S3{
+		Region:     "us-east-1",
+		PathStyle:  true,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.PublicURL == "" && s.PathStyle {
+		s.PublicURL = s.Endpoint + "/" + s.Bucket
+	}
+	return s
+}
@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "test-access-key"
+	testSecretKey = "test-secret-key"
+	testRegion    = "eu-test-1"
+	testBucket    = "x-gopher"
+)
+
+type s3Object struct {
+	body        []byte
+	contentType string
+}
+
+// s3Stub is a minimal S3 endpoint. It checks the Signature Version 4
+// signature of every request, rebuilt from what actually arrived, and keeps
+// objects in memory.
+type s3Stub struct {
+	pathStyle bool
+
+	mu       sync.Mutex
+	objects  map[string]s3Object
+	rejected []error
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.verify(r, body); err != nil {
+		s.mu.Lock()
+		s.rejected = append(s.rejected, fmt.Errorf("%s %s: %w", r.Method, r.URL.Path, err))
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+
+	key, ok := s.objectKey(r)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = s3Object{body: body, contentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		_, _ = w.Write(object.body)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// objectKey returns the decoded object key addressed by the request.
+func (s *s3Stub) objectKey(r *http.Request) (string, bool) {
+	if s.pathStyle {
+		return strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	}
+	if !strings.HasPrefix(r.Host, testBucket+".") {
+		return "", false
+	}
+	return strings.TrimPrefix(r.URL.Path, "/"), true
+}
+
+func (s *s3Stub) verify(r *http.Request, body []byte) error {
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != sha256Hex(body) {
+		return fmt.Errorf("payload hash %q does not match the body", got)
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil {
+		return fmt.Errorf("invalid X-Amz-Date %q", amzDate)
+	}
+	if d := time.Since(signedAt); d > time.Minute || d < -time.Minute {
+		return fmt.Errorf("request signed at %s", signedAt)
+	}
+
+	date := amzDate[:8]
+	scope := date + "/" + testRegion + "/s3/aws4_request"
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		"host:" + r.Host,
+		"x-amz-content-sha256:" + r.Header.Get("X-Amz-Content-Sha256"),
+		"x-amz-date:" + amzDate,
+		"",
+		"host;x-amz-content-sha256;x-amz-date",
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+testSecretKey), date)
+	for _, part := range []string{testRegion, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	want := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=%x",
+		testAccessKey, scope, hmacSHA256(key, stringToSign))
+	if got := r.Header.Get("Authorization"); got != want {
+		return fmt.Errorf("authorization %q, want %q", got, want)
+	}
+
+	return nil
+}
+
+// newTestS3 starts a stub and returns an S3 client for it. Virtual hosted
+// requests for bucket.127.0.0.1 are dialled to the stub.
+func newTestS3(t *testing.T, pathStyle bool, opts ...S3Options) (*S3, *s3Stub) {
+	t.Helper()
+
+	stub := &s3Stub{pathStyle: pathStyle, objects: make(map[string]s3Object)}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	addr := server.Listener.Addr().String()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+
+	opts = append([]S3Options{
+		WithS3Endpoint(server.URL),
+		WithS3Region(testRegion),
+		WithS3Bucket(testBucket),
+		WithS3AccessKey(testAccessKey),
+		WithS3SecretKey(testSecretKey),
+		WithS3PathStyle(pathStyle),
+		WithS3HTTPClient(client),
+	}, opts...)
+
+	return NewS3(opts...), stub
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+
+	for _, pathStyle := range []bool{true, false} {
+		t.Run(fmt.Sprintf("path style %v", pathStyle), func(t *testing.T) {
+			s3, stub := newTestS3(t, pathStyle, WithS3PublicURL("https://cdn.example.com/"))
+
+			keys := []string{
+				"avatars/1/photo.jpg",
+				"media/1/2/parts/1048576",
+				"media/odd name/ü+&=.jpg",
+			}
+			for _, key := range keys {
+				if err := s3.Put(ctx, key, strings.NewReader("data:"+key), "image/jpeg"); err != nil {
+					t.Fatalf("put %q: %v", key, err)
+				}
+
+				if got := stub.objects[key]; string(got.body) != "data:"+key || got.contentType != "image/jpeg" {
+					t.Errorf("stored %q as %+v", key, got)
+				}
+
+				r, err := s3.Get(ctx, key)
+				if err != nil {
+					t.Fatalf("get %q: %v", key, err)
+				}
+				body, err := io.ReadAll(r)
+				r.Close()
+				if err != nil || string(body) != "data:"+key {
+					t.Errorf("get %q returned %q (%v)", key, body, err)
+				}
+
+				if err := s3.Delete(ctx, key); err != nil {
+					t.Fatalf("delete %q: %v", key, err)
+				}
+				if _, err := s3.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+					t.Errorf("expected %q to be gone, got %v", key, err)
+				}
+			}
+
+			// Deleting a missing object succeeds, as S3 itself does.
+			if err := s3.Delete(ctx, "missing.jpg"); err != nil {
+				t.Errorf("delete missing object: %v", err)
+			}
+
+			for _, err := range stub.rejected {
+				t.Errorf("stub rejected request: %v", err)
+			}
+		})
+	}
+}
+
+func TestS3RejectedSignature(t *testing.T) {
+	s3, stub := newTestS3(t, true, WithS3SecretKey("wrong-secret"))
+
+	err := s3.Put(context.Background(), "avatars/1/photo.jpg", strings.NewReader("data"), "image/jpeg")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("expected a signature error, got %v", err)
+	}
+	if len(stub.rejected) != 1 || len(stub.objects) != 0 {
+		t.Errorf("expected the put to be rejected, got %d rejections and %d objects", len(stub.rejected), len(stub.objects))
+	}
+}
+
+func TestS3URLAndKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []S3Options
+		wantURL string
+	}{
+		{
+			name:    "path style default",
+			opts:    []S3Options{WithS3Endpoint("http://minio:9000/"), WithS3Bucket(testBucket)},
+			wantURL: "http://minio:9000/" + testBucket + "/avatars/1/photo.jpg",
+		},
+		{
+			name:    "public url",
+			opts:    []S3Options{WithS3Endpoint("https://s3.amazonaws.com"), WithS3Bucket(testBucket), WithS3PublicURL("https://cdn.example.com/")},
+			wantURL: "https://cdn.example.com/avatars/1/photo.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3 := NewS3(tt.opts...)
+
+			url := s3.URL("avatars/1/photo.jpg")
+			if url != tt.wantURL {
+				t.Errorf("expected url %q, got %q", tt.wantURL, url)
+			}
+
+			if key, ok := s3.Key(url); !ok || key != "avatars/1/photo.jpg" {
+				t.Errorf("expected key back from %q, got %q (%v)", url, key, ok)
+			}
+			if _, ok := s3.Key("https://lh3.googleusercontent.com/a/photo.jpg"); ok {
+				t.Error("expected foreign url to be rejected")
+			}
+		})
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	tests := map[string]string{
+		"/bucket/avatars/1/photo.jpg": "/bucket/avatars/1/photo.jpg",
+		"/bucket/odd name/ü+&=.jpg":   "/bucket/odd%20name/%C3%BC%2B%26%3D.jpg",
+		"/bucket/a~b_c-d.e":           "/bucket/a~b_c-d.e",
+	}
+
+	for in, want := range tests {
+		if got := uriEncode(in); got != want {
+			t.Errorf("uriEncode(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if _, err := url.Parse("http://host" + uriEncode("/bucket/odd name/ü+&=.jpg")); err != nil {
+		t.Errorf("encoded path is not a valid url: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage keeps uploaded files such as avatars, banners and post media. Keys
+// are slash separated paths like "avatars/<user id>/<name>.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the public address the object is served from.
+	URL(key string) string
+	// Key reverses URL. It reports false for URLs this storage did not produce.
+	Key(url string) (string, bool)
+}
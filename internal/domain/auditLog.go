@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+const (
+	AuditEventSignup            = "signup"
+	AuditEventLogin             = "login"
+	AuditEventRefresh           = "refresh"
+	AuditEventLogout            = "logout"
+	AuditEventSessionRevocation = "session_revocation"
+)
+
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+var AuditEvents = []string{
+	AuditEventSignup,
+	AuditEventLogin,
+	AuditEventRefresh,
+	AuditEventLogout,
+	AuditEventSessionRevocation,
+}
+
+var AuditOutcomes = []string{AuditOutcomeSuccess, AuditOutcomeFailure}
+
+// AuditEvent is an entry in the security audit log. UserId is empty when the
+// event could not be tied to an account, e.g. a login with an unknown email.
+type AuditEvent struct {
+	Id        string
+	UserId    string
+	Email     string
+	Event     string
+	Outcome   string
+	IP        string
+	UserAgent string
+	Details   string
+	CreatedAt time.Time
+}
+
+type AuditLogFilter struct {
+	UserId  string
+	Email   string
+	Event   string
+	Outcome string
+	IP      string
+	From    *time.Time
+	To      *time.Time
+}
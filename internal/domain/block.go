@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Block hides BlockerId and BlockedId from each other and stops every interaction between them.
+type Block struct {
+	Id        string
+	BlockerId string
+	BlockedId string
+	CreatedAt time.Time
+}
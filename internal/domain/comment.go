@@ -2,10 +2,40 @@ package domain
 
 import "time"
 
+// CommentMaxDepth is how deep replies nest. A reply to a comment at this depth
+// becomes its sibling instead, so threads never grow past it.
+const CommentMaxDepth = 5
+
+// Orders comments can be listed in. Top ranks by like count.
+const (
+	CommentSortNewest = "newest"
+	CommentSortOldest = "oldest"
+	CommentSortTop    = "top"
+)
+
 type Comment struct {
-	Id        string
-	PostId    string
-	UserId    string
+	Id       string
+	PostId   string
+	UserId   string
+	ParentId string
+	// RootId is the top-level comment a reply belongs to; empty for top-level comments.
+	RootId     string
+	Depth      int
+	ReplyCount int64
+	Value      string
+	Mentions   []Mention
+	Likes      []string
+	// Deleted marks a tombstone: a deleted comment kept in place because it
+	// still has replies. Its text and mentions are gone.
+	Deleted  bool
+	EditedAt *time.Time
+	// History holds the earlier versions of the text, oldest first.
+	History   []CommentRevision
+	CreatedAt time.Time
+}
+
+// CommentRevision is an earlier version of a comment's text and when it was written.
+type CommentRevision struct {
 	Value     string
 	CreatedAt time.Time
 }
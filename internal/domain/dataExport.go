@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	DataExportStatusPending = "pending"
+	DataExportStatusReady   = "ready"
+	DataExportStatusFailed  = "failed"
+)
+
+// DataExport is an archive of a user's personal data. It is built in the
+// background and deleted once ExpiresAt has passed.
+type DataExport struct {
+	Id          string
+	UserId      string
+	Status      string
+	FilePath    string
+	Size        int64
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   time.Time
+}
+
+func (d *DataExport) IsExpired() bool {
+	return time.Now().After(d.ExpiresAt)
+}
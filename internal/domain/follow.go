@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Follow is an edge of the follow graph: FollowerId follows FolloweeId.
+type Follow struct {
+	Id         string
+	FollowerId string
+	FolloweeId string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Follow status of one user towards another, as reported after toggling a follow.
+const (
+	FollowStatusNone      = "none"
+	FollowStatusRequested = "requested"
+	FollowStatusFollowing = "following"
+)
+
+// FollowRequest is a pending request to follow a private account.
+type FollowRequest struct {
+	Id          string
+	RequesterId string
+	TargetId    string
+	CreatedAt   time.Time
+}
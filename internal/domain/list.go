@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ListMaxMembers caps how many accounts a list can hold.
+const ListMaxMembers = 500
+
+// List is a named, curated set of accounts whose posts form the list timeline.
+// Private lists are only visible to their owner.
+type List struct {
+	Id              string
+	OwnerId         string
+	Name            string
+	Description     string
+	IsPrivate       bool
+	MemberIds       []string
+	SubscriberCount int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ListSubscription records that UserId follows the timeline of someone else's public list.
+type ListSubscription struct {
+	Id        string
+	ListId    string
+	UserId    string
+	CreatedAt time.Time
+}
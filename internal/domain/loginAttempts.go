@@ -0,0 +1,9 @@
+package domain
+
+import "time"
+
+// LoginAttempts tracks recent failed logins for an account or an IP.
+type LoginAttempts struct {
+	Failures     int
+	BlockedUntil time.Time
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// PostMaxMedia caps how many media items a post can carry.
+const PostMaxMedia = 4
+
+const (
+	// MediaStatusPending marks a resumable upload that is still receiving chunks.
+	MediaStatusPending = "pending"
+	MediaStatusReady   = "ready"
+)
+
+// Media is an uploaded image. Once ready it holds a full size rendition and a
+// thumbnail, both stored as JPEG. PostId is set when a post starts using it;
+// media nobody attached is deleted after a while.
+type Media struct {
+	Id           string
+	OwnerId      string
+	PostId       string
+	Status       string
+	Url          string
+	ThumbnailUrl string
+	Width        int
+	Height       int
+	// Size is the length of the upload in bytes, declared up front for
+	// resumable uploads. Received counts the bytes stored so far and Parts
+	// holds the storage key of every stored chunk, in upload order.
+	Size      int64
+	Received  int64
+	Parts     []string
+	CreatedAt time.Time
+}
+
+func (m *Media) IsReady() bool {
+	return m.Status == MediaStatusReady
+}
+
+// Attachment is the part of a media item embedded in the posts that use it,
+// so feeds can render media without looking it up.
+type Attachment struct {
+	MediaId      string
+	Url          string
+	ThumbnailUrl string
+	Width        int
+	Height       int
+}
@@ -0,0 +1,10 @@
+package domain
+
+// Mention is an @handle in a post or comment resolved to the user it refers to.
+// Start and End are character offsets of the mention, including the @, in the text.
+type Mention struct {
+	UserId string
+	Handle string
+	Start  int
+	End    int
+}
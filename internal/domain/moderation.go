@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+const (
+	ModerationActionDeletePost    = "delete_post"
+	ModerationActionDeleteComment = "delete_comment"
+	ModerationActionChangeRole    = "change_role"
+)
+
+// ModerationAction is an audit trail entry for a privileged action taken on someone else's content or account.
+type ModerationAction struct {
+	Id            string
+	ActorId       string
+	Action        string
+	TargetId      string
+	TargetOwnerId string
+	Reason        string
+	Details       string
+	CreatedAt     time.Time
+}
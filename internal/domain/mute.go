@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Mute silently drops MutedId's posts and notifications for MuterId. MutedId is not told.
+type Mute struct {
+	Id        string
+	MuterId   string
+	MutedId   string
+	CreatedAt time.Time
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// OAuthClient is a third-party application registered by a developer.
+// Its Id doubles as the OAuth client_id.
+type OAuthClient struct {
+	Id               string
+	OwnerId          string
+	Name             string
+	ClientSecretHash string
+	Public           bool
+	RedirectURIs     []string
+	Scopes           []string
+	CreatedAt        time.Time
+}
+
+// OAuthAuthorizationCode is issued after consent and redeemed once at the token endpoint.
+type OAuthAuthorizationCode struct {
+	ClientId      string   `json:"client_id"`
+	UserId        string   `json:"user_id"`
+	RedirectURI   string   `json:"redirect_uri"`
+	Scopes        []string `json:"scopes"`
+	CodeChallenge string   `json:"code_challenge"`
+}
+
+// OAuthGrant records the scopes a user has consented to for a client.
+type OAuthGrant struct {
+	Id        string
+	UserId    string
+	ClientId  string
+	Scopes    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type OAuthToken struct {
+	Id               string
+	ClientId         string
+	UserId           string
+	Scopes           []string
+	AccessTokenHash  string
+	RefreshTokenHash string
+	AccessExpiresAt  time.Time
+	RefreshExpiresAt time.Time
+	CreatedAt        time.Time
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// PageCursor points at the last item of a page of a list sorted by creation
+// time, newest first unless noted otherwise. The next page starts with the
+// item right after it.
+type PageCursor struct {
+	// Score is the item's rank in lists sorted by a count, such as top comments.
+	Score     int64
+	CreatedAt time.Time
+	Id        string
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+type PersonalAccessToken struct {
+	Id         string
+	UserId     string
+	Name       string
+	TokenHash  string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+func (p *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return p.ExpiresAt != nil && !now.Before(*p.ExpiresAt)
+}
+
+func (p *PersonalAccessToken) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
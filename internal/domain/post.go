@@ -2,15 +2,32 @@ package domain
 
 import "time"
 
+// Kinds of post. A repost shares another post as it is; a quote is a post of
+// its own that embeds the post it quotes. Both point at it through OriginalId.
+const (
+	PostKindPost   = "post"
+	PostKindRepost = "repost"
+	PostKindQuote  = "quote"
+)
+
 type Post struct {
-	Id           string
-	Creator      string
-	Title        string
-	Message      string
-	FirstName    string
-	LastName     string
-	SelectedFile string
-	Likes        []string
-	Comments     []string
-	CreatedAt    time.Time
+	Id          string
+	Creator     string
+	Kind        string
+	OriginalId  string
+	Title       string
+	Message     string
+	FirstName   string
+	LastName    string
+	Media       []Attachment
+	Likes       []string
+	Comments    []string
+	Mentions    []Mention
+	RepostCount int64
+	QuoteCount  int64
+	CreatedAt   time.Time
+}
+
+func (p *Post) IsRepost() bool {
+	return p.Kind == PostKindRepost
 }
@@ -0,0 +1,50 @@
+package domain
+
+import "slices"
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+const (
+	PermissionDeleteAnyPost     = "posts:delete_any"
+	PermissionDeleteAnyComment  = "comments:delete_any"
+	PermissionReadModerationLog = "moderation_log:read"
+	PermissionManageRoles       = "users:manage_roles"
+	PermissionManageUsers       = "users:manage"
+	PermissionReadAuditLog      = "audit_log:read"
+)
+
+var Roles = []string{RoleUser, RoleModerator, RoleAdmin}
+
+var rolePermissions = map[string][]string{
+	RoleUser: {},
+	RoleModerator: {
+		PermissionDeleteAnyPost,
+		PermissionDeleteAnyComment,
+		PermissionReadModerationLog,
+	},
+	RoleAdmin: {
+		PermissionDeleteAnyPost,
+		PermissionDeleteAnyComment,
+		PermissionReadModerationLog,
+		PermissionManageRoles,
+		PermissionManageUsers,
+		PermissionReadAuditLog,
+	},
+}
+
+// PermissionsFor returns the permissions granted to a role. Unknown or empty
+// roles, e.g. on users created before roles existed, get the user permissions.
+func PermissionsFor(role string) []string {
+	if permissions, ok := rolePermissions[role]; ok {
+		return permissions
+	}
+	return rolePermissions[RoleUser]
+}
+
+func RoleHasPermission(role, permission string) bool {
+	return slices.Contains(PermissionsFor(role), permission)
+}
@@ -0,0 +1,27 @@
+package domain
+
+const (
+	ScopePostsRead          = "posts:read"
+	ScopePostsWrite         = "posts:write"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeMessagesRead       = "messages:read"
+	ScopeMessagesWrite      = "messages:write"
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
+	ScopeListsRead          = "lists:read"
+	ScopeListsWrite         = "lists:write"
+)
+
+var Scopes = []string{
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeMessagesRead,
+	ScopeMessagesWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+	ScopeListsRead,
+	ScopeListsWrite,
+}
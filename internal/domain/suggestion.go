@@ -0,0 +1,10 @@
+package domain
+
+// Suggestion is a ranked who-to-follow candidate. MutualIds holds a few of the
+// viewer's followings who follow the candidate, most recent first.
+type Suggestion struct {
+	User        *User
+	Score       int64
+	MutualCount int64
+	MutualIds   []string
+}
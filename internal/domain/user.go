@@ -1,13 +1,30 @@
 package domain
 
+import "time"
+
 type User struct {
 	Id        string
 	FirstName string
 	LastName  string
 	Email     string
+	Handle    string
 	Password  string
 	ImageUrl  string
+	BannerUrl string
 	Bio       string
-	Followers []string
-	Following []string
+	Role      string
+	// IsPrivate accounts approve each follower, and only followers see their posts.
+	IsPrivate bool
+	// FollowersCount and FollowingCount mirror the follows collection and are
+	// only changed together with it.
+	FollowersCount int64
+	FollowingCount int64
+	// HandleChangedAt is when the handle was last changed, used to rate limit changes.
+	HandleChangedAt *time.Time
+	// DeactivatedAt is set while the account waits out its grace period before being purged.
+	DeactivatedAt *time.Time
+}
+
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// UserIdentity links a user to an account at an external identity provider.
+type UserIdentity struct {
+	Id        string
+	UserId    string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+// OIDCState is kept between redirecting to the identity provider and handling its callback.
+type OIDCState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+const (
+	VerificationPurposeMagicLink = "magic_link"
+)
+
+// VerificationToken backs single-use links sent by email. The plain token
+// only ever exists in the email; the store is keyed by its hash and purpose.
+type VerificationToken struct {
+	UserId    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Purpose   string    `json:"purpose"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+)
+
+type AuditEventResp struct {
+	Id        string    `json:"id"`
+	UserId    string    `json:"user_id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Event     string    `json:"event"`
+	Outcome   string    `json:"outcome"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ValidateAuditLogFilter(v *helper.Validator, filter *domain.AuditLogFilter) {
+	if filter.Event != "" {
+		v.Check(helper.PermittedValue(filter.Event, domain.AuditEvents...), "event", "unknown event")
+	}
+	if filter.Outcome != "" {
+		v.Check(helper.PermittedValue(filter.Outcome, domain.AuditOutcomes...), "outcome", "must be success or failure")
+	}
+	if filter.From != nil && filter.To != nil {
+		v.Check(filter.From.Before(*filter.To), "from", "must be before to")
+	}
+}
@@ -6,6 +6,7 @@ type RegisterReq struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
+	Handle    string `json:"handle"`
 	Password  string `json:"password"`
 }
 
@@ -41,6 +42,11 @@ func validateEmail(v *helper.Validator, email string) {
 	v.Check(helper.Matches(email, helper.EmailRX), "email", "must be a valid email address")
 }
 
+func validateHandle(v *helper.Validator, handle string) {
+	v.Check(handle != "", "handle", "required")
+	v.Check(helper.Matches(handle, helper.HandleRX), "handle", "must be 3 to 15 letters, digits or underscores")
+}
+
 func validatePassword(v *helper.Validator, password string) {
 	v.Check(password != "", "password", "required")
 	v.Check(len(password) >= 8, "password", "must be at least 8 characters")
@@ -55,6 +61,7 @@ func ValidateRegisterReq(v *helper.Validator, req *RegisterReq) {
 	validateFirstName(v, req.FirstName)
 	validateLastName(v, req.LastName)
 	validateEmail(v, req.Email)
+	validateHandle(v, req.Handle)
 	validatePassword(v, req.Password)
 }
 
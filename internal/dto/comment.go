@@ -1,9 +1,59 @@
 package dto
 
-import "github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+)
 
 type CommentReq struct {
 	Value string `json:"value"`
+	// ParentId makes the comment a reply to another comment on the same post.
+	ParentId string `json:"parent_id"`
+}
+
+type UpdateCommentReq struct {
+	Value string `json:"value"`
+}
+
+type GetCommentsQuery struct {
+	ParentId string
+	Sort     string
+	Cursor   string
+	Limit    int
+}
+
+type CommentAuthorResp struct {
+	Id        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Handle    string `json:"handle"`
+	ImageUrl  string `json:"image_url"`
+}
+
+// CommentResp is a comment within a thread. Deleted comments that still have
+// replies are tombstones: only their place in the thread is kept.
+type CommentResp struct {
+	Id         string             `json:"id"`
+	PostId     string             `json:"post_id"`
+	ParentId   string             `json:"parent_id,omitempty"`
+	UserId     string             `json:"user_id,omitempty"`
+	Author     *CommentAuthorResp `json:"author,omitempty"`
+	Value      string             `json:"value"`
+	Mentions   []MentionResp      `json:"mentions"`
+	Depth      int                `json:"depth"`
+	ReplyCount int64              `json:"reply_count"`
+	LikeCount  int64              `json:"like_count"`
+	LikedByMe  bool               `json:"liked_by_me"`
+	Deleted    bool               `json:"deleted"`
+	EditedAt   *time.Time         `json:"edited_at,omitempty"`
+	Replies    []*CommentResp     `json:"replies"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type CommentRevisionResp struct {
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func validateComment(v *helper.Validator, value string) {
@@ -15,3 +65,11 @@ func validateComment(v *helper.Validator, value string) {
 func ValidateCommentReq(v *helper.Validator, req *CommentReq) {
 	validateComment(v, req.Value)
 }
+
+func ValidateUpdateCommentReq(v *helper.Validator, req *UpdateCommentReq) {
+	validateComment(v, req.Value)
+}
+
+func ValidateGetCommentsQuery(v *helper.Validator, query *GetCommentsQuery) {
+	v.Check(helper.PermittedValue(query.Sort, domain.CommentSortNewest, domain.CommentSortOldest, domain.CommentSortTop), "sort", "must be newest, oldest or top")
+}
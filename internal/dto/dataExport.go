@@ -0,0 +1,78 @@
+package dto
+
+import "time"
+
+type DataExportResp struct {
+	Id           string     `json:"id"`
+	Status       string     `json:"status"`
+	Size         int64      `json:"size,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	DownloadURL  string     `json:"download_url,omitempty"`
+	LinkExpireAt *time.Time `json:"link_expires_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+}
+
+// The types below describe the JSON files inside an export archive.
+
+type ExportedComment struct {
+	Id        string     `json:"id"`
+	PostId    string     `json:"post_id"`
+	ParentId  string     `json:"parent_id,omitempty"`
+	Value     string     `json:"value"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ExportedLike struct {
+	PostId      string `json:"post_id"`
+	PostTitle   string `json:"post_title"`
+	PostCreator string `json:"post_creator"`
+}
+
+type ExportedUserRef struct {
+	Id        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type ExportedFollows struct {
+	Followers []ExportedUserRef `json:"followers"`
+	Following []ExportedUserRef `json:"following"`
+}
+
+type ExportedMessage struct {
+	Id          string `json:"id"`
+	Direction   string `json:"direction"`
+	Counterpart string `json:"counterpart"`
+	Content     string `json:"content"`
+}
+
+type ExportedNotification struct {
+	Id        string    `json:"id"`
+	Details   string    `json:"details"`
+	SenderId  string    `json:"sender_id"`
+	TargetId  string    `json:"target_id"`
+	IsRead    bool      `json:"is_read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportedRefreshSession struct {
+	Id        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ExportedIdentity struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type ExportedSessions struct {
+	RefreshSessions      []ExportedRefreshSession   `json:"refresh_sessions"`
+	PersonalAccessTokens []*PersonalAccessTokenResp `json:"personal_access_tokens"`
+	OAuthAuthorizations  []*OAuthAuthorizationResp  `json:"oauth_authorizations"`
+	LinkedIdentities     []ExportedIdentity         `json:"linked_identities"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+	"unicode/utf8"
+)
+
+type CreateListReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+}
+
+type UpdateListReq struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsPrivate   *bool   `json:"is_private"`
+}
+
+type ListResp struct {
+	Id              string    `json:"id"`
+	OwnerId         string    `json:"owner_id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	IsPrivate       bool      `json:"is_private"`
+	MemberCount     int       `json:"member_count"`
+	SubscriberCount int64     `json:"subscriber_count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func validateListName(v *helper.Validator, name string) {
+	v.Check(name != "", "name", "must be provided")
+	v.Check(utf8.RuneCountInString(name) <= 25, "name", "must not be more than 25 characters")
+}
+
+func validateListDescription(v *helper.Validator, description string) {
+	v.Check(utf8.RuneCountInString(description) <= 100, "description", "must not be more than 100 characters")
+}
+
+func ValidateCreateListReq(v *helper.Validator, req *CreateListReq) {
+	validateListName(v, req.Name)
+	validateListDescription(v, req.Description)
+}
+
+func ValidateUpdateListReq(v *helper.Validator, req *UpdateListReq) {
+	if req.Name != nil {
+		validateListName(v, *req.Name)
+	}
+	if req.Description != nil {
+		validateListDescription(v, *req.Description)
+	}
+}
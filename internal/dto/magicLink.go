@@ -0,0 +1,19 @@
+package dto
+
+import "github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+
+type MagicLinkReq struct {
+	Email string `json:"email"`
+}
+
+type RedeemMagicLinkReq struct {
+	Token string `json:"token"`
+}
+
+func ValidateMagicLinkReq(v *helper.Validator, req *MagicLinkReq) {
+	validateEmail(v, req.Email)
+}
+
+func ValidateRedeemMagicLinkReq(v *helper.Validator, req *RedeemMagicLinkReq) {
+	v.Check(req.Token != "", "token", "required")
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+)
+
+// CreateUploadReq starts a resumable upload of Size bytes.
+type CreateUploadReq struct {
+	Size int64 `json:"size"`
+}
+
+// MediaResp describes an uploaded media item. While Status is pending,
+// Received tells a resuming client where to continue from.
+type MediaResp struct {
+	Id           string    `json:"id"`
+	Status       string    `json:"status"`
+	Url          string    `json:"url,omitempty"`
+	ThumbnailUrl string    `json:"thumbnail_url,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	Size         int64     `json:"size"`
+	Received     int64     `json:"received"`
+	ChunkSize    int64     `json:"chunk_size,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AttachmentResp struct {
+	MediaId      string `json:"media_id"`
+	Url          string `json:"url"`
+	ThumbnailUrl string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+func ValidateCreateUploadReq(v *helper.Validator, req *CreateUploadReq) {
+	v.Check(req.Size > 0, "size", "must be greater than zero")
+}
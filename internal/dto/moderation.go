@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+)
+
+type ModerationReq struct {
+	Reason string `json:"reason"`
+}
+
+type UpdateRoleReq struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+type ModerationActionResp struct {
+	Id            string    `json:"id"`
+	ActorId       string    `json:"actor_id"`
+	Action        string    `json:"action"`
+	TargetId      string    `json:"target_id"`
+	TargetOwnerId string    `json:"target_owner_id"`
+	Reason        string    `json:"reason"`
+	Details       string    `json:"details"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func validateModerationReason(v *helper.Validator, reason string) {
+	v.Check(reason != "", "reason", "must be provided")
+	v.Check(len(reason) <= 500, "reason", "must not be more than 500 characters")
+}
+
+func ValidateModerationReq(v *helper.Validator, req *ModerationReq) {
+	validateModerationReason(v, req.Reason)
+}
+
+func ValidateUpdateRoleReq(v *helper.Validator, req *UpdateRoleReq) {
+	v.Check(helper.PermittedValue(req.Role, domain.Roles...), "role", "must be one of user, moderator or admin")
+	validateModerationReason(v, req.Reason)
+}
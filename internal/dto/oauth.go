@@ -0,0 +1,128 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"strings"
+	"time"
+)
+
+type CreateOAuthClientReq struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	Public       bool     `json:"public"`
+}
+
+type OAuthClientResp struct {
+	ClientId     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Public       bool      `json:"public"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreatedOAuthClientResp carries the client secret, which is only returned once at registration.
+type CreatedOAuthClientResp struct {
+	OAuthClientResp
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizeReq holds the authorization request parameters the third-party app
+// sent the user with, as defined by RFC 6749 and RFC 7636.
+type AuthorizeReq struct {
+	ResponseType        string `json:"response_type"`
+	ClientId            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+type AuthorizeDecisionReq struct {
+	AuthorizeReq
+	Approve bool `json:"approve"`
+}
+
+type ConsentResp struct {
+	Client          OAuthClientResp `json:"client"`
+	RequestedScopes []string        `json:"requested_scopes"`
+	GrantedScopes   []string        `json:"granted_scopes"`
+	RedirectURI     string          `json:"redirect_uri"`
+}
+
+type AuthorizeResp struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+type OAuthTokenReq struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	ClientId     string
+	ClientSecret string
+}
+
+type OAuthRevokeReq struct {
+	Token        string
+	ClientId     string
+	ClientSecret string
+}
+
+type OAuthTokenResp struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+type OAuthErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type OAuthAuthorizationResp struct {
+	ClientId   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scopes     []string  `json:"scopes"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func validateClientName(v *helper.Validator, name string) {
+	v.Check(name != "", "name", "required")
+	v.Check(len(name) <= 64, "name", "must not exceed 64 characters")
+}
+
+func validateRedirectURIs(v *helper.Validator, redirectURIs []string) {
+	v.Check(len(redirectURIs) > 0, "redirect_uris", "at least one redirect uri is required")
+	v.Check(helper.Unique(redirectURIs), "redirect_uris", "must not contain duplicates")
+	for _, uri := range redirectURIs {
+		v.Check(helper.IsURL(uri), "redirect_uris", "must be absolute urls")
+		v.Check(!strings.Contains(uri, "#"), "redirect_uris", "must not contain a fragment")
+	}
+}
+
+func ValidateCreateOAuthClientReq(v *helper.Validator, req *CreateOAuthClientReq) {
+	validateClientName(v, req.Name)
+	validateRedirectURIs(v, req.RedirectURIs)
+	validateScopes(v, req.Scopes)
+}
+
+func ValidateAuthorizeReq(v *helper.Validator, req *AuthorizeReq) {
+	v.Check(req.ResponseType == "code", "response_type", "must be code")
+	v.Check(req.ClientId != "", "client_id", "required")
+	v.Check(req.RedirectURI != "", "redirect_uri", "required")
+	v.Check(req.Scope != "", "scope", "required")
+	v.Check(req.CodeChallenge != "", "code_challenge", "required")
+	v.Check(req.CodeChallengeMethod == "S256", "code_challenge_method", "must be S256")
+}
+
+func ValidateOAuthRevokeReq(v *helper.Validator, req *OAuthRevokeReq) {
+	v.Check(req.Token != "", "token", "required")
+	v.Check(req.ClientId != "", "client_id", "required")
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+
+type OIDCLoginResp struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+type OIDCCallbackReq struct {
+	Code  string
+	State string
+}
+
+func ValidateOIDCCallbackReq(v *helper.Validator, req *OIDCCallbackReq) {
+	v.Check(req.Code != "", "code", "required")
+	v.Check(req.State != "", "state", "required")
+}
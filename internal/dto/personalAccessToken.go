@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+)
+
+type CreatePersonalAccessTokenReq struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type PersonalAccessTokenResp struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedPersonalAccessTokenResp carries the plain token, which is only
+// returned once at creation time.
+type CreatedPersonalAccessTokenResp struct {
+	PersonalAccessTokenResp
+	Token string `json:"token"`
+}
+
+func validateTokenName(v *helper.Validator, name string) {
+	v.Check(name != "", "name", "required")
+	v.Check(len(name) <= 64, "name", "must not exceed 64 characters")
+}
+
+func validateScopes(v *helper.Validator, scopes []string) {
+	v.Check(len(scopes) > 0, "scopes", "at least one scope is required")
+	v.Check(helper.Unique(scopes), "scopes", "must not contain duplicates")
+	for _, scope := range scopes {
+		v.Check(helper.PermittedValue(scope, domain.Scopes...), "scopes", "contains an unknown scope")
+	}
+}
+
+func validateExpiresAt(v *helper.Validator, expiresAt *time.Time) {
+	if expiresAt != nil {
+		v.Check(expiresAt.After(time.Now()), "expires_at", "must be in the future")
+	}
+}
+
+func ValidateCreatePersonalAccessTokenReq(v *helper.Validator, req *CreatePersonalAccessTokenReq) {
+	validateTokenName(v, req.Name)
+	validateScopes(v, req.Scopes)
+	validateExpiresAt(v, req.ExpiresAt)
+}
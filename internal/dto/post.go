@@ -1,33 +1,54 @@
 package dto
 
 import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
 	"time"
 )
 
 type CreatePostReq struct {
-	Title        string `json:"title"`
-	Message      string `json:"message"`
-	SelectedFile string `json:"selected_file"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	MediaIds []string `json:"media_ids"`
+	// QuoteId makes the post a quote of another post.
+	QuoteId string `json:"quote_id"`
 }
 
 type UpdatePostReq struct {
-	Title        *string `json:"title"`
-	Message      *string `json:"message"`
-	SelectedFile *string `json:"selected_file"`
+	Title    *string   `json:"title"`
+	Message  *string   `json:"message"`
+	MediaIds *[]string `json:"media_ids"`
 }
 
+type MentionResp struct {
+	UserId string `json:"user_id"`
+	Handle string `json:"handle"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+}
+
+// PostResp is a post as shown to a viewer. For a repost, the creator and
+// names are those of the reposter and Original holds the shared post; a quote
+// carries its own content and Original holds the quoted post, unless that is
+// gone or hidden from the viewer.
 type PostResp struct {
-	Id           string    `json:"id"`
-	Creator      string    `json:"creator"`
-	Title        string    `json:"title"`
-	Message      string    `json:"message"`
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	SelectedFile string    `json:"selected_file"`
-	Likes        []string  `json:"likes"`
-	Comments     []string  `json:"comments"`
-	CreatedAt    time.Time `json:"created_at"`
+	Id          string           `json:"id"`
+	Creator     string           `json:"creator"`
+	Kind        string           `json:"kind"`
+	OriginalId  string           `json:"original_id,omitempty"`
+	Original    *PostResp        `json:"original,omitempty"`
+	Title       string           `json:"title"`
+	Message     string           `json:"message"`
+	FirstName   string           `json:"first_name"`
+	LastName    string           `json:"last_name"`
+	Media       []AttachmentResp `json:"media"`
+	Likes       []string         `json:"likes"`
+	Comments    []string         `json:"comments"`
+	Mentions    []MentionResp    `json:"mentions"`
+	RepostCount int64            `json:"repost_count"`
+	QuoteCount  int64            `json:"quote_count"`
+	CreatedAt   time.Time        `json:"created_at"`
 }
 
 func validatePostTitle(v *helper.Validator, title string) {
@@ -52,12 +73,21 @@ func validateUpdatePostMessage(v *helper.Validator, message *string) {
 	}
 }
 
+func validatePostMediaIds(v *helper.Validator, ids []string) {
+	v.Check(len(ids) <= domain.PostMaxMedia, "media_ids", fmt.Sprintf("must not contain more than %d items", domain.PostMaxMedia))
+	v.Check(helper.Unique(ids), "media_ids", "must not contain duplicate values")
+}
+
 func ValidateCreatePostReq(v *helper.Validator, req *CreatePostReq) {
 	validatePostTitle(v, req.Title)
 	validatePostMessage(v, req.Message)
+	validatePostMediaIds(v, req.MediaIds)
 }
 
 func ValidateUpdatePostReq(v *helper.Validator, req *UpdatePostReq) {
 	validateUpdatePostTitle(v, req.Title)
 	validateUpdatePostMessage(v, req.Message)
+	if req.MediaIds != nil {
+		validatePostMediaIds(v, *req.MediaIds)
+	}
 }
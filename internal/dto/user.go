@@ -1,27 +1,98 @@
 package dto
 
-import "github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"time"
+)
 
 type UpdateUserReq struct {
 	FirstName *string `json:"first_name"`
 	LastName  *string `json:"last_name"`
-	ImageUrl  *string `json:"image_url"`
 	Bio       *string `json:"bio"`
+	IsPrivate *bool   `json:"is_private"`
+}
+
+type UpdateHandleReq struct {
+	Handle string `json:"handle"`
 }
 
 type UserResp struct {
-	Id        string   `json:"id"`
-	FirstName string   `json:"first_name"`
-	LastName  string   `json:"last_name"`
-	Email     string   `json:"email"`
-	ImageUrl  string   `json:"image_url"`
-	Bio       string   `json:"bio"`
-	Followers []string `json:"followers"`
-	Following []string `json:"following"`
+	Id             string `json:"id"`
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	Email          string `json:"email"`
+	Handle         string `json:"handle"`
+	ImageUrl       string `json:"image_url"`
+	BannerUrl      string `json:"banner_url"`
+	Bio            string `json:"bio"`
+	Role           string `json:"role"`
+	IsPrivate      bool   `json:"is_private"`
+	FollowersCount int64  `json:"followers_count"`
+	FollowingCount int64  `json:"following_count"`
+}
+
+// FollowEntryResp is one row of a followers or following list: a profile
+// summary plus whether the viewer follows that user.
+type FollowEntryResp struct {
+	Id             string    `json:"id"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	Handle         string    `json:"handle"`
+	ImageUrl       string    `json:"image_url"`
+	Bio            string    `json:"bio"`
+	FollowersCount int64     `json:"followers_count"`
+	FollowingCount int64     `json:"following_count"`
+	IsFollowedByMe bool      `json:"is_followed_by_me"`
+	FollowedAt     time.Time `json:"followed_at"`
 }
 
-func validateImageUrl(v *helper.Validator, image string) {
+// FollowStatusResp is returned after toggling a follow. Status is none,
+// requested or following.
+type FollowStatusResp struct {
+	TargetUser  *UserResp `json:"target_user"`
+	CurrentUser *UserResp `json:"current_user"`
+	Status      string    `json:"status"`
+}
+
+type FollowRequestResp struct {
+	Id          string    `json:"id"`
+	RequesterId string    `json:"requester_id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Handle      string    `json:"handle"`
+	ImageUrl    string    `json:"image_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
 
+// RelationshipResp describes how the viewer relates to another user.
+type RelationshipResp struct {
+	UserId                string `json:"user_id"`
+	Following             bool   `json:"following"`
+	FollowedBy            bool   `json:"followed_by"`
+	Blocking              bool   `json:"blocking"`
+	BlockedBy             bool   `json:"blocked_by"`
+	Muting                bool   `json:"muting"`
+	FollowRequestSent     bool   `json:"follow_request_sent"`
+	FollowRequestReceived bool   `json:"follow_request_received"`
+}
+
+// SuggestionResp is a who-to-follow candidate. Reason explains the
+// suggestion, e.g. "Followed by Jane Doe and John Roe".
+type SuggestionResp struct {
+	User        *UserResp `json:"user"`
+	MutualCount int64     `json:"mutual_count"`
+	FollowedBy  []string  `json:"followed_by"`
+	Reason      string    `json:"reason"`
+}
+
+// RestrictedUserResp is an entry of the caller's block or mute list.
+type RestrictedUserResp struct {
+	UserId    string    `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Handle    string    `json:"handle"`
+	ImageUrl  string    `json:"image_url"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func validateBio(v *helper.Validator, bio string) {
@@ -35,11 +106,11 @@ func ValidateUpdateUserReq(v *helper.Validator, req *UpdateUserReq) {
 	if req.LastName != nil {
 		validateLastName(v, *req.LastName)
 	}
-
-	if req.ImageUrl != nil {
-		validateImageUrl(v, *req.ImageUrl)
-	}
 	if req.Bio != nil {
 		validateBio(v, *req.Bio)
 	}
 }
+
+func ValidateUpdateHandleReq(v *helper.Validator, req *UpdateHandleReq) {
+	validateHandle(v, req.Handle)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type AuditLogHandler struct {
+	auditLogService service.AuditLogService
+}
+
+func (a *AuditLogHandler) GetMyEvents(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	page, limit := a.readPagination(r.URL.Query())
+
+	events, total, err := a.auditLogService.GetUserEvents(r.Context(), userId, page, limit)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to fetch security history", err)
+		return
+	}
+
+	helper.PaginatedSuccessResponse(w, "Security history retrieved successfully", events, a.paginatedMeta(page, limit, total))
+}
+
+func (a *AuditLogHandler) QueryEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	v := helper.NewValidator()
+	filter := &domain.AuditLogFilter{
+		UserId:  query.Get("user_id"),
+		Email:   query.Get("email"),
+		Event:   query.Get("event"),
+		Outcome: query.Get("outcome"),
+		IP:      query.Get("ip"),
+		From:    a.readTime(v, query, "from"),
+		To:      a.readTime(v, query, "to"),
+	}
+	dto.ValidateAuditLogFilter(v, filter)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given filter")
+		return
+	}
+
+	page, limit := a.readPagination(query)
+
+	events, total, err := a.auditLogService.QueryEvents(r.Context(), filter, page, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid user id", err)
+		default:
+			helper.InternalServerError(w, "Failed to query audit log", err)
+		}
+		return
+	}
+
+	helper.PaginatedSuccessResponse(w, "Audit log retrieved successfully", events, a.paginatedMeta(page, limit, total))
+}
+
+func (a *AuditLogHandler) readTime(v *helper.Validator, query url.Values, key string) *time.Time {
+	value := query.Get(key)
+	if value == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.AddError(key, "must be an RFC 3339 timestamp")
+		return nil
+	}
+
+	return &t
+}
+
+func (a *AuditLogHandler) readPagination(query url.Values) (int, int) {
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	return page, limit
+}
+
+func (a *AuditLogHandler) paginatedMeta(page, limit int, total int64) helper.PaginatedMeta {
+	return helper.PaginatedMeta{
+		Page:      int64(page),
+		Limit:     int64(limit),
+		Total:     total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(limit))),
+	}
+}
+
+func NewAuditLogHandler(auditLogService service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogService: auditLogService,
+	}
+}
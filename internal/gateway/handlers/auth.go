@@ -6,7 +6,10 @@ import (
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 type AuthHandler struct {
@@ -42,7 +45,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
 			helper.BadRequestResponse(w, "Registration failed", err)
-		case errors.Is(err, repository.ErrDuplicateEmail):
+		case errors.Is(err, repository.ErrDuplicateEmail), errors.Is(err, repository.ErrDuplicateHandle):
 			helper.EditConflictResponse(w, "Registration failed", err)
 		default:
 			helper.InternalServerError(w, "Failed to register user", err)
@@ -62,6 +65,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // @Param        request body dto.LoginReq true "User login credentials"
 // @Success      200 {object} helper.Response{data=dto.AuthResp} "Login successfully"
 // @Failure      401 {object} helper.Response "Invalid credentials"
+// @Failure      429 {object} helper.Response "Too many failed login attempts"
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var payload dto.LoginReq
@@ -79,7 +83,16 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	login, err := h.authService.Login(r.Context(), &payload)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to login", err)
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			helper.RateLimitExceededResponse(w, "Too many failed login attempts, try again later")
+		case errors.Is(err, repository.ErrInvalidCredentials):
+			helper.UnauthorizedResponse(w, "Invalid email or password")
+		default:
+			helper.InternalServerError(w, "Failed to login", err)
+		}
 		return
 	}
 
@@ -112,7 +125,12 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 
 	refreshToken, err := h.authService.RefreshToken(r.Context(), &payload)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to refresh token", err)
+		switch {
+		case errors.Is(err, utils.ErrInvalidToken), errors.Is(err, repository.ErrRecordNotFound):
+			helper.UnauthorizedResponse(w, "Invalid refresh token")
+		default:
+			helper.InternalServerError(w, "Failed to refresh token", err)
+		}
 		return
 	}
 
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/http"
+	"path/filepath"
+)
+
+type DataExportHandler struct {
+	dataExportService service.DataExportService
+}
+
+func (d *DataExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	export, err := d.dataExportService.RequestExport(r.Context(), userId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTooManyAttempts):
+			helper.RateLimitExceededResponse(w, "An export is already being prepared")
+		default:
+			helper.InternalServerError(w, "Failed to request data export", err)
+		}
+		return
+	}
+
+	helper.CreatedResponse(w, "Data export requested successfully", export)
+}
+
+func (d *DataExportHandler) GetExports(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	exports, err := d.dataExportService.GetExports(r.Context(), userId)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to fetch data exports", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Data exports retrieved successfully", exports)
+}
+
+func (d *DataExportHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	query := r.URL.Query()
+
+	file, export, err := d.dataExportService.OpenArchive(r.Context(), id, query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidToken):
+			helper.ForbiddenResponse(w, "Invalid or expired download link")
+		case errors.Is(err, repository.ErrRecordNotFound), errors.Is(err, repository.ErrInvalidId):
+			helper.NotFoundResponse(w, "Data export not found")
+		default:
+			helper.InternalServerError(w, "Failed to open data export", err)
+		}
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="x-gopher-export-`+export.Id+`.zip"`)
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, filepath.Base(export.FilePath), *export.CompletedAt, file)
+}
+
+func NewDataExportHandler(dataExportService service.DataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		dataExportService: dataExportService,
+	}
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type ListHandler struct {
+	listService service.ListService
+}
+
+func (l *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.CreateListReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateCreateListReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	list, err := l.listService.CreateList(r.Context(), userId, &payload)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to create list", err)
+		return
+	}
+
+	helper.CreatedResponse(w, "List successfully created", list)
+}
+
+func (l *ListHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	list, err := l.listService.GetList(r.Context(), viewerId, id)
+	if err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "List retrieved successfully", list)
+}
+
+func (l *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	var payload dto.UpdateListReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateUpdateListReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	list, err := l.listService.UpdateList(r.Context(), userId, id, &payload)
+	if err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "List successfully updated", list)
+}
+
+func (l *ListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	if err := l.listService.DeleteList(r.Context(), userId, id); err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "List successfully deleted", nil)
+}
+
+func (l *ListHandler) GetMyLists(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	lists, err := l.listService.GetListsByOwner(r.Context(), userId, userId)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to get lists", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Lists retrieved successfully", lists)
+}
+
+func (l *ListHandler) GetUserLists(w http.ResponseWriter, r *http.Request) {
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	ownerId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	lists, err := l.listService.GetListsByOwner(r.Context(), viewerId, ownerId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given user id", err)
+		default:
+			helper.InternalServerError(w, "Failed to get lists", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Lists retrieved successfully", lists)
+}
+
+func (l *ListHandler) GetSubscribedLists(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	lists, err := l.listService.GetSubscribedLists(r.Context(), userId)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to get lists", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Subscribed lists retrieved successfully", lists)
+}
+
+func (l *ListHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+
+	list, err := l.listService.AddMember(r.Context(), userId, params.ByName("id"), params.ByName("userId"))
+	if err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Member successfully added", list)
+}
+
+func (l *ListHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	params := httprouter.ParamsFromContext(r.Context())
+
+	list, err := l.listService.RemoveMember(r.Context(), userId, params.ByName("id"), params.ByName("userId"))
+	if err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Member successfully removed", list)
+}
+
+func (l *ListHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	members, err := l.listService.GetMembers(r.Context(), viewerId, id)
+	if err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "List members retrieved successfully", members)
+}
+
+func (l *ListHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	list, err := l.listService.Subscribe(r.Context(), userId, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You cannot subscribe to your own list")
+		default:
+			l.errorResponse(w, err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Subscribed to list successfully", list)
+}
+
+func (l *ListHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	if err := l.listService.Unsubscribe(r.Context(), userId, id); err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Unsubscribed from list successfully", nil)
+}
+
+func (l *ListHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	posts, total, err := l.listService.GetTimeline(r.Context(), viewerId, id, page, limit)
+	if err != nil {
+		l.errorResponse(w, err)
+		return
+	}
+
+	helper.PaginatedSuccessResponse(w, "List timeline retrieved successfully", posts, helper.PaginatedMeta{
+		Page:      int64(page),
+		Limit:     int64(limit),
+		Total:     total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(limit))),
+	})
+}
+
+// errorResponse maps the list service errors shared by most list endpoints.
+func (l *ListHandler) errorResponse(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound), errors.Is(err, repository.ErrInvalidId):
+		helper.NotFoundResponse(w, "List or user not found")
+	case errors.Is(err, repository.ErrForbidden):
+		helper.ForbiddenResponse(w, "You can only manage your own lists")
+	case errors.Is(err, repository.ErrBlocked):
+		helper.ForbiddenResponse(w, "You cannot interact with this user")
+	case errors.Is(err, repository.ErrListFull):
+		helper.BadRequestResponse(w, "List has reached the maximum number of members", err)
+	default:
+		helper.InternalServerError(w, "Internal server error", err)
+	}
+}
+
+func NewListHandler(listService service.ListService) *ListHandler {
+	return &ListHandler{
+		listService: listService,
+	}
+}
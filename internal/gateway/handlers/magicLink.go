@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService service.MagicLinkService
+}
+
+// RequestLink docs
+// @Summary Request a magic login link
+// @Description Email a single-use login link. The response is the same whether or not the email is registered
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.MagicLinkReq true "Email to send the link to"
+// @Success 200 {object} helper.Response "Link sent if the account exists"
+// @Failure 429 {object} helper.Response "Too many link requests"
+// @Router /auth/magic-link [post]
+func (m *MagicLinkHandler) RequestLink(w http.ResponseWriter, r *http.Request) {
+	var payload dto.MagicLinkReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateMagicLinkReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	if err := m.magicLinkService.RequestLink(r.Context(), &payload); err != nil {
+		var throttled *service.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			helper.RateLimitExceededResponse(w, "Too many login link requests, try again later")
+		default:
+			helper.InternalServerError(w, "Failed to send login link", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "If an account exists for this email, a login link has been sent", nil)
+}
+
+// RedeemLink docs
+// @Summary Log in with a magic link
+// @Description Exchange the token from a magic link for access and refresh tokens
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.RedeemMagicLinkReq true "Token from the link"
+// @Success 200 {object} helper.Response{data=dto.AuthResp} "Login successfully"
+// @Failure 401 {object} helper.Response "Invalid or expired link"
+// @Router /auth/magic-link/redeem [post]
+func (m *MagicLinkHandler) RedeemLink(w http.ResponseWriter, r *http.Request) {
+	var payload dto.RedeemMagicLinkReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateRedeemMagicLinkReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	login, err := m.magicLinkService.RedeemLink(r.Context(), &payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidToken):
+			helper.UnauthorizedResponse(w, "Invalid or expired login link")
+		default:
+			helper.InternalServerError(w, "Failed to login", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "User successfully logged in", login)
+}
+
+func NewMagicLinkHandler(magicLinkService service.MagicLinkService) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: magicLinkService,
+	}
+}
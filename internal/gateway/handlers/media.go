@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type MediaHandler struct {
+	mediaService service.MediaService
+}
+
+// UploadMedia stores the image sent in the "file" field of a multipart request.
+func (m *MediaHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	data, err := helper.ReadFormFile(w, r, "file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			helper.PayloadTooLargeResponse(w, "File is too large")
+			return
+		}
+		helper.BadRequestResponse(w, "Invalid given file", err)
+		return
+	}
+
+	media, err := m.mediaService.Upload(r.Context(), userId, data)
+	if err != nil {
+		m.errorResponse(w, err)
+		return
+	}
+
+	helper.CreatedResponse(w, "Media successfully uploaded", media)
+}
+
+// CreateUpload starts a resumable upload.
+func (m *MediaHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.CreateUploadReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateCreateUploadReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	media, err := m.mediaService.CreateUpload(r.Context(), userId, &payload)
+	if err != nil {
+		m.errorResponse(w, err)
+		return
+	}
+
+	helper.CreatedResponse(w, "Upload successfully created", media)
+}
+
+// UploadChunk takes the next chunk of a resumable upload as the raw request
+// body. The Upload-Offset header tells where in the file the chunk starts.
+func (m *MediaHandler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		helper.BadRequestResponse(w, "Invalid Upload-Offset header", errors.New("invalid upload offset"))
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, helper.MaxUploadSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			helper.PayloadTooLargeResponse(w, "Chunk is too large")
+			return
+		}
+		helper.BadRequestResponse(w, "Invalid given chunk", err)
+		return
+	}
+
+	media, err := m.mediaService.UploadChunk(r.Context(), userId, id, offset, data)
+	if err != nil {
+		m.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Chunk successfully uploaded", media)
+}
+
+func (m *MediaHandler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	media, err := m.mediaService.GetMedia(r.Context(), userId, id)
+	if err != nil {
+		m.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Media retrieved successfully", media)
+}
+
+func (m *MediaHandler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+
+	if err := m.mediaService.DeleteMedia(r.Context(), userId, id); err != nil {
+		m.errorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Media successfully deleted", nil)
+}
+
+func (m *MediaHandler) errorResponse(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound), errors.Is(err, repository.ErrInvalidId):
+		helper.NotFoundResponse(w, "Media not found")
+	case errors.Is(err, repository.ErrForbidden):
+		helper.ForbiddenResponse(w, "You can only manage your own media")
+	case errors.Is(err, repository.ErrImageTooLarge):
+		helper.PayloadTooLargeResponse(w, "File is too large")
+	case errors.Is(err, repository.ErrInvalidImage):
+		helper.BadRequestResponse(w, "Invalid given image", err)
+	case errors.Is(err, repository.ErrInvalidMedia):
+		helper.BadRequestResponse(w, "Media is in use or the chunk doesn't fit the upload", err)
+	case errors.Is(err, repository.ErrUploadOffset):
+		helper.EditConflictResponse(w, "Upload-Offset doesn't match the bytes received so far", err)
+	default:
+		helper.InternalServerError(w, "Internal server error", err)
+	}
+}
+
+func NewMediaHandler(mediaService service.MediaService) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+	}
+}
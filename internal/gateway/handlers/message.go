@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
 	"net/http"
 	"strconv"
 )
@@ -13,12 +16,21 @@ type MessageHandler struct {
 }
 
 func (m *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
 	var payload dto.MessageReq
 	if err := helper.ReadJSON(w, r, &payload); err != nil {
 		helper.BadRequestResponse(w, "Invalid given payload", err)
 		return
 	}
 
+	// Messages are always sent as the signed in user, whatever the body says.
+	payload.Sender = userId
+
 	v := helper.NewValidator()
 	dto.ValidateMessageReq(v, &payload)
 	if !v.Valid() {
@@ -28,7 +40,14 @@ func (m *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 
 	message, err := m.messageService.SendMessage(r.Context(), &payload)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to create message", err)
+		switch {
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot message this user")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given user id", err)
+		default:
+			helper.InternalServerError(w, "Failed to create message", err)
+		}
 		return
 	}
 
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type stubMessageService struct {
+	service.MessageService
+
+	// blocks maps a receiver to the senders they blocked.
+	blocks map[string][]string
+	sent   []*dto.MessageReq
+}
+
+func (s *stubMessageService) SendMessage(ctx context.Context, input *dto.MessageReq) (*dto.MessageResp, error) {
+	if slices.Contains(s.blocks[input.Receiver], input.Sender) {
+		return nil, repository.ErrBlocked
+	}
+	s.sent = append(s.sent, input)
+	return &dto.MessageResp{Id: "m1", Content: input.Content, Sender: input.Sender, Receiver: input.Receiver}, nil
+}
+
+func TestMessageHandlerSendMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		actorId    string
+		body       string
+		wantStatus int
+	}{
+		{name: "send", actorId: "1", body: `{"content":"hi","receiver":"2"}`, wantStatus: http.StatusCreated},
+		{name: "blocked sender", actorId: "3", body: `{"content":"hi","sender":"3","receiver":"2"}`, wantStatus: http.StatusForbidden},
+		{name: "blocked sender posing as another", actorId: "3", body: `{"content":"hi","sender":"1","receiver":"2"}`, wantStatus: http.StatusForbidden},
+		{name: "sender in the body is ignored", actorId: "1", body: `{"content":"hi","sender":"4","receiver":"2"}`, wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageService := &stubMessageService{blocks: map[string][]string{"2": {"3"}}}
+			handler := NewMessageHandler(messageService)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/message/send", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			r = r.WithContext(utils.WithUserId(r.Context(), tt.actorId))
+			w := httptest.NewRecorder()
+
+			handler.SendMessage(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			for _, sent := range messageService.sent {
+				if sent.Sender != tt.actorId {
+					t.Errorf("expected the message to be sent as %s, got %s", tt.actorId, sent.Sender)
+				}
+			}
+		})
+	}
+}
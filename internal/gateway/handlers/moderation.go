@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type ModerationHandler struct {
+	moderationService service.ModerationService
+}
+
+func (m *ModerationHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	actorId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	var payload dto.ModerationReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateModerationReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	if err := m.moderationService.DeletePost(r.Context(), actorId, postId, payload.Reason); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid post id", err)
+		default:
+			helper.InternalServerError(w, "Failed to delete post", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Post deleted successfully", nil)
+}
+
+func (m *ModerationHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	actorId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	commentId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if commentId == "" {
+		helper.BadRequestResponse(w, "Invalid comment id", errors.New("invalid comment id"))
+		return
+	}
+
+	var payload dto.ModerationReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateModerationReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	if err := m.moderationService.DeleteComment(r.Context(), actorId, commentId, payload.Reason); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Comment not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid comment id", err)
+		default:
+			helper.InternalServerError(w, "Failed to delete comment", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Comment deleted successfully", nil)
+}
+
+func (m *ModerationHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	actorId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	userId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if userId == "" {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.UpdateRoleReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateUpdateRoleReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	if err := m.moderationService.UpdateRole(r.Context(), actorId, userId, &payload); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You cannot change your own role")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid user id", err)
+		default:
+			helper.InternalServerError(w, "Failed to update role", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Role updated successfully", nil)
+}
+
+func (m *ModerationHandler) GetActions(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	actions, total, err := m.moderationService.GetActions(r.Context(), page, limit)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to fetch moderation log", err)
+		return
+	}
+
+	totalPages := int64(math.Ceil(float64(total) / float64(limit)))
+
+	meta := helper.PaginatedMeta{
+		Page:      int64(page),
+		Limit:     int64(limit),
+		Total:     total,
+		TotalPage: totalPages,
+	}
+
+	helper.PaginatedSuccessResponse(w, "Moderation log retrieved successfully", actions, meta)
+}
+
+func NewModerationHandler(moderationService service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/http"
+)
+
+type OAuthHandler struct {
+	oauthService service.OAuthService
+}
+
+func (o *OAuthHandler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.CreateOAuthClientReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateCreateOAuthClientReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	client, err := o.oauthService.RegisterClient(r.Context(), userId, &payload)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to register app", err)
+		return
+	}
+
+	helper.CreatedResponse(w, "App registered, the client secret will not be shown again", client)
+}
+
+func (o *OAuthHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	clients, err := o.oauthService.GetClients(r.Context(), userId)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to get apps", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Apps retrieved successfully", clients)
+}
+
+func (o *OAuthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	clientId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if clientId == "" {
+		helper.BadRequestResponse(w, "Invalid client id", errors.New("invalid client id"))
+		return
+	}
+
+	if err := o.oauthService.DeleteClient(r.Context(), userId, clientId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "App not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid client id", err)
+		default:
+			helper.InternalServerError(w, "Failed to delete app", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "App deleted successfully", nil)
+}
+
+// GetConsent returns what the consent screen shows the user before they approve or deny an app.
+func (o *OAuthHandler) GetConsent(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	query := r.URL.Query()
+	payload := dto.AuthorizeReq{
+		ResponseType:        query.Get("response_type"),
+		ClientId:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateAuthorizeReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid authorization request")
+		return
+	}
+
+	consent, err := o.oauthService.GetConsent(r.Context(), userId, &payload)
+	if err != nil {
+		o.authorizeErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Consent details retrieved successfully", consent)
+}
+
+func (o *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.AuthorizeDecisionReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateAuthorizeReq(v, &payload.AuthorizeReq)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid authorization request")
+		return
+	}
+
+	resp, err := o.oauthService.Authorize(r.Context(), userId, &payload)
+	if err != nil {
+		o.authorizeErrorResponse(w, err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Authorization decision recorded", resp)
+}
+
+func (o *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		o.oauthErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	clientId, clientSecret := o.clientCredentials(r)
+	payload := dto.OAuthTokenReq{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+	}
+
+	token, err := o.oauthService.Token(r.Context(), &payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidClient):
+			o.oauthErrorResponse(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		case errors.Is(err, repository.ErrInvalidGrant):
+			o.oauthErrorResponse(w, http.StatusBadRequest, "invalid_grant", "the grant is invalid, expired or was already used")
+		case errors.Is(err, repository.ErrUnsupportedGrantType):
+			o.oauthErrorResponse(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		default:
+			o.oauthErrorResponse(w, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	helper.RawJSONResponse(w, http.StatusOK, token)
+}
+
+func (o *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		o.oauthErrorResponse(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	clientId, clientSecret := o.clientCredentials(r)
+	payload := dto.OAuthRevokeReq{
+		Token:        r.PostForm.Get("token"),
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateOAuthRevokeReq(v, &payload)
+	if !v.Valid() {
+		o.oauthErrorResponse(w, http.StatusBadRequest, "invalid_request", "token and client_id are required")
+		return
+	}
+
+	if err := o.oauthService.Revoke(r.Context(), &payload); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidClient):
+			o.oauthErrorResponse(w, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+		default:
+			o.oauthErrorResponse(w, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (o *OAuthHandler) GetAuthorizations(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	authorizations, err := o.oauthService.GetAuthorizations(r.Context(), userId)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to get authorized apps", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Authorized apps retrieved successfully", authorizations)
+}
+
+func (o *OAuthHandler) RevokeAuthorization(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	clientId := httprouter.ParamsFromContext(r.Context()).ByName("clientId")
+	if clientId == "" {
+		helper.BadRequestResponse(w, "Invalid client id", errors.New("invalid client id"))
+		return
+	}
+
+	if err := o.oauthService.RevokeAuthorization(r.Context(), userId, clientId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Authorized app not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid client id", err)
+		default:
+			helper.InternalServerError(w, "Failed to revoke app access", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "App access revoked successfully", nil)
+}
+
+// clientCredentials reads client credentials from HTTP Basic auth, falling back to the form body.
+func (o *OAuthHandler) clientCredentials(r *http.Request) (string, string) {
+	if clientId, clientSecret, ok := r.BasicAuth(); ok {
+		return clientId, clientSecret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
+
+func (o *OAuthHandler) authorizeErrorResponse(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrInvalidClient):
+		helper.BadRequestResponse(w, "Unknown client", err)
+	case errors.Is(err, repository.ErrInvalidRedirectURI):
+		helper.BadRequestResponse(w, "Redirect uri is not registered for this client", err)
+	case errors.Is(err, repository.ErrInvalidScope):
+		helper.BadRequestResponse(w, "Requested scope is not allowed for this client", err)
+	default:
+		helper.InternalServerError(w, "Failed to process authorization request", err)
+	}
+}
+
+func (o *OAuthHandler) oauthErrorResponse(w http.ResponseWriter, statusCode int, code, description string) {
+	w.Header().Set("Cache-Control", "no-store")
+	helper.RawJSONResponse(w, statusCode, dto.OAuthErrorResp{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
+
+func NewOAuthHandler(oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+	}
+}
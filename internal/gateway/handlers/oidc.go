@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/http"
+)
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+// Login docs
+// @Summary Start social login
+// @Description Get the identity provider authorization URL for the authorization code flow with PKCE
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} helper.Response{data=dto.OIDCLoginResp} "Authorization URL generated"
+// @Router /auth/oidc/login [get]
+func (o *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	resp, err := o.oidcService.BeginLogin(r.Context())
+	if err != nil {
+		helper.InternalServerError(w, "Failed to start social login", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Authorization URL generated", resp)
+}
+
+// Callback docs
+// @Summary Complete social login
+// @Description Exchange the authorization code returned by the identity provider for tokens
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the login endpoint"
+// @Success 200 {object} helper.Response{data=dto.AuthResp} "User successfully logged in"
+// @Failure 400 {object} helper.Response "Invalid or expired state"
+// @Failure 401 {object} helper.Response "Invalid ID token"
+// @Failure 403 {object} helper.Response "Email not verified by the provider"
+// @Router /auth/oidc/callback [get]
+func (o *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		helper.BadRequestResponse(w, "Social login was cancelled or denied", errors.New(providerErr))
+		return
+	}
+
+	payload := dto.OIDCCallbackReq{
+		Code:  query.Get("code"),
+		State: query.Get("state"),
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateOIDCCallbackReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid query params")
+		return
+	}
+
+	resp, err := o.oidcService.CompleteLogin(r.Context(), &payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.BadRequestResponse(w, "Invalid or expired state", err)
+		case errors.Is(err, utils.ErrInvalidToken):
+			helper.UnauthorizedResponse(w, "Invalid ID token")
+		case errors.Is(err, repository.ErrEmailNotVerified):
+			helper.ForbiddenResponse(w, "Email is not verified by the identity provider")
+		default:
+			helper.InternalServerError(w, "Failed to complete social login", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "User successfully logged in", resp)
+}
+
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/http"
+)
+
+type PersonalAccessTokenHandler struct {
+	personalAccessTokenService service.PersonalAccessTokenService
+}
+
+func (p *PersonalAccessTokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.CreatePersonalAccessTokenReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateCreatePersonalAccessTokenReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	token, err := p.personalAccessTokenService.CreateToken(r.Context(), userId, &payload)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to create personal access token", err)
+		return
+	}
+
+	helper.CreatedResponse(w, "Personal access token created, it will not be shown again", token)
+}
+
+func (p *PersonalAccessTokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	tokens, err := p.personalAccessTokenService.GetTokens(r.Context(), userId)
+	if err != nil {
+		helper.InternalServerError(w, "Failed to get personal access tokens", err)
+		return
+	}
+
+	helper.SuccessResponse(w, "Personal access tokens retrieved successfully", tokens)
+}
+
+func (p *PersonalAccessTokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid token id", errors.New("invalid token id"))
+		return
+	}
+
+	if err := p.personalAccessTokenService.RevokeToken(r.Context(), userId, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Personal access token not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid token id", err)
+		default:
+			helper.InternalServerError(w, "Failed to revoke personal access token", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Personal access token revoked successfully", nil)
+}
+
+func NewPersonalAccessTokenHandler(personalAccessTokenService service.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		personalAccessTokenService: personalAccessTokenService,
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
@@ -39,7 +40,18 @@ func (p *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	post, err := p.postService.CreatePost(r.Context(), userId, &payload)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to create post", err)
+		switch {
+		case errors.Is(err, repository.ErrInvalidMedia):
+			helper.BadRequestResponse(w, "Media must be your own finished uploads not used by another post", err)
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Quoted post not found")
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot interact with this user")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "Posts of private accounts cannot be quoted")
+		default:
+			helper.InternalServerError(w, "Failed to create post", err)
+		}
 		return
 	}
 
@@ -53,7 +65,8 @@ func (p *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := p.postService.GetPostById(r.Context(), id)
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	post, err := p.postService.GetPostById(r.Context(), viewerId, id)
 	if err != nil {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
@@ -68,7 +81,11 @@ func (p *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 }
 
 func (p *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
+	viewerId, _ := utils.UserIdFromContext(r.Context())
 	userId := r.URL.Query().Get("id")
+	if userId == "" {
+		userId = viewerId
+	}
 	if userId == "" {
 		helper.BadRequestResponse(w, "user id is required (?id=...)", nil)
 		return
@@ -84,7 +101,7 @@ func (p *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 		limit = 10
 	}
 
-	posts, total, err := p.postService.GetAllPosts(r.Context(), userId, page, limit)
+	posts, total, err := p.postService.GetAllPosts(r.Context(), viewerId, userId, page, limit)
 	if err != nil {
 		helper.InternalServerError(w, "Failed to fetch feed", err)
 		return
@@ -104,7 +121,8 @@ func (p *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 
 func (p *PostHandler) GetPostsUsersBySearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("searchQuery")
-	result, err := p.postService.GetPostsUsersBySearch(r.Context(), query)
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	result, err := p.postService.GetPostsUsersBySearch(r.Context(), viewerId, query)
 	if err != nil {
 		helper.InternalServerError(w, "Search failed", err)
 		return
@@ -140,7 +158,16 @@ func (p *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 
 	post, err := p.postService.UpdatePost(r.Context(), postId, userId, &payload)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to update post", err)
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only update your own posts, and reposts can't be updated")
+		case errors.Is(err, repository.ErrInvalidMedia):
+			helper.BadRequestResponse(w, "Media must be your own finished uploads not used by another post", err)
+		default:
+			helper.InternalServerError(w, "Failed to update post", err)
+		}
 		return
 	}
 
@@ -175,13 +202,107 @@ func (p *PostHandler) CommentPost(w http.ResponseWriter, r *http.Request) {
 
 	post, err := p.postService.CommentPost(r.Context(), postId, userId, &payload)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to comment post", err)
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, repository.ErrInvalidParent):
+			helper.BadRequestResponse(w, "Invalid parent comment", err)
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot interact with this user")
+		default:
+			helper.InternalServerError(w, "Failed to comment post", err)
+		}
 		return
 	}
 
 	helper.SuccessResponse(w, "Comment successfully added", post)
 }
 
+func (p *PostHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	thread, nextCursor, err := p.postService.GetThread(r.Context(), viewerId, postId, query.Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, utils.ErrInvalidCursor), errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given cursor", err)
+		default:
+			helper.InternalServerError(w, "Failed to fetch thread", err)
+		}
+		return
+	}
+
+	helper.CursorPaginatedSuccessResponse(w, "Thread fetched successfully", thread, helper.CursorMeta{
+		Limit:      int64(limit),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	})
+}
+
+func (p *PostHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	req := dto.GetCommentsQuery{
+		ParentId: query.Get("parent_id"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+		Limit:    limit,
+	}
+	if req.Sort == "" {
+		req.Sort = domain.CommentSortNewest
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateGetCommentsQuery(v, &req)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid query params")
+		return
+	}
+
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	comments, nextCursor, err := p.postService.GetComments(r.Context(), viewerId, postId, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, utils.ErrInvalidCursor), errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given cursor or parent id", err)
+		default:
+			helper.InternalServerError(w, "Failed to fetch comments", err)
+		}
+		return
+	}
+
+	helper.CursorPaginatedSuccessResponse(w, "Comments fetched successfully", comments, helper.CursorMeta{
+		Limit:      int64(limit),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	})
+}
+
 func (p *PostHandler) LikePost(w http.ResponseWriter, r *http.Request) {
 	userId, exists := utils.UserIdFromContext(r.Context())
 	if !exists {
@@ -197,13 +318,79 @@ func (p *PostHandler) LikePost(w http.ResponseWriter, r *http.Request) {
 
 	post, err := p.postService.LikePost(r.Context(), postId, userId)
 	if err != nil {
-		helper.InternalServerError(w, "Failed to like post", err)
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot interact with this user")
+		default:
+			helper.InternalServerError(w, "Failed to like post", err)
+		}
 		return
 	}
 
 	helper.CreatedResponse(w, "Like toggled successfully", post)
 }
 
+func (p *PostHandler) Repost(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	post, err := p.postService.Repost(r.Context(), postId, userId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, repository.ErrAlreadyReposted):
+			helper.EditConflictResponse(w, "You already reposted this post", err)
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot interact with this user")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "Posts of private accounts cannot be reposted")
+		default:
+			helper.InternalServerError(w, "Failed to repost", err)
+		}
+		return
+	}
+
+	helper.CreatedResponse(w, "Post successfully reposted", post)
+}
+
+func (p *PostHandler) UndoRepost(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id", errors.New("invalid user id"))
+		return
+	}
+
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	if err := p.postService.UndoRepost(r.Context(), postId, userId); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Repost not found")
+		default:
+			helper.InternalServerError(w, "Failed to undo repost", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Repost successfully undone", nil)
+}
+
 func (p *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	userId, exists := utils.UserIdFromContext(r.Context())
 	if !exists {
@@ -218,7 +405,14 @@ func (p *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := p.postService.DeletePost(r.Context(), postId, userId); err != nil {
-		helper.InternalServerError(w, "Failed to delete post", err)
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Post not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only delete your own posts")
+		default:
+			helper.InternalServerError(w, "Failed to delete post", err)
+		}
 		return
 	}
 
@@ -248,7 +442,7 @@ func (p *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
 			helper.NotFoundResponse(w, "Comment or post not found")
-		case errors.Is(err, repository.ErrUnauthorized):
+		case errors.Is(err, repository.ErrForbidden):
 			helper.ForbiddenResponse(w, "You are not authorized to delete this comment!")
 		case errors.Is(err, repository.ErrInvalidId):
 			helper.BadRequestResponse(w, "Invalid comment or post id", err)
@@ -261,6 +455,125 @@ func (p *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	helper.SuccessResponse(w, "Comment deleted successfully", nil)
 }
 
+func (p *PostHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", nil)
+		return
+	}
+
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("postId")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	commentId := httprouter.ParamsFromContext(r.Context()).ByName("commentId")
+	if commentId == "" {
+		helper.BadRequestResponse(w, "Invalid comment id", errors.New("invalid comment id"))
+		return
+	}
+
+	var payload dto.UpdateCommentReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateUpdateCommentReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid payload")
+		return
+	}
+
+	comment, err := p.postService.UpdateComment(r.Context(), postId, commentId, userId, &payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Comment or post not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only edit your own comments")
+		case errors.Is(err, repository.ErrEditWindowClosed):
+			helper.ForbiddenResponse(w, "This comment can no longer be edited")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid comment or post id", err)
+		default:
+			helper.InternalServerError(w, "Failed to update comment", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Comment updated successfully", comment)
+}
+
+func (p *PostHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", nil)
+		return
+	}
+
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("postId")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	commentId := httprouter.ParamsFromContext(r.Context()).ByName("commentId")
+	if commentId == "" {
+		helper.BadRequestResponse(w, "Invalid comment id", errors.New("invalid comment id"))
+		return
+	}
+
+	comment, err := p.postService.LikeComment(r.Context(), postId, commentId, userId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Comment or post not found")
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot interact with this user")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid comment or post id", err)
+		default:
+			helper.InternalServerError(w, "Failed to like comment", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Like toggled successfully", comment)
+}
+
+func (p *PostHandler) GetCommentHistory(w http.ResponseWriter, r *http.Request) {
+	postId := httprouter.ParamsFromContext(r.Context()).ByName("postId")
+	if postId == "" {
+		helper.BadRequestResponse(w, "Invalid post id", errors.New("invalid post id"))
+		return
+	}
+
+	commentId := httprouter.ParamsFromContext(r.Context()).ByName("commentId")
+	if commentId == "" {
+		helper.BadRequestResponse(w, "Invalid comment id", errors.New("invalid comment id"))
+		return
+	}
+
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	history, err := p.postService.GetCommentHistory(r.Context(), viewerId, postId, commentId)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "Comment or post not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid comment or post id", err)
+		default:
+			helper.InternalServerError(w, "Failed to fetch comment history", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Comment history fetched successfully", history)
+}
+
 func NewPostHandler(postService service.PostService) *PostHandler {
 	return &PostHandler{
 		postService: postService,
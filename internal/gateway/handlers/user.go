@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"github.com/julienschmidt/httprouter"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
@@ -8,12 +9,15 @@ import (
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
 	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 type UserHandler struct {
-	userService service.UserService
-	postService service.PostService
+	userService    service.UserService
+	postService    service.PostService
+	accountService service.AccountService
 }
 
 func (u *UserHandler) GetUserById(w http.ResponseWriter, r *http.Request) {
@@ -34,7 +38,38 @@ func (u *UserHandler) GetUserById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	posts, err := u.postService.GetPostsByCreator(r.Context(), id)
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	posts, err := u.postService.GetPostsByCreator(r.Context(), viewerId, id)
+	if err != nil {
+		posts = []*dto.PostResp{}
+	}
+
+	helper.SuccessResponse(w, "user successfully retrieved", map[string]any{
+		"user":  user,
+		"posts": posts,
+	})
+}
+
+func (u *UserHandler) GetUserByHandle(w http.ResponseWriter, r *http.Request) {
+	handle := httprouter.ParamsFromContext(r.Context()).ByName("handle")
+	if !helper.Matches(handle, helper.HandleRX) {
+		helper.NotFoundResponse(w, "User not found")
+		return
+	}
+
+	user, err := u.userService.GetUserByHandle(r.Context(), handle)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	viewerId, _ := utils.UserIdFromContext(r.Context())
+	posts, err := u.postService.GetPostsByCreator(r.Context(), viewerId, user.Id)
 	if err != nil {
 		posts = []*dto.PostResp{}
 	}
@@ -53,8 +88,8 @@ func (u *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
-	if id == "" || id != userId {
-		helper.UnauthorizedResponse(w, "You can only update your own profile")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
 		return
 	}
 
@@ -71,11 +106,13 @@ func (u *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updatedUser, err := u.userService.UpdateUser(r.Context(), id, &payload)
+	updatedUser, err := u.userService.UpdateUser(r.Context(), userId, id, &payload)
 	if err != nil {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
 			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only update your own profile")
 		default:
 			helper.InternalServerError(w, "Internal server error", err)
 		}
@@ -85,6 +122,143 @@ func (u *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	helper.SuccessResponse(w, "user successfully updated", updatedUser)
 }
 
+func (u *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	u.uploadImage(w, r, u.userService.UploadAvatar, "avatar successfully updated")
+}
+
+func (u *UserHandler) UploadBanner(w http.ResponseWriter, r *http.Request) {
+	u.uploadImage(w, r, u.userService.UploadBanner, "banner successfully updated")
+}
+
+func (u *UserHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	u.deleteImage(w, r, u.userService.DeleteAvatar, "avatar successfully removed")
+}
+
+func (u *UserHandler) DeleteBanner(w http.ResponseWriter, r *http.Request) {
+	u.deleteImage(w, r, u.userService.DeleteBanner, "banner successfully removed")
+}
+
+// uploadImage handles a multipart request carrying a profile image in its
+// "image" field.
+func (u *UserHandler) uploadImage(w http.ResponseWriter, r *http.Request, upload func(ctx context.Context, actorId, id string, data []byte) (*dto.UserResp, error), message string) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	data, err := helper.ReadFormFile(w, r, "image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			helper.PayloadTooLargeResponse(w, "Image is too large")
+			return
+		}
+		helper.BadRequestResponse(w, "Invalid given image", err)
+		return
+	}
+
+	user, err := upload(r.Context(), userId, id, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only update your own profile")
+		case errors.Is(err, repository.ErrImageTooLarge):
+			helper.PayloadTooLargeResponse(w, "Image is too large")
+		case errors.Is(err, repository.ErrInvalidImage):
+			helper.BadRequestResponse(w, "Invalid given image", err)
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, message, user)
+}
+
+func (u *UserHandler) deleteImage(w http.ResponseWriter, r *http.Request, remove func(ctx context.Context, actorId, id string) (*dto.UserResp, error), message string) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	user, err := remove(r.Context(), userId, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only update your own profile")
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, message, user)
+}
+
+func (u *UserHandler) UpdateHandle(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	var payload dto.UpdateHandleReq
+	if err := helper.ReadJSON(w, r, &payload); err != nil {
+		helper.BadRequestResponse(w, "Invalid given payload", err)
+		return
+	}
+
+	v := helper.NewValidator()
+	dto.ValidateUpdateHandleReq(v, &payload)
+	if !v.Valid() {
+		helper.FailedValidationResponse(w, "Invalid given payload")
+		return
+	}
+
+	updatedUser, err := u.userService.UpdateHandle(r.Context(), userId, id, &payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only change your own handle")
+		case errors.Is(err, repository.ErrDuplicateHandle):
+			helper.EditConflictResponse(w, "Handle is already taken", err)
+		case errors.Is(err, repository.ErrTooManyAttempts):
+			helper.RateLimitExceededResponse(w, "Handle was changed too recently")
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "handle successfully updated", updatedUser)
+}
+
 func (u *UserHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
 	targetId := httprouter.ParamsFromContext(r.Context()).ByName("id")
 	if targetId == "" {
@@ -105,6 +279,8 @@ func (u *UserHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
 			helper.NotFoundResponse(w, "User not found")
 		case errors.Is(err, repository.ErrCannotFollowSelf):
 			helper.BadRequestResponse(w, "Cannot follow yourself", err)
+		case errors.Is(err, repository.ErrBlocked):
+			helper.ForbiddenResponse(w, "You cannot follow this user")
 		default:
 			helper.InternalServerError(w, "Failed to toggle follow", err)
 		}
@@ -114,6 +290,256 @@ func (u *UserHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
 	helper.SuccessResponse(w, "Follow status toggled successfully", resp)
 }
 
+func (u *UserHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	u.listFollows(w, r, "Followers retrieved successfully", u.userService.GetFollowers)
+}
+
+func (u *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	u.listFollows(w, r, "Following retrieved successfully", u.userService.GetFollowing)
+}
+
+func (u *UserHandler) GetMutuals(w http.ResponseWriter, r *http.Request) {
+	u.listFollows(w, r, "Mutual connections retrieved successfully", u.userService.GetMutuals)
+}
+
+func (u *UserHandler) GetRelationship(w http.ResponseWriter, r *http.Request) {
+	viewerId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", errors.New("user id not found in context"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	relationship, err := u.userService.GetRelationship(r.Context(), viewerId, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given user id", err)
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, "Relationship retrieved successfully", relationship)
+}
+
+func (u *UserHandler) listFollows(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	list func(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error),
+) {
+	viewerId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", errors.New("user id not found in context"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	entries, nextCursor, err := list(r.Context(), viewerId, id, query.Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, utils.ErrInvalidCursor), errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given cursor", err)
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.CursorPaginatedSuccessResponse(w, message, entries, helper.CursorMeta{
+		Limit:      int64(limit),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	})
+}
+
+func (u *UserHandler) GetFollowRequests(w http.ResponseWriter, r *http.Request) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", errors.New("user id not found in context"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	requests, nextCursor, err := u.userService.GetFollowRequests(r.Context(), userId, query.Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidCursor), errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given cursor", err)
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.CursorPaginatedSuccessResponse(w, "Follow requests retrieved successfully", requests, helper.CursorMeta{
+		Limit:      int64(limit),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	})
+}
+
+func (u *UserHandler) ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	u.resolveFollowRequest(w, r, "Follow request approved", u.userService.ApproveFollowRequest)
+}
+
+func (u *UserHandler) RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	u.resolveFollowRequest(w, r, "Follow request rejected", u.userService.RejectFollowRequest)
+}
+
+func (u *UserHandler) resolveFollowRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	resolve func(ctx context.Context, actorId, requestId string) error,
+) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", errors.New("user id not found in context"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given request id", errors.New("invalid request id"))
+		return
+	}
+
+	if err := resolve(r.Context(), userId, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound), errors.Is(err, repository.ErrInvalidId):
+			helper.NotFoundResponse(w, "Follow request not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only answer requests sent to you")
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, message, nil)
+}
+
+func (u *UserHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	u.restrictUser(w, r, "User blocked successfully", u.userService.Block)
+}
+
+func (u *UserHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	u.restrictUser(w, r, "User unblocked successfully", u.userService.Unblock)
+}
+
+func (u *UserHandler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	u.restrictUser(w, r, "User muted successfully", u.userService.Mute)
+}
+
+func (u *UserHandler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	u.restrictUser(w, r, "User unmuted successfully", u.userService.Unmute)
+}
+
+func (u *UserHandler) restrictUser(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	action func(ctx context.Context, actorId, targetId string) error,
+) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", errors.New("user id not found in context"))
+		return
+	}
+
+	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
+		return
+	}
+
+	if err := action(r.Context(), userId, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrCannotBlockSelf), errors.Is(err, repository.ErrCannotMuteSelf), errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given user id", err)
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(w, message, nil)
+}
+
+func (u *UserHandler) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	u.listRestricted(w, r, "Blocked users retrieved successfully", u.userService.GetBlocks)
+}
+
+func (u *UserHandler) GetMutes(w http.ResponseWriter, r *http.Request) {
+	u.listRestricted(w, r, "Muted users retrieved successfully", u.userService.GetMutes)
+}
+
+func (u *UserHandler) listRestricted(
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	list func(ctx context.Context, userId, cursor string, limit int) ([]*dto.RestrictedUserResp, string, error),
+) {
+	userId, exists := utils.UserIdFromContext(r.Context())
+	if !exists {
+		helper.BadRequestResponse(w, "Invalid user id from token", errors.New("user id not found in context"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	entries, nextCursor, err := list(r.Context(), userId, query.Get("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrInvalidCursor), errors.Is(err, repository.ErrInvalidId):
+			helper.BadRequestResponse(w, "Invalid given cursor", err)
+		default:
+			helper.InternalServerError(w, "Internal server error", err)
+		}
+		return
+	}
+
+	helper.CursorPaginatedSuccessResponse(w, message, entries, helper.CursorMeta{
+		Limit:      int64(limit),
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	})
+}
+
 func (u *UserHandler) GetSuggestedUsers(w http.ResponseWriter, r *http.Request) {
 	userId, exists := utils.UserIdFromContext(r.Context())
 	if !exists {
@@ -121,13 +547,28 @@ func (u *UserHandler) GetSuggestedUsers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	users, err := u.userService.GetSuggestedUsers(r.Context(), userId)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	suggestions, total, err := u.userService.GetSuggestedUsers(r.Context(), userId, page, limit)
 	if err != nil {
 		helper.InternalServerError(w, "Failed to get suggested users", err)
 		return
 	}
 
-	helper.SuccessResponse(w, "Suggested users retrieved successfully", users)
+	helper.PaginatedSuccessResponse(w, "Suggested users retrieved successfully", suggestions, helper.PaginatedMeta{
+		Page:      int64(page),
+		Limit:     int64(limit),
+		Total:     total,
+		TotalPage: int64(math.Ceil(float64(total) / float64(limit))),
+	})
 }
 
 func (u *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
@@ -138,27 +579,30 @@ func (u *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id := httprouter.ParamsFromContext(r.Context()).ByName("id")
-	if id == "" || id != userId {
-		helper.UnauthorizedResponse(w, "You can only update your own profile")
+	if id == "" {
+		helper.BadRequestResponse(w, "Invalid given user id", errors.New("invalid user id"))
 		return
 	}
 
-	if err := u.userService.DeleteUser(r.Context(), id); err != nil {
+	if err := u.accountService.Deactivate(r.Context(), userId, id); err != nil {
 		switch {
 		case errors.Is(err, repository.ErrRecordNotFound):
 			helper.NotFoundResponse(w, "User not found")
+		case errors.Is(err, repository.ErrForbidden):
+			helper.ForbiddenResponse(w, "You can only delete your own account")
 		default:
 			helper.InternalServerError(w, "Failed to delete user", err)
 		}
 		return
 	}
 
-	helper.SuccessResponse(w, "User deleted successfully", nil)
+	helper.SuccessResponse(w, "Account deactivated, log in again within the grace period to restore it", nil)
 }
 
-func NewUserHandler(userService service.UserService, postService service.PostService) *UserHandler {
+func NewUserHandler(userService service.UserService, postService service.PostService, accountService service.AccountService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		postService: postService,
+		userService:    userService,
+		postService:    postService,
+		accountService: accountService,
 	}
 }
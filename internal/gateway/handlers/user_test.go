@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// authorize mirrors the service rule: users manage their own account, and
+// holders of the manage users permission manage everyone's.
+func authorize(ctx context.Context, actorId, id string) error {
+	if actorId != id && !utils.HasPermission(ctx, domain.PermissionManageUsers) {
+		return repository.ErrForbidden
+	}
+	return nil
+}
+
+type stubUserService struct {
+	service.UserService
+}
+
+func (s *stubUserService) UpdateUser(ctx context.Context, actorId, id string, input *dto.UpdateUserReq) (*dto.UserResp, error) {
+	if err := authorize(ctx, actorId, id); err != nil {
+		return nil, err
+	}
+	if id == "missing" {
+		return nil, repository.ErrRecordNotFound
+	}
+	return &dto.UserResp{Id: id, FirstName: *input.FirstName}, nil
+}
+
+type stubAccountService struct {
+	service.AccountService
+}
+
+func (s *stubAccountService) Deactivate(ctx context.Context, actorId, id string) error {
+	if err := authorize(ctx, actorId, id); err != nil {
+		return err
+	}
+	if id == "missing" {
+		return repository.ErrRecordNotFound
+	}
+	return nil
+}
+
+func newUserRequest(method, body, actorId, role, id string) *http.Request {
+	r := httptest.NewRequest(method, "/v1/user/"+id, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	ctx := utils.WithUserId(r.Context(), actorId)
+	ctx = utils.WithRole(ctx, role, domain.PermissionsFor(role))
+	ctx = context.WithValue(ctx, httprouter.ParamsKey, httprouter.Params{{Key: "id", Value: id}})
+
+	return r.WithContext(ctx)
+}
+
+func TestUserHandlerManageOtherUsers(t *testing.T) {
+	handler := NewUserHandler(&stubUserService{}, nil, &stubAccountService{})
+
+	tests := []struct {
+		name       string
+		method     string
+		handle     http.HandlerFunc
+		actorId    string
+		role       string
+		id         string
+		wantStatus int
+	}{
+		{name: "update own profile", method: http.MethodPatch, handle: handler.UpdateUser, actorId: "1", role: domain.RoleUser, id: "1", wantStatus: http.StatusOK},
+		{name: "update another user", method: http.MethodPatch, handle: handler.UpdateUser, actorId: "1", role: domain.RoleUser, id: "2", wantStatus: http.StatusForbidden},
+		{name: "moderator updates another user", method: http.MethodPatch, handle: handler.UpdateUser, actorId: "1", role: domain.RoleModerator, id: "2", wantStatus: http.StatusForbidden},
+		{name: "admin updates another user", method: http.MethodPatch, handle: handler.UpdateUser, actorId: "1", role: domain.RoleAdmin, id: "2", wantStatus: http.StatusOK},
+		{name: "admin updates missing user", method: http.MethodPatch, handle: handler.UpdateUser, actorId: "1", role: domain.RoleAdmin, id: "missing", wantStatus: http.StatusNotFound},
+		{name: "delete own account", method: http.MethodDelete, handle: handler.DeleteUser, actorId: "1", role: domain.RoleUser, id: "1", wantStatus: http.StatusOK},
+		{name: "delete another user", method: http.MethodDelete, handle: handler.DeleteUser, actorId: "1", role: domain.RoleUser, id: "2", wantStatus: http.StatusForbidden},
+		{name: "moderator deletes another user", method: http.MethodDelete, handle: handler.DeleteUser, actorId: "1", role: domain.RoleModerator, id: "2", wantStatus: http.StatusForbidden},
+		{name: "admin deletes another user", method: http.MethodDelete, handle: handler.DeleteUser, actorId: "1", role: domain.RoleAdmin, id: "2", wantStatus: http.StatusOK},
+		{name: "admin deletes missing user", method: http.MethodDelete, handle: handler.DeleteUser, actorId: "1", role: domain.RoleAdmin, id: "missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handle(w, newUserRequest(tt.method, `{"first_name":"Janet"}`, tt.actorId, tt.role, tt.id))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
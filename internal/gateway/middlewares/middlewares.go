@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/saleh-ghazimoradi/X-Gopher/config"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/helper"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
 	"github.com/saleh-ghazimoradi/X-Gopher/utils"
 	"github.com/tomasen/realip"
 	"golang.org/x/time/rate"
@@ -20,8 +21,11 @@ type client struct {
 }
 
 type Middleware struct {
-	config *config.Config
-	logger *slog.Logger
+	config                     *config.Config
+	logger                     *slog.Logger
+	personalAccessTokenService service.PersonalAccessTokenService
+	oauthService               service.OAuthService
+	accountService             service.AccountService
 }
 
 func (m *Middleware) Logging(next http.Handler) http.Handler {
@@ -103,6 +107,14 @@ func (m *Middleware) RateLimit(next http.Handler) http.Handler {
 	})
 }
 
+// ClientInfo stores the caller's IP and user agent in the request context for services that need them.
+func (m *Middleware) ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := utils.WithClientInfo(r.Context(), realip.FromRequest(r), r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -117,22 +129,102 @@ func (m *Middleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		claims, err := utils.ValidateToken(tokenParts[1], m.config.JWT.Secret)
-		if err != nil {
-			helper.UnauthorizedResponse(w, "Invalid token")
-			return
+		ctx := r.Context()
+		switch {
+		case strings.HasPrefix(tokenParts[1], utils.PersonalAccessTokenPrefix):
+			pat, err := m.personalAccessTokenService.Authenticate(ctx, tokenParts[1])
+			if err != nil {
+				helper.UnauthorizedResponse(w, "Invalid token")
+				return
+			}
+			ctx = utils.WithUserId(ctx, pat.UserId)
+			ctx = utils.WithScopes(ctx, pat.Scopes)
+		case strings.HasPrefix(tokenParts[1], utils.OAuthAccessTokenPrefix):
+			oauthToken, err := m.oauthService.Authenticate(ctx, tokenParts[1])
+			if err != nil {
+				helper.UnauthorizedResponse(w, "Invalid token")
+				return
+			}
+			ctx = utils.WithUserId(ctx, oauthToken.UserId)
+			ctx = utils.WithScopes(ctx, oauthToken.Scopes)
+		default:
+			claims, err := utils.ValidateToken(m.config, tokenParts[1], utils.TokenTypeAccess)
+			if err != nil {
+				helper.UnauthorizedResponse(w, "Invalid token")
+				return
+			}
+			// Deactivation revokes the stored credentials, but access tokens stay valid until they expire.
+			active, err := m.accountService.IsActive(ctx, claims.UserId)
+			if err != nil {
+				helper.InternalServerError(w, "Internal server error", err)
+				return
+			}
+			if !active {
+				helper.UnauthorizedResponse(w, "Invalid token")
+				return
+			}
+			ctx = utils.WithUserId(ctx, claims.UserId)
+			ctx = utils.WithRole(ctx, claims.Role, claims.Permissions)
 		}
 
-		ctx := r.Context()
-		ctx = utils.WithUserId(ctx, claims.UserId)
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	})
 }
 
-func NewMiddleware(config *config.Config, logger *slog.Logger) *Middleware {
+// OptionalAuthenticate lets anonymous requests through and authenticates the rest,
+// so public routes can tailor their response to the caller.
+func (m *Middleware) OptionalAuthenticate(next http.Handler) http.Handler {
+	authenticated := m.Authenticate(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// RequireScope rejects scoped requests, e.g. personal access tokens, that were not granted the given scope.
+func (m *Middleware) RequireScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !utils.HasScope(r.Context(), scope) {
+			helper.ForbiddenResponse(w, "Token is missing the required scope: "+scope)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission rejects requests whose role does not grant the given permission.
+// Scoped tokens never carry a role, so privileged routes always need a login session.
+func (m *Middleware) RequirePermission(permission string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !utils.HasPermission(r.Context(), permission) {
+			helper.ForbiddenResponse(w, "You do not have permission to perform this action")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireSession only lets through requests authenticated with a login session, not with a scoped token.
+func (m *Middleware) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, restricted := utils.ScopesFromContext(r.Context()); restricted {
+			helper.ForbiddenResponse(w, "This action requires a login session")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func NewMiddleware(config *config.Config, logger *slog.Logger, personalAccessTokenService service.PersonalAccessTokenService, oauthService service.OAuthService, accountService service.AccountService) *Middleware {
 	return &Middleware{
-		config: config,
-		logger: logger,
+		config:                     config,
+		logger:                     logger,
+		personalAccessTokenService: personalAccessTokenService,
+		oauthService:               oauthService,
+		accountService:             accountService,
 	}
 }
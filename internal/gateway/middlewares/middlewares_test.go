@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type stubAccountService struct {
+	service.AccountService
+	active map[string]bool
+	err    error
+}
+
+func (s *stubAccountService) IsActive(ctx context.Context, userId string) (bool, error) {
+	return s.active[userId], s.err
+}
+
+func TestAuthenticateRejectsInactiveUsers(t *testing.T) {
+	cfg := &config.Config{
+		JWT: config.JWT{
+			Secret:              "test-secret",
+			ExpiresIn:           15 * time.Minute,
+			RefreshTokenExpires: 24 * time.Hour,
+			Issuer:              "x-gopher",
+			Audience:            "x-gopher-api",
+			Leeway:              30 * time.Second,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		userId     string
+		err        error
+		wantStatus int
+	}{
+		{name: "active user", userId: "active", wantStatus: http.StatusOK},
+		{name: "deactivated user", userId: "deactivated", wantStatus: http.StatusUnauthorized},
+		{name: "purged user", userId: "purged", wantStatus: http.StatusUnauthorized},
+		{name: "lookup failure", userId: "active", err: errors.New("database down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts := &stubAccountService{active: map[string]bool{"active": true, "deactivated": false}, err: tt.err}
+			m := NewMiddleware(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, accounts)
+
+			access, _, err := utils.GenerateToken(cfg, tt.userId, tt.userId+"@example.com", "user", nil)
+			if err != nil {
+				t.Fatalf("generate token: %v", err)
+			}
+
+			var gotUserId string
+			handler := m.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUserId, _ = utils.UserIdFromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/posts", nil)
+			r.Header.Set("Authorization", "Bearer "+access)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && gotUserId != tt.userId {
+				t.Errorf("expected user %q in context, got %q", tt.userId, gotUserId)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type AuditLogRoute struct {
+	middlewares     *middlewares.Middleware
+	auditLogHandler *handlers.AuditLogHandler
+}
+
+func (a *AuditLogRoute) AuditLogRoutes(router *httprouter.Router) {
+	router.Handler(http.MethodGet, "/v1/audit-log", a.middlewares.Authenticate(a.middlewares.RequireSession(http.HandlerFunc(a.auditLogHandler.GetMyEvents))))
+	router.Handler(http.MethodGet, "/v1/admin/audit-log", a.middlewares.Authenticate(a.middlewares.RequirePermission(domain.PermissionReadAuditLog, http.HandlerFunc(a.auditLogHandler.QueryEvents))))
+}
+
+func NewAuditLogRoute(middlewares *middlewares.Middleware, auditLogHandler *handlers.AuditLogHandler) *AuditLogRoute {
+	return &AuditLogRoute{
+		middlewares:     middlewares,
+		auditLogHandler: auditLogHandler,
+	}
+}
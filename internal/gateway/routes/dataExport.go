@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type DataExportRoute struct {
+	middlewares       *middlewares.Middleware
+	dataExportHandler *handlers.DataExportHandler
+}
+
+func (d *DataExportRoute) DataExportRoutes(router *httprouter.Router) {
+	router.Handler(http.MethodPost, "/v1/exports", d.middlewares.Authenticate(d.middlewares.RequireSession(http.HandlerFunc(d.dataExportHandler.RequestExport))))
+	router.Handler(http.MethodGet, "/v1/exports", d.middlewares.Authenticate(d.middlewares.RequireSession(http.HandlerFunc(d.dataExportHandler.GetExports))))
+	router.HandlerFunc(http.MethodGet, "/v1/exports/:id/download", d.dataExportHandler.Download)
+}
+
+func NewDataExportRoute(middlewares *middlewares.Middleware, dataExportHandler *handlers.DataExportHandler) *DataExportRoute {
+	return &DataExportRoute{
+		middlewares:       middlewares,
+		dataExportHandler: dataExportHandler,
+	}
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type ListRoute struct {
+	middlewares *middlewares.Middleware
+	listHandler *handlers.ListHandler
+}
+
+func (l *ListRoute) ListRoutes(router *httprouter.Router) {
+	// Public Routes
+	router.Handler(http.MethodGet, "/v1/list/:id", l.wrapOptionalAuth(domain.ScopeListsRead, l.listHandler.GetList))
+	router.Handler(http.MethodGet, "/v1/list/:id/members", l.wrapOptionalAuth(domain.ScopeListsRead, l.listHandler.GetMembers))
+	router.Handler(http.MethodGet, "/v1/list/:id/timeline", l.wrapOptionalAuth(domain.ScopeListsRead, l.listHandler.GetTimeline))
+	router.Handler(http.MethodGet, "/v1/user/:id/lists", l.wrapOptionalAuth(domain.ScopeListsRead, l.listHandler.GetUserLists))
+
+	// Protected Routes
+	router.Handler(http.MethodPost, "/v1/list", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.CreateList))
+	router.Handler(http.MethodGet, "/v1/lists", l.wrapAuth(domain.ScopeListsRead, l.listHandler.GetMyLists))
+	router.Handler(http.MethodGet, "/v1/lists/subscribed", l.wrapAuth(domain.ScopeListsRead, l.listHandler.GetSubscribedLists))
+	router.Handler(http.MethodPatch, "/v1/list/:id", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.UpdateList))
+	router.Handler(http.MethodDelete, "/v1/list/:id", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.DeleteList))
+	router.Handler(http.MethodPost, "/v1/list/:id/members/:userId", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.AddMember))
+	router.Handler(http.MethodDelete, "/v1/list/:id/members/:userId", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.RemoveMember))
+	router.Handler(http.MethodPost, "/v1/list/:id/subscribe", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.Subscribe))
+	router.Handler(http.MethodDelete, "/v1/list/:id/subscribe", l.wrapAuth(domain.ScopeListsWrite, l.listHandler.Unsubscribe))
+}
+
+func (l *ListRoute) wrapAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return l.middlewares.Authenticate(l.middlewares.RequireScope(scope, handler))
+}
+
+func (l *ListRoute) wrapOptionalAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return l.middlewares.OptionalAuthenticate(l.middlewares.RequireScope(scope, handler))
+}
+
+func NewListRoute(middlewares *middlewares.Middleware, listHandler *handlers.ListHandler) *ListRoute {
+	return &ListRoute{
+		middlewares: middlewares,
+		listHandler: listHandler,
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"net/http"
+)
+
+type MagicLinkRoute struct {
+	magicLinkHandler *handlers.MagicLinkHandler
+}
+
+func (m *MagicLinkRoute) MagicLinkRoutes(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodPost, "/v1/auth/magic-link", m.magicLinkHandler.RequestLink)
+	router.HandlerFunc(http.MethodPost, "/v1/auth/magic-link/redeem", m.magicLinkHandler.RedeemLink)
+}
+
+func NewMagicLinkRoute(magicLinkHandler *handlers.MagicLinkHandler) *MagicLinkRoute {
+	return &MagicLinkRoute{
+		magicLinkHandler: magicLinkHandler,
+	}
+}
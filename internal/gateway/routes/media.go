@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type MediaRoute struct {
+	middlewares  *middlewares.Middleware
+	mediaHandler *handlers.MediaHandler
+}
+
+func (m *MediaRoute) MediaRoutes(router *httprouter.Router) {
+	// Protected Routes
+	router.Handler(http.MethodPost, "/v1/media", m.wrapAuth(domain.ScopePostsWrite, m.mediaHandler.UploadMedia))
+	router.Handler(http.MethodGet, "/v1/media/:id", m.wrapAuth(domain.ScopePostsRead, m.mediaHandler.GetMedia))
+	router.Handler(http.MethodDelete, "/v1/media/:id", m.wrapAuth(domain.ScopePostsWrite, m.mediaHandler.DeleteMedia))
+	router.Handler(http.MethodPost, "/v1/uploads", m.wrapAuth(domain.ScopePostsWrite, m.mediaHandler.CreateUpload))
+	router.Handler(http.MethodPatch, "/v1/uploads/:id", m.wrapAuth(domain.ScopePostsWrite, m.mediaHandler.UploadChunk))
+}
+
+func (m *MediaRoute) wrapAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return m.middlewares.Authenticate(m.middlewares.RequireScope(scope, handler))
+}
+
+func NewMediaRoute(middlewares *middlewares.Middleware, mediaHandler *handlers.MediaHandler) *MediaRoute {
+	return &MediaRoute{
+		middlewares:  middlewares,
+		mediaHandler: mediaHandler,
+	}
+}
@@ -2,23 +2,31 @@ package routes
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
 	"net/http"
 )
 
 type MessageRoute struct {
+	middlewares    *middlewares.Middleware
 	messageHandler *handlers.MessageHandler
 }
 
 func (m *MessageRoute) MessageRoutes(router *httprouter.Router) {
-	router.HandlerFunc(http.MethodPost, "/v1/message/send", m.messageHandler.SendMessage)
-	router.HandlerFunc(http.MethodGet, "/v1/messages", m.messageHandler.GetMessages)
-	router.HandlerFunc(http.MethodGet, "/v1/messages/unread", m.messageHandler.GetUnreadSummary)
-	router.HandlerFunc(http.MethodPatch, "/v1/messages/read", m.messageHandler.MarkAsRead)
+	router.Handler(http.MethodPost, "/v1/message/send", m.wrapAuth(domain.ScopeMessagesWrite, m.messageHandler.SendMessage))
+	router.Handler(http.MethodGet, "/v1/messages", m.wrapAuth(domain.ScopeMessagesRead, m.messageHandler.GetMessages))
+	router.Handler(http.MethodGet, "/v1/messages/unread", m.wrapAuth(domain.ScopeMessagesRead, m.messageHandler.GetUnreadSummary))
+	router.Handler(http.MethodPatch, "/v1/messages/read", m.wrapAuth(domain.ScopeMessagesWrite, m.messageHandler.MarkAsRead))
 }
 
-func NewMessageRoute(messageHandler *handlers.MessageHandler) *MessageRoute {
+func (m *MessageRoute) wrapAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return m.middlewares.Authenticate(m.middlewares.RequireScope(scope, handler))
+}
+
+func NewMessageRoute(middlewares *middlewares.Middleware, messageHandler *handlers.MessageHandler) *MessageRoute {
 	return &MessageRoute{
+		middlewares:    middlewares,
 		messageHandler: messageHandler,
 	}
 }
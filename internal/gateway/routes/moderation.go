@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type ModerationRoute struct {
+	middlewares       *middlewares.Middleware
+	moderationHandler *handlers.ModerationHandler
+}
+
+func (m *ModerationRoute) ModerationRoutes(router *httprouter.Router) {
+	router.Handler(http.MethodDelete, "/v1/moderation/post/:id", m.wrapAuth(domain.PermissionDeleteAnyPost, m.moderationHandler.DeletePost))
+	router.Handler(http.MethodDelete, "/v1/moderation/comment/:id", m.wrapAuth(domain.PermissionDeleteAnyComment, m.moderationHandler.DeleteComment))
+	router.Handler(http.MethodGet, "/v1/moderation/log", m.wrapAuth(domain.PermissionReadModerationLog, m.moderationHandler.GetActions))
+	router.Handler(http.MethodPatch, "/v1/admin/user/:id/role", m.wrapAuth(domain.PermissionManageRoles, m.moderationHandler.UpdateRole))
+}
+
+func (m *ModerationRoute) wrapAuth(permission string, handler http.HandlerFunc) http.Handler {
+	return m.middlewares.Authenticate(m.middlewares.RequirePermission(permission, handler))
+}
+
+func NewModerationRoute(middlewares *middlewares.Middleware, moderationHandler *handlers.ModerationHandler) *ModerationRoute {
+	return &ModerationRoute{
+		middlewares:       middlewares,
+		moderationHandler: moderationHandler,
+	}
+}
@@ -2,21 +2,29 @@ package routes
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
 	"net/http"
 )
 
 type NotificationRoute struct {
+	middlewares         *middlewares.Middleware
 	notificationHandler *handlers.NotificationHandler
 }
 
 func (n *NotificationRoute) NotificationRoutes(router *httprouter.Router) {
-	router.HandlerFunc(http.MethodGet, "/v1/notifications/mark-read", n.notificationHandler.MarkAsRead)
-	router.HandlerFunc(http.MethodGet, "/v1/notification/:id", n.notificationHandler.GetUserNotifications)
+	router.Handler(http.MethodGet, "/v1/notifications/mark-read", n.wrapAuth(domain.ScopeNotificationsWrite, n.notificationHandler.MarkAsRead))
+	router.Handler(http.MethodGet, "/v1/notification/:id", n.wrapAuth(domain.ScopeNotificationsRead, n.notificationHandler.GetUserNotifications))
 }
 
-func NewNotificationRoute(notificationHandler *handlers.NotificationHandler) *NotificationRoute {
+func (n *NotificationRoute) wrapAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return n.middlewares.Authenticate(n.middlewares.RequireScope(scope, handler))
+}
+
+func NewNotificationRoute(middlewares *middlewares.Middleware, notificationHandler *handlers.NotificationHandler) *NotificationRoute {
 	return &NotificationRoute{
+		middlewares:         middlewares,
 		notificationHandler: notificationHandler,
 	}
 }
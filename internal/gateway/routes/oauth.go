@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type OAuthRoute struct {
+	middlewares  *middlewares.Middleware
+	oauthHandler *handlers.OAuthHandler
+}
+
+func (o *OAuthRoute) OAuthRoutes(router *httprouter.Router) {
+	// Client-authenticated endpoints
+	router.HandlerFunc(http.MethodPost, "/v1/oauth/token", o.oauthHandler.Token)
+	router.HandlerFunc(http.MethodPost, "/v1/oauth/revoke", o.oauthHandler.Revoke)
+
+	// Protected Routes
+	router.Handler(http.MethodGet, "/v1/oauth/authorize", o.wrapAuth(o.oauthHandler.GetConsent))
+	router.Handler(http.MethodPost, "/v1/oauth/authorize", o.wrapAuth(o.oauthHandler.Authorize))
+	router.Handler(http.MethodPost, "/v1/oauth/apps", o.wrapAuth(o.oauthHandler.RegisterClient))
+	router.Handler(http.MethodGet, "/v1/oauth/apps", o.wrapAuth(o.oauthHandler.GetClients))
+	router.Handler(http.MethodDelete, "/v1/oauth/apps/:id", o.wrapAuth(o.oauthHandler.DeleteClient))
+	router.Handler(http.MethodGet, "/v1/oauth/authorizations", o.wrapAuth(o.oauthHandler.GetAuthorizations))
+	router.Handler(http.MethodDelete, "/v1/oauth/authorizations/:clientId", o.wrapAuth(o.oauthHandler.RevokeAuthorization))
+}
+
+// wrapAuth only accepts login sessions so third-party tokens cannot grant themselves more access.
+func (o *OAuthRoute) wrapAuth(handler http.HandlerFunc) http.Handler {
+	return o.middlewares.Authenticate(o.middlewares.RequireSession(handler))
+}
+
+func NewOAuthRoute(middlewares *middlewares.Middleware, oauthHandler *handlers.OAuthHandler) *OAuthRoute {
+	return &OAuthRoute{
+		middlewares:  middlewares,
+		oauthHandler: oauthHandler,
+	}
+}
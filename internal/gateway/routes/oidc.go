@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"net/http"
+)
+
+type OIDCRoute struct {
+	oidcHandler *handlers.OIDCHandler
+}
+
+func (o *OIDCRoute) OIDCRoutes(router *httprouter.Router) {
+	router.HandlerFunc(http.MethodGet, "/v1/auth/oidc/login", o.oidcHandler.Login)
+	router.HandlerFunc(http.MethodGet, "/v1/auth/oidc/callback", o.oidcHandler.Callback)
+}
+
+func NewOIDCRoute(oidcHandler *handlers.OIDCHandler) *OIDCRoute {
+	return &OIDCRoute{
+		oidcHandler: oidcHandler,
+	}
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
+	"net/http"
+)
+
+type PersonalAccessTokenRoute struct {
+	middlewares                *middlewares.Middleware
+	personalAccessTokenHandler *handlers.PersonalAccessTokenHandler
+}
+
+func (p *PersonalAccessTokenRoute) PersonalAccessTokenRoutes(router *httprouter.Router) {
+	router.Handler(http.MethodPost, "/v1/tokens", p.wrapAuth(p.personalAccessTokenHandler.CreateToken))
+	router.Handler(http.MethodGet, "/v1/tokens", p.wrapAuth(p.personalAccessTokenHandler.GetTokens))
+	router.Handler(http.MethodDelete, "/v1/tokens/:id", p.wrapAuth(p.personalAccessTokenHandler.RevokeToken))
+}
+
+// wrapAuth only accepts login sessions so a token can never be used to mint or revoke other tokens.
+func (p *PersonalAccessTokenRoute) wrapAuth(handler http.HandlerFunc) http.Handler {
+	return p.middlewares.Authenticate(p.middlewares.RequireSession(handler))
+}
+
+func NewPersonalAccessTokenRoute(middlewares *middlewares.Middleware, personalAccessTokenHandler *handlers.PersonalAccessTokenHandler) *PersonalAccessTokenRoute {
+	return &PersonalAccessTokenRoute{
+		middlewares:                middlewares,
+		personalAccessTokenHandler: personalAccessTokenHandler,
+	}
+}
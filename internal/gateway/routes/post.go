@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
 	"net/http"
@@ -13,20 +14,31 @@ type PostRoute struct {
 }
 
 func (p *PostRoute) PostRoutes(router *httprouter.Router) {
-	router.HandlerFunc(http.MethodGet, "/v1/post/:id", p.postHandler.GetPost)
-	router.HandlerFunc(http.MethodGet, "/v1/post", p.postHandler.GetAllPosts)
-	router.HandlerFunc(http.MethodGet, "/v1/postSearch", p.postHandler.GetPostsUsersBySearch)
+	router.Handler(http.MethodGet, "/v1/post/:id", p.wrapOptionalAuth(domain.ScopePostsRead, p.postHandler.GetPost))
+	router.Handler(http.MethodGet, "/v1/post/:id/thread", p.wrapOptionalAuth(domain.ScopePostsRead, p.postHandler.GetThread))
+	router.Handler(http.MethodGet, "/v1/post/:id/comments", p.wrapOptionalAuth(domain.ScopePostsRead, p.postHandler.GetComments))
+	router.Handler(http.MethodGet, "/v1/comments/:postId/comments/:commentId/history", p.wrapOptionalAuth(domain.ScopePostsRead, p.postHandler.GetCommentHistory))
+	router.Handler(http.MethodGet, "/v1/post", p.wrapOptionalAuth(domain.ScopePostsRead, p.postHandler.GetAllPosts))
+	router.Handler(http.MethodGet, "/v1/postSearch", p.wrapOptionalAuth(domain.ScopePostsRead, p.postHandler.GetPostsUsersBySearch))
 
-	router.Handler(http.MethodPost, "/v1/post", p.wrapAuth(p.postHandler.CreatePost))
-	router.Handler(http.MethodPatch, "/v1/post/:id", p.wrapAuth(p.postHandler.UpdatePost))
-	router.Handler(http.MethodPost, "/v1/post/:id/comment", p.wrapAuth(p.postHandler.CommentPost))
-	router.Handler(http.MethodPatch, "/v1/post/:id/like", p.wrapAuth(p.postHandler.LikePost))
-	router.Handler(http.MethodDelete, "/v1/post/:id", p.wrapAuth(p.postHandler.DeletePost))
-	router.Handler(http.MethodDelete, "/v1/comments/:postId/comments/:commentId", p.wrapAuth(p.postHandler.DeleteComment))
+	router.Handler(http.MethodPost, "/v1/post", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.CreatePost))
+	router.Handler(http.MethodPatch, "/v1/post/:id", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.UpdatePost))
+	router.Handler(http.MethodPost, "/v1/post/:id/comment", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.CommentPost))
+	router.Handler(http.MethodPatch, "/v1/post/:id/like", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.LikePost))
+	router.Handler(http.MethodPost, "/v1/post/:id/repost", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.Repost))
+	router.Handler(http.MethodDelete, "/v1/post/:id/repost", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.UndoRepost))
+	router.Handler(http.MethodDelete, "/v1/post/:id", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.DeletePost))
+	router.Handler(http.MethodPatch, "/v1/comments/:postId/comments/:commentId", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.UpdateComment))
+	router.Handler(http.MethodPatch, "/v1/comments/:postId/comments/:commentId/like", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.LikeComment))
+	router.Handler(http.MethodDelete, "/v1/comments/:postId/comments/:commentId", p.wrapAuth(domain.ScopePostsWrite, p.postHandler.DeleteComment))
 }
 
-func (p *PostRoute) wrapAuth(handler http.HandlerFunc) http.Handler {
-	return p.middlewares.Authenticate(handler)
+func (p *PostRoute) wrapAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return p.middlewares.Authenticate(p.middlewares.RequireScope(scope, handler))
+}
+
+func (p *PostRoute) wrapOptionalAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return p.middlewares.OptionalAuthenticate(p.middlewares.RequireScope(scope, handler))
 }
 
 func NewPostRoute(middlewares *middlewares.Middleware, postHandler *handlers.PostHandler) *PostRoute {
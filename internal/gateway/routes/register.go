@@ -10,12 +10,22 @@ import (
 )
 
 type Register struct {
-	authRoute         *AuthRoute
-	userRoute         *UserRoute
-	postRoute         *PostRoute
-	messageRoute      *MessageRoute
-	notificationRoute *NotificationRoute
-	middlewares       *middlewares.Middleware
+	authRoute                *AuthRoute
+	userRoute                *UserRoute
+	postRoute                *PostRoute
+	messageRoute             *MessageRoute
+	notificationRoute        *NotificationRoute
+	personalAccessTokenRoute *PersonalAccessTokenRoute
+	oidcRoute                *OIDCRoute
+	oauthRoute               *OAuthRoute
+	moderationRoute          *ModerationRoute
+	magicLinkRoute           *MagicLinkRoute
+	auditLogRoute            *AuditLogRoute
+	dataExportRoute          *DataExportRoute
+	listRoute                *ListRoute
+	mediaRoute               *MediaRoute
+	uploads                  http.Handler
+	middlewares              *middlewares.Middleware
 }
 
 type Options func(*Register)
@@ -50,6 +60,68 @@ func WithNotificationRoute(notificationRoute *NotificationRoute) Options {
 	}
 }
 
+func WithPersonalAccessTokenRoute(personalAccessTokenRoute *PersonalAccessTokenRoute) Options {
+	return func(r *Register) {
+		r.personalAccessTokenRoute = personalAccessTokenRoute
+	}
+}
+
+func WithOIDCRoute(oidcRoute *OIDCRoute) Options {
+	return func(r *Register) {
+		r.oidcRoute = oidcRoute
+	}
+}
+
+func WithOAuthRoute(oauthRoute *OAuthRoute) Options {
+	return func(r *Register) {
+		r.oauthRoute = oauthRoute
+	}
+}
+
+func WithModerationRoute(moderationRoute *ModerationRoute) Options {
+	return func(r *Register) {
+		r.moderationRoute = moderationRoute
+	}
+}
+
+func WithMagicLinkRoute(magicLinkRoute *MagicLinkRoute) Options {
+	return func(r *Register) {
+		r.magicLinkRoute = magicLinkRoute
+	}
+}
+
+func WithAuditLogRoute(auditLogRoute *AuditLogRoute) Options {
+	return func(r *Register) {
+		r.auditLogRoute = auditLogRoute
+	}
+}
+
+func WithDataExportRoute(dataExportRoute *DataExportRoute) Options {
+	return func(r *Register) {
+		r.dataExportRoute = dataExportRoute
+	}
+}
+
+func WithListRoute(listRoute *ListRoute) Options {
+	return func(r *Register) {
+		r.listRoute = listRoute
+	}
+}
+
+func WithMediaRoute(mediaRoute *MediaRoute) Options {
+	return func(r *Register) {
+		r.mediaRoute = mediaRoute
+	}
+}
+
+// WithUploads serves stored uploads under /uploads, for storage backends that
+// don't serve files themselves.
+func WithUploads(uploads http.Handler) Options {
+	return func(r *Register) {
+		r.uploads = uploads
+	}
+}
+
 func WithMiddlewares(middlewares *middlewares.Middleware) Options {
 	return func(r *Register) {
 		r.middlewares = middlewares
@@ -78,7 +150,21 @@ func (r *Register) RegisterRoutes() http.Handler {
 	r.postRoute.PostRoutes(router)
 	r.messageRoute.MessageRoutes(router)
 	r.notificationRoute.NotificationRoutes(router)
-	return r.middlewares.Recover(r.middlewares.Logging(r.middlewares.CORS(r.middlewares.RateLimit(router))))
+	r.personalAccessTokenRoute.PersonalAccessTokenRoutes(router)
+	r.oauthRoute.OAuthRoutes(router)
+	r.moderationRoute.ModerationRoutes(router)
+	r.magicLinkRoute.MagicLinkRoutes(router)
+	r.auditLogRoute.AuditLogRoutes(router)
+	r.dataExportRoute.DataExportRoutes(router)
+	r.listRoute.ListRoutes(router)
+	r.mediaRoute.MediaRoutes(router)
+	if r.uploads != nil {
+		router.Handler(http.MethodGet, "/uploads/*filepath", http.StripPrefix("/uploads", r.uploads))
+	}
+	if r.oidcRoute != nil {
+		r.oidcRoute.OIDCRoutes(router)
+	}
+	return r.middlewares.Recover(r.middlewares.Logging(r.middlewares.CORS(r.middlewares.RateLimit(r.middlewares.ClientInfo(router)))))
 }
 
 func NewRegister(opts ...Options) *Register {
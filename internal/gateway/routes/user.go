@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/julienschmidt/httprouter"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/gateway/middlewares"
 	"net/http"
@@ -14,17 +15,45 @@ type UserRoute struct {
 
 func (u *UserRoute) UserRoutes(router *httprouter.Router) {
 	// Public Routes
-	router.HandlerFunc(http.MethodGet, "/v1/user/:id", u.userHandler.GetUserById)
+	router.Handler(http.MethodGet, "/v1/user/:id", u.wrapOptionalAuth(domain.ScopeUsersRead, u.userHandler.GetUserById))
+	router.Handler(http.MethodGet, "/v1/users/by-handle/:handle", u.wrapOptionalAuth(domain.ScopeUsersRead, u.userHandler.GetUserByHandle))
 
 	// Protected Routes
-	router.Handler(http.MethodPatch, "/v1/user/:id", u.wrapAuth(u.userHandler.UpdateUser))
-	router.Handler(http.MethodPatch, "/v1/user/:id/following", u.wrapAuth(u.userHandler.FollowUser))
-	router.Handler(http.MethodGet, "/v1/suggest_users", u.wrapAuth(u.userHandler.GetSuggestedUsers))
-	router.Handler(http.MethodDelete, "/v1/user/:id", u.wrapAuth(u.userHandler.DeleteUser))
+	router.Handler(http.MethodPatch, "/v1/user/:id", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.UpdateUser))
+	router.Handler(http.MethodPatch, "/v1/user/:id/handle", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.UpdateHandle))
+	router.Handler(http.MethodPut, "/v1/user/:id/avatar", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.UploadAvatar))
+	router.Handler(http.MethodDelete, "/v1/user/:id/avatar", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.DeleteAvatar))
+	router.Handler(http.MethodPut, "/v1/user/:id/banner", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.UploadBanner))
+	router.Handler(http.MethodDelete, "/v1/user/:id/banner", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.DeleteBanner))
+	router.Handler(http.MethodPatch, "/v1/user/:id/following", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.FollowUser))
+	router.Handler(http.MethodGet, "/v1/user/:id/followers", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetFollowers))
+	router.Handler(http.MethodGet, "/v1/user/:id/following", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetFollowing))
+	router.Handler(http.MethodGet, "/v1/user/:id/mutuals", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetMutuals))
+	router.Handler(http.MethodGet, "/v1/user/:id/relationship", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetRelationship))
+	router.Handler(http.MethodGet, "/v1/follow-requests", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetFollowRequests))
+	router.Handler(http.MethodPost, "/v1/follow-requests/:id/approve", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.ApproveFollowRequest))
+	router.Handler(http.MethodPost, "/v1/follow-requests/:id/reject", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.RejectFollowRequest))
+	router.Handler(http.MethodPost, "/v1/user/:id/block", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.BlockUser))
+	router.Handler(http.MethodDelete, "/v1/user/:id/block", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.UnblockUser))
+	router.Handler(http.MethodPost, "/v1/user/:id/mute", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.MuteUser))
+	router.Handler(http.MethodDelete, "/v1/user/:id/mute", u.wrapAuth(domain.ScopeUsersWrite, u.userHandler.UnmuteUser))
+	router.Handler(http.MethodGet, "/v1/blocks", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetBlocks))
+	router.Handler(http.MethodGet, "/v1/mutes", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetMutes))
+	router.Handler(http.MethodGet, "/v1/suggest_users", u.wrapAuth(domain.ScopeUsersRead, u.userHandler.GetSuggestedUsers))
+	router.Handler(http.MethodDelete, "/v1/user/:id", u.wrapSession(u.userHandler.DeleteUser))
 }
 
-func (u *UserRoute) wrapAuth(handler http.HandlerFunc) http.Handler {
-	return u.middlewares.Authenticate(handler)
+func (u *UserRoute) wrapAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return u.middlewares.Authenticate(u.middlewares.RequireScope(scope, handler))
+}
+
+// wrapSession only accepts login sessions so a scoped token can never deactivate the account.
+func (u *UserRoute) wrapSession(handler http.HandlerFunc) http.Handler {
+	return u.middlewares.Authenticate(u.middlewares.RequireSession(handler))
+}
+
+func (u *UserRoute) wrapOptionalAuth(scope string, handler http.HandlerFunc) http.Handler {
+	return u.middlewares.OptionalAuthenticate(u.middlewares.RequireScope(scope, handler))
 }
 
 func NewUserRoute(middlewares *middlewares.Middleware, userHandler *handlers.UserHandler) *UserRoute {
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"io"
+	"net/http"
+)
+
+// MaxUploadSize caps the body of multipart requests. Services enforce their
+// own, usually smaller, limits on the files themselves.
+const MaxUploadSize = 32 << 20
+
+// ReadFormFile returns the contents of the named file field of a multipart
+// request. Bodies over MaxUploadSize fail with an *http.MaxBytesError.
+func ReadFormFile(w http.ResponseWriter, r *http.Request, field string) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
@@ -21,6 +21,18 @@ type PaginatedResponse struct {
 	Meta     PaginatedMeta `json:"meta"`
 }
 
+// CursorMeta describes a page of a cursor-paginated list. NextCursor is empty on the last page.
+type CursorMeta struct {
+	Limit      int64  `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+type CursorPaginatedResponse struct {
+	Response Response
+	Meta     CursorMeta `json:"meta"`
+}
+
 func SuccessResponse(w http.ResponseWriter, message string, data any) {
 	response := Response{
 		Success: true,
@@ -78,6 +90,10 @@ func EditConflictResponse(w http.ResponseWriter, message string, err error) {
 	ErrorResponse(w, http.StatusConflict, message, err)
 }
 
+func PayloadTooLargeResponse(w http.ResponseWriter, message string) {
+	ErrorResponse(w, http.StatusRequestEntityTooLarge, message, nil)
+}
+
 func RateLimitExceededResponse(w http.ResponseWriter, message string) {
 	ErrorResponse(w, http.StatusTooManyRequests, message, nil)
 }
@@ -101,3 +117,20 @@ func PaginatedSuccessResponse(w http.ResponseWriter, message string, data any, m
 	}
 	writeJSON(w, http.StatusOK, paginatedResponse)
 }
+
+func CursorPaginatedSuccessResponse(w http.ResponseWriter, message string, data any, meta CursorMeta) {
+	cursorPaginatedResponse := CursorPaginatedResponse{
+		Response: Response{
+			Success: true,
+			Message: message,
+			Data:    data,
+		},
+		Meta: meta,
+	}
+	writeJSON(w, http.StatusOK, cursorPaginatedResponse)
+}
+
+// RawJSONResponse writes data without the Response envelope, for endpoints whose body is defined by a spec such as OAuth2.
+func RawJSONResponse(w http.ResponseWriter, statusCode int, data any) {
+	writeJSON(w, statusCode, data)
+}
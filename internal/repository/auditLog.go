@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AuditLogRepository is append-only: entries are never updated or deleted.
+type AuditLogRepository interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+	Query(ctx context.Context, filter *domain.AuditLogFilter, page, limit int) ([]*domain.AuditEvent, int64, error)
+	Each(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditEvent) error) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type auditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func (a *auditLogRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := a.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (a *auditLogRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	eventDTO, err := mongoDTO.FromAuditEventCoreToDTO(event)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.collection.InsertOne(ctx, eventDTO)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		event.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (a *auditLogRepository) Query(ctx context.Context, filter *domain.AuditLogFilter, page, limit int) ([]*domain.AuditEvent, int64, error) {
+	query, err := a.buildQuery(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := a.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := a.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var eventsDTO []mongoDTO.AuditEvent
+	if err := cursor.All(ctx, &eventsDTO); err != nil {
+		return nil, 0, err
+	}
+
+	events := make([]*domain.AuditEvent, len(eventsDTO))
+	for i, dto := range eventsDTO {
+		events[i] = mongoDTO.FromAuditEventDTOToCore(&dto)
+	}
+
+	return events, total, nil
+}
+
+// Each streams every matching event in chronological order without loading them all into memory.
+func (a *auditLogRepository) Each(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditEvent) error) error {
+	query, err := a.buildQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := a.collection.Find(ctx, query, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var eventDTO mongoDTO.AuditEvent
+		if err := cursor.Decode(&eventDTO); err != nil {
+			return err
+		}
+		if err := fn(mongoDTO.FromAuditEventDTOToCore(&eventDTO)); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (a *auditLogRepository) buildQuery(filter *domain.AuditLogFilter) (bson.M, error) {
+	query := bson.M{}
+	if filter == nil {
+		return query, nil
+	}
+
+	if filter.UserId != "" {
+		oid, err := bson.ObjectIDFromHex(filter.UserId)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidId, err)
+		}
+		query["user_id"] = oid
+	}
+	if filter.Email != "" {
+		query["email"] = filter.Email
+	}
+	if filter.Event != "" {
+		query["event"] = filter.Event
+	}
+	if filter.Outcome != "" {
+		query["outcome"] = filter.Outcome
+	}
+	if filter.IP != "" {
+		query["ip"] = filter.IP
+	}
+
+	createdAt := bson.M{}
+	if filter.From != nil {
+		createdAt["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		createdAt["$lt"] = *filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	return query, nil
+}
+
+func NewAuditLogRepository(database *mongo.Database, collectionName string) AuditLogRepository {
+	return &auditLogRepository{
+		collection: database.Collection(collectionName),
+	}
+}
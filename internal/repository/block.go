@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type BlockRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, block *domain.Block) error
+	Delete(ctx context.Context, blockerId, blockedId string) error
+	IsBlocked(ctx context.Context, userId, otherId string) (bool, error)
+	HasBlocked(ctx context.Context, blockerId, blockedId string) (bool, error)
+	GetRelatedIds(ctx context.Context, userId string) ([]string, error)
+	GetByBlockerId(ctx context.Context, blockerId string, after *domain.PageCursor, limit int) ([]*domain.Block, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type blockRepository struct {
+	collection *mongo.Collection
+}
+
+func (b *blockRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := b.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blocker_id", Value: 1}, {Key: "blocked_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "blocked_id", Value: 1}}},
+		{Keys: bson.D{{Key: "blocker_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// Create stores the block. Blocking someone already blocked is a no-op.
+func (b *blockRepository) Create(ctx context.Context, block *domain.Block) error {
+	blockDTO, err := mongoDTO.FromBlockCoreToDTO(block)
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.collection.InsertOne(ctx, blockDTO); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	}
+
+	block.Id = blockDTO.Id.Hex()
+	return nil
+}
+
+// Delete removes the block. Unblocking someone not blocked is a no-op.
+func (b *blockRepository) Delete(ctx context.Context, blockerId, blockedId string) error {
+	blockerOId, err := bson.ObjectIDFromHex(blockerId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	blockedOId, err := bson.ObjectIDFromHex(blockedId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = b.collection.DeleteOne(ctx, bson.M{"blocker_id": blockerOId, "blocked_id": blockedOId})
+	return err
+}
+
+// IsBlocked reports whether either user blocked the other.
+func (b *blockRepository) IsBlocked(ctx context.Context, userId, otherId string) (bool, error) {
+	userOId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	otherOId, err := bson.ObjectIDFromHex(otherId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	count, err := b.collection.CountDocuments(ctx, bson.M{
+		"$or": []bson.M{
+			{"blocker_id": userOId, "blocked_id": otherOId},
+			{"blocker_id": otherOId, "blocked_id": userOId},
+		},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// HasBlocked reports whether the blocker blocked the other user, ignoring the opposite direction.
+func (b *blockRepository) HasBlocked(ctx context.Context, blockerId, blockedId string) (bool, error) {
+	blockerOId, err := bson.ObjectIDFromHex(blockerId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	blockedOId, err := bson.ObjectIDFromHex(blockedId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	count, err := b.collection.CountDocuments(ctx, bson.M{"blocker_id": blockerOId, "blocked_id": blockedOId}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetRelatedIds returns everyone the user blocked or was blocked by.
+func (b *blockRepository) GetRelatedIds(ctx context.Context, userId string) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	blocks, err := b.find(ctx, bson.M{
+		"$or": []bson.M{
+			{"blocker_id": oid},
+			{"blocked_id": oid},
+		},
+	}, options.Find())
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(blocks))
+	for i, block := range blocks {
+		if block.BlockerId == userId {
+			ids[i] = block.BlockedId
+		} else {
+			ids[i] = block.BlockerId
+		}
+	}
+
+	return ids, nil
+}
+
+// GetByBlockerId returns the users the blocker blocked, newest first, starting after the cursor.
+func (b *blockRepository) GetByBlockerId(ctx context.Context, blockerId string, after *domain.PageCursor, limit int) ([]*domain.Block, error) {
+	oid, err := bson.ObjectIDFromHex(blockerId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	filter := bson.M{"blocker_id": oid}
+	if after != nil {
+		afterId, err := bson.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{"$lt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "_id": bson.M{"$lt": afterId}},
+		}
+	}
+
+	return b.find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+}
+
+func (b *blockRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = b.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"blocker_id": oid},
+			{"blocked_id": oid},
+		},
+	})
+	return err
+}
+
+func (b *blockRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*domain.Block, error) {
+	cursor, err := b.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blocksDTO []mongoDTO.Block
+	if err := cursor.All(ctx, &blocksDTO); err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*domain.Block, len(blocksDTO))
+	for i, block := range blocksDTO {
+		blocks[i] = mongoDTO.FromBlockDTOToCore(&block)
+	}
+
+	return blocks, nil
+}
+
+func NewBlockRepository(database *mongo.Database, collectionName string) BlockRepository {
+	return &blockRepository{
+		collection: database.Collection(collectionName),
+	}
+}
@@ -8,18 +8,41 @@ import (
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type CommentRepository interface {
+	EnsureIndexes(ctx context.Context) error
 	CreateComment(ctx context.Context, comment *domain.Comment) error
 	GetCommentById(ctx context.Context, id string) (*domain.Comment, error)
+	GetRoots(ctx context.Context, postId string, after *domain.PageCursor, limit int) ([]*domain.Comment, error)
+	GetByRootIds(ctx context.Context, rootIds []string) ([]*domain.Comment, error)
+	GetByParent(ctx context.Context, postId, parentId, sort string, after *domain.PageCursor, limit int) ([]*domain.Comment, error)
+	IncrementReplies(ctx context.Context, id string, delta int64) (*domain.Comment, error)
+	UpdateComment(ctx context.Context, comment *domain.Comment, previous domain.CommentRevision) error
+	SetLike(ctx context.Context, id, userId string, liked bool) error
+	RemoveLikesByUser(ctx context.Context, userId string) error
+	Tombstone(ctx context.Context, id string) error
 	DeleteComment(ctx context.Context, id string) error
+	GetIdsByUserId(ctx context.Context, userId string) ([]string, error)
+	GetByUserId(ctx context.Context, userId string) ([]*domain.Comment, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+	DeleteByPostIds(ctx context.Context, postIds []string) error
 }
 
 type commentRepository struct {
 	collection *mongo.Collection
 }
 
+func (c *commentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "root_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	return err
+}
+
 func (c *commentRepository) CreateComment(ctx context.Context, comment *domain.Comment) error {
 	commentDTO, err := mongoDTO.FromCoreCommentToDTO(comment)
 	if err != nil {
@@ -55,6 +78,226 @@ func (c *commentRepository) GetCommentById(ctx context.Context, id string) (*dom
 	return mongoDTO.FromCommentDTOToCore(&commentDTO), nil
 }
 
+// GetRoots returns the top-level comments of a post, oldest first, starting after the cursor.
+func (c *commentRepository) GetRoots(ctx context.Context, postId string, after *domain.PageCursor, limit int) ([]*domain.Comment, error) {
+	oid, err := bson.ObjectIDFromHex(postId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	filter := bson.M{"post_id": oid, "parent_id": nil}
+	if after != nil {
+		afterId, err := bson.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{"$gt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "_id": bson.M{"$gt": afterId}},
+		}
+	}
+
+	return c.find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit)))
+}
+
+// GetByRootIds returns every reply below the given top-level comments, oldest first.
+func (c *commentRepository) GetByRootIds(ctx context.Context, rootIds []string) ([]*domain.Comment, error) {
+	if len(rootIds) == 0 {
+		return []*domain.Comment{}, nil
+	}
+
+	oids, err := toObjectIds(rootIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.find(ctx, bson.M{"root_id": bson.M{"$in": oids}}, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+// GetByParent returns a page of the comments directly below parentId, or the
+// post's top-level comments when parentId is empty, in the given sort order.
+func (c *commentRepository) GetByParent(ctx context.Context, postId, parentId, sort string, after *domain.PageCursor, limit int) ([]*domain.Comment, error) {
+	oid, err := bson.ObjectIDFromHex(postId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	match := bson.M{"post_id": oid, "parent_id": nil}
+	if parentId != "" {
+		parentOId, err := bson.ObjectIDFromHex(parentId)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		match["parent_id"] = parentOId
+	}
+
+	var afterId bson.ObjectID
+	if after != nil {
+		if afterId, err = bson.ObjectIDFromHex(after.Id); err != nil {
+			return nil, ErrInvalidId
+		}
+	}
+
+	var order bson.D
+	page := bson.M{}
+	switch sort {
+	case domain.CommentSortOldest:
+		order = bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+		if after != nil {
+			page["$or"] = []bson.M{
+				{"created_at": bson.M{"$gt": after.CreatedAt}},
+				{"created_at": after.CreatedAt, "_id": bson.M{"$gt": afterId}},
+			}
+		}
+	case domain.CommentSortTop:
+		order = bson.D{{Key: "like_count", Value: -1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+		if after != nil {
+			page["$or"] = []bson.M{
+				{"like_count": bson.M{"$lt": after.Score}},
+				{"like_count": after.Score, "created_at": bson.M{"$lt": after.CreatedAt}},
+				{"like_count": after.Score, "created_at": after.CreatedAt, "_id": bson.M{"$lt": afterId}},
+			}
+		}
+	default:
+		order = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+		if after != nil {
+			page["$or"] = []bson.M{
+				{"created_at": bson.M{"$lt": after.CreatedAt}},
+				{"created_at": after.CreatedAt, "_id": bson.M{"$lt": afterId}},
+			}
+		}
+	}
+
+	cursor, err := c.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		// Likes are counted here rather than stored, so comments from before
+		// likes existed rank like any other.
+		{{Key: "$addFields", Value: bson.M{"like_count": bson.M{"$size": bson.M{"$ifNull": bson.A{"$likes", bson.A{}}}}}}},
+		{{Key: "$match", Value: page}},
+		{{Key: "$sort", Value: order}},
+		{{Key: "$limit", Value: int64(limit)}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var commentsDTO []mongoDTO.Comment
+	if err := cursor.All(ctx, &commentsDTO); err != nil {
+		return nil, err
+	}
+
+	comments := make([]*domain.Comment, len(commentsDTO))
+	for i, comment := range commentsDTO {
+		comments[i] = mongoDTO.FromCommentDTOToCore(&comment)
+	}
+
+	return comments, nil
+}
+
+// IncrementReplies adjusts the reply count of a comment and returns it as updated.
+func (c *commentRepository) IncrementReplies(ctx context.Context, id string, delta int64) (*domain.Comment, error) {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	var commentDTO mongoDTO.Comment
+	if err := c.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": oid},
+		bson.M{"$inc": bson.M{"reply_count": delta}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&commentDTO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return mongoDTO.FromCommentDTOToCore(&commentDTO), nil
+}
+
+// UpdateComment saves the new text and mentions of a comment that isn't a
+// tombstone and adds the version it replaces to the history.
+func (c *commentRepository) UpdateComment(ctx context.Context, comment *domain.Comment, previous domain.CommentRevision) error {
+	oid, err := bson.ObjectIDFromHex(comment.Id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	res, err := c.collection.UpdateOne(ctx, bson.M{"_id": oid, "deleted": bson.M{"$ne": true}}, bson.M{
+		"$set": bson.M{
+			"value":     comment.Value,
+			"mentions":  mongoDTO.FromMentionsCoreToDTO(comment.Mentions),
+			"edited_at": comment.EditedAt,
+		},
+		"$push": bson.M{"history": mongoDTO.FromCommentRevisionCoreToDTO(previous)},
+	})
+	if err != nil {
+		return fmt.Errorf("update comment: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetLike adds or removes the user's like on a comment.
+func (c *commentRepository) SetLike(ctx context.Context, id, userId string, liked bool) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	update := bson.M{"$pull": bson.M{"likes": userId}}
+	if liked {
+		update = bson.M{"$addToSet": bson.M{"likes": userId}}
+	}
+
+	res, err := c.collection.UpdateOne(ctx, bson.M{"_id": oid, "deleted": bson.M{"$ne": true}}, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (c *commentRepository) RemoveLikesByUser(ctx context.Context, userId string) error {
+	_, err := c.collection.UpdateMany(ctx, bson.M{"likes": userId}, bson.M{
+		"$pull": bson.M{"likes": userId},
+	})
+	return err
+}
+
+// Tombstone clears a comment's content but keeps it in place, so the replies
+// below it stay attached to the thread.
+func (c *commentRepository) Tombstone(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	res, err := c.collection.UpdateOne(ctx, bson.M{"_id": oid}, tombstoneUpdate)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
 func (c *commentRepository) DeleteComment(ctx context.Context, id string) error {
 	oid, err := bson.ObjectIDFromHex(id)
 	if err != nil {
@@ -74,6 +317,151 @@ func (c *commentRepository) DeleteComment(ctx context.Context, id string) error
 	return nil
 }
 
+func (c *commentRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.Comment, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	return c.find(ctx, bson.M{"user_id": oid, "deleted": bson.M{"$ne": true}}, opts)
+}
+
+func (c *commentRepository) GetIdsByUserId(ctx context.Context, userId string) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+
+	cursor, err := c.collection.Find(ctx, bson.M{"user_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var commentsDTO []mongoDTO.Comment
+	if err := cursor.All(ctx, &commentsDTO); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(commentsDTO))
+	for i, dto := range commentsDTO {
+		ids[i] = dto.Id.Hex()
+	}
+
+	return ids, nil
+}
+
+// DeleteByUserId removes the user's comments. Comments that still have
+// replies from others become tombstones, so those threads stay intact.
+func (c *commentRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	// Deleting a reply can leave its parent without replies, so repeat until
+	// nothing is left to delete. Tombstones emptied along the way go too.
+	// Comments from before threading have no reply_count at all.
+	noReplies := bson.M{"$not": bson.M{"$gt": 0}}
+	filter := bson.M{"user_id": oid, "reply_count": noReplies}
+	for {
+		leaves, err := c.find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1, "parent_id": 1}))
+		if err != nil {
+			return err
+		}
+		if len(leaves) == 0 {
+			break
+		}
+
+		ids := make([]string, len(leaves))
+		var parentIds []string
+		for i, leaf := range leaves {
+			ids[i] = leaf.Id
+			if leaf.ParentId != "" {
+				parentIds = append(parentIds, leaf.ParentId)
+			}
+		}
+
+		oids, err := toObjectIds(ids)
+		if err != nil {
+			return err
+		}
+
+		if _, err := c.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": oids}}); err != nil {
+			return err
+		}
+
+		for _, parentId := range parentIds {
+			if _, err := c.IncrementReplies(ctx, parentId, -1); err != nil && !errors.Is(err, ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		parentOIds, err := toObjectIds(parentIds)
+		if err != nil {
+			return err
+		}
+
+		filter = bson.M{
+			"reply_count": noReplies,
+			"$or": []bson.M{
+				{"user_id": oid},
+				{"_id": bson.M{"$in": parentOIds}, "deleted": true},
+			},
+		}
+	}
+
+	_, err = c.collection.UpdateMany(ctx, bson.M{"user_id": oid, "deleted": bson.M{"$ne": true}}, tombstoneUpdate)
+	return err
+}
+
+func (c *commentRepository) DeleteByPostIds(ctx context.Context, postIds []string) error {
+	if len(postIds) == 0 {
+		return nil
+	}
+
+	oids := make([]bson.ObjectID, 0, len(postIds))
+	for _, id := range postIds {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		oids = append(oids, oid)
+	}
+
+	_, err := c.collection.DeleteMany(ctx, bson.M{"post_id": bson.M{"$in": oids}})
+	return err
+}
+
+var tombstoneUpdate = bson.M{
+	"$set":   bson.M{"deleted": true, "value": ""},
+	"$unset": bson.M{"mentions": "", "likes": "", "edited_at": "", "history": ""},
+}
+
+func (c *commentRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*domain.Comment, error) {
+	cursor, err := c.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var commentsDTO []mongoDTO.Comment
+	if err := cursor.All(ctx, &commentsDTO); err != nil {
+		return nil, err
+	}
+
+	comments := make([]*domain.Comment, len(commentsDTO))
+	for i, comment := range commentsDTO {
+		comments[i] = mongoDTO.FromCommentDTOToCore(&comment)
+	}
+
+	return comments, nil
+}
+
 func NewCommentRepository(database *mongo.Database, collectionName string) CommentRepository {
 	return &commentRepository{
 		collection: database.Collection(collectionName),
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+type DataExportRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, export *domain.DataExport) error
+	GetById(ctx context.Context, id string) (*domain.DataExport, error)
+	GetByUserId(ctx context.Context, userId string) ([]*domain.DataExport, error)
+	GetExpired(ctx context.Context, now time.Time) ([]*domain.DataExport, error)
+	MarkReady(ctx context.Context, id, filePath string, size int64, completedAt time.Time) error
+	MarkFailed(ctx context.Context, id, reason string, completedAt time.Time) error
+	FailStale(ctx context.Context, startedBefore time.Time, reason string, completedAt time.Time) (int64, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type dataExportRepository struct {
+	collection *mongo.Collection
+}
+
+func (d *dataExportRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := d.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// A user has at most one export in progress.
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": domain.DataExportStatusPending}),
+		},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}},
+	})
+	return err
+}
+
+// Create stores the export. It returns ErrTooManyAttempts when the user
+// already has a pending export.
+func (d *dataExportRepository) Create(ctx context.Context, export *domain.DataExport) error {
+	exportDTO, err := mongoDTO.FromDataExportCoreToDTO(export)
+	if err != nil {
+		return err
+	}
+
+	res, err := d.collection.InsertOne(ctx, exportDTO)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTooManyAttempts
+		}
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		export.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (d *dataExportRepository) GetById(ctx context.Context, id string) (*domain.DataExport, error) {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	var exportDTO mongoDTO.DataExport
+	if err := d.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&exportDTO); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mongoDTO.FromDataExportDTOToCore(&exportDTO), nil
+}
+
+func (d *dataExportRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.DataExport, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	return d.find(ctx, bson.M{"user_id": oid}, opts)
+}
+
+func (d *dataExportRepository) GetExpired(ctx context.Context, now time.Time) ([]*domain.DataExport, error) {
+	return d.find(ctx, bson.M{"expires_at": bson.M{"$lte": now}}, options.Find())
+}
+
+func (d *dataExportRepository) MarkReady(ctx context.Context, id, filePath string, size int64, completedAt time.Time) error {
+	return d.update(ctx, id, bson.M{
+		"status":       domain.DataExportStatusReady,
+		"file_path":    filePath,
+		"size":         size,
+		"completed_at": completedAt,
+	})
+}
+
+func (d *dataExportRepository) MarkFailed(ctx context.Context, id, reason string, completedAt time.Time) error {
+	return d.update(ctx, id, bson.M{
+		"status":       domain.DataExportStatusFailed,
+		"error":        reason,
+		"completed_at": completedAt,
+	})
+}
+
+// FailStale marks every export still pending since before startedBefore as
+// failed, for builds that died with the process running them.
+func (d *dataExportRepository) FailStale(ctx context.Context, startedBefore time.Time, reason string, completedAt time.Time) (int64, error) {
+	result, err := d.collection.UpdateMany(ctx, bson.M{
+		"status":     domain.DataExportStatusPending,
+		"created_at": bson.M{"$lt": startedBefore},
+	}, bson.M{"$set": bson.M{
+		"status":       domain.DataExportStatusFailed,
+		"error":        reason,
+		"completed_at": completedAt,
+	}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (d *dataExportRepository) Delete(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = d.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
+
+// update finishes a pending export. An export already failed as stale stays
+// failed, so a build that outlives its timeout can't bring it back.
+func (d *dataExportRepository) update(ctx context.Context, id string, set bson.M) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := d.collection.UpdateOne(ctx, bson.M{"_id": oid, "status": domain.DataExportStatusPending}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (d *dataExportRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*domain.DataExport, error) {
+	cursor, err := d.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var exportsDTO []mongoDTO.DataExport
+	if err := cursor.All(ctx, &exportsDTO); err != nil {
+		return nil, err
+	}
+
+	exports := make([]*domain.DataExport, len(exportsDTO))
+	for i, dto := range exportsDTO {
+		exports[i] = mongoDTO.FromDataExportDTOToCore(&dto)
+	}
+
+	return exports, nil
+}
+
+func NewDataExportRepository(database *mongo.Database, collectionName string) DataExportRepository {
+	return &dataExportRepository{
+		collection: database.Collection(collectionName),
+	}
+}
@@ -3,9 +3,31 @@ package repository
 import "errors"
 
 var (
-	ErrDuplicateEmail   = errors.New("duplicate email")
-	ErrRecordNotFound   = errors.New("record not found")
-	ErrCannotFollowSelf = errors.New("cannot follow yourself")
-	ErrInvalidId        = errors.New("invalid id")
-	ErrUnauthorized     = errors.New("unauthorized action")
+	ErrDuplicateEmail     = errors.New("duplicate email")
+	ErrDuplicateHandle    = errors.New("duplicate handle")
+	ErrRecordNotFound     = errors.New("record not found")
+	ErrCannotFollowSelf   = errors.New("cannot follow yourself")
+	ErrCannotBlockSelf    = errors.New("cannot block yourself")
+	ErrCannotMuteSelf     = errors.New("cannot mute yourself")
+	ErrBlocked            = errors.New("blocked")
+	ErrListFull           = errors.New("list is full")
+	ErrInvalidImage       = errors.New("invalid image")
+	ErrImageTooLarge      = errors.New("image too large")
+	ErrInvalidMedia       = errors.New("invalid media")
+	ErrUploadOffset       = errors.New("upload offset mismatch")
+	ErrInvalidParent      = errors.New("invalid parent comment")
+	ErrEditWindowClosed   = errors.New("edit window closed")
+	ErrAlreadyReposted    = errors.New("already reposted")
+	ErrInvalidId          = errors.New("invalid id")
+	ErrForbidden          = errors.New("forbidden")
+	ErrEmailNotVerified   = errors.New("email not verified")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrTooManyAttempts    = errors.New("too many attempts")
+	ErrIdentityLinked     = errors.New("identity already linked")
+
+	ErrInvalidClient        = errors.New("invalid_client")
+	ErrInvalidGrant         = errors.New("invalid_grant")
+	ErrInvalidScope         = errors.New("invalid_scope")
+	ErrInvalidRedirectURI   = errors.New("invalid redirect uri")
+	ErrUnsupportedGrantType = errors.New("unsupported_grant_type")
 )
@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+// FollowRepository stores the follow graph as one document per edge. Creating
+// or removing an edge updates the denormalized counters on both users in the
+// same transaction, so the counters never drift from the edges.
+type FollowRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Follow(ctx context.Context, follow *domain.Follow) error
+	Unfollow(ctx context.Context, followerId, followeeId string) error
+	IsFollowing(ctx context.Context, followerId, followeeId string) (bool, error)
+	GetFollowingIds(ctx context.Context, userId string) ([]string, error)
+	GetFollowerIds(ctx context.Context, userId string) ([]string, error)
+	GetFollowers(ctx context.Context, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error)
+	GetFollowing(ctx context.Context, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error)
+	GetFollowersAmong(ctx context.Context, userId string, followerIds []string, after *domain.PageCursor, limit int) ([]*domain.Follow, error)
+	GetFollowedAmong(ctx context.Context, followerId string, ids []string) ([]string, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+	MigrateEmbedded(ctx context.Context) (edges int64, users int64, err error)
+}
+
+type followRepository struct {
+	collection     *mongo.Collection
+	userCollection *mongo.Collection
+}
+
+var (
+	errFollowExists   = errors.New("follow already exists")
+	errFollowNotFound = errors.New("follow not found")
+)
+
+func (f *followRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := f.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// Follow creates the edge. Following someone already followed is a no-op.
+func (f *followRepository) Follow(ctx context.Context, follow *domain.Follow) error {
+	followDTO, err := mongoDTO.FromFollowCoreToDTO(follow)
+	if err != nil {
+		return err
+	}
+
+	err = f.withTransaction(ctx, func(ctx context.Context) error {
+		if _, err := f.collection.InsertOne(ctx, followDTO); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return errFollowExists
+			}
+			return err
+		}
+		return f.incrementCounters(ctx, followDTO.FollowerId, followDTO.FolloweeId, 1)
+	})
+	if errors.Is(err, errFollowExists) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	follow.Id = followDTO.Id.Hex()
+	return nil
+}
+
+// Unfollow removes the edge. Unfollowing someone not followed is a no-op.
+func (f *followRepository) Unfollow(ctx context.Context, followerId, followeeId string) error {
+	followerOId, followeeOId, err := f.edgeIds(followerId, followeeId)
+	if err != nil {
+		return err
+	}
+
+	err = f.withTransaction(ctx, func(ctx context.Context) error {
+		result, err := f.collection.DeleteOne(ctx, bson.M{"follower_id": followerOId, "followee_id": followeeOId})
+		if err != nil {
+			return err
+		}
+		if result.DeletedCount == 0 {
+			return errFollowNotFound
+		}
+		return f.incrementCounters(ctx, followerOId, followeeOId, -1)
+	})
+	if errors.Is(err, errFollowNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func (f *followRepository) IsFollowing(ctx context.Context, followerId, followeeId string) (bool, error) {
+	followerOId, followeeOId, err := f.edgeIds(followerId, followeeId)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := f.collection.CountDocuments(ctx, bson.M{"follower_id": followerOId, "followee_id": followeeOId}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (f *followRepository) GetFollowingIds(ctx context.Context, userId string) ([]string, error) {
+	return f.getIds(ctx, "follower_id", "followee_id", userId)
+}
+
+func (f *followRepository) GetFollowerIds(ctx context.Context, userId string) ([]string, error) {
+	return f.getIds(ctx, "followee_id", "follower_id", userId)
+}
+
+// GetFollowers returns the edges pointing at the user, newest first, starting after the cursor.
+func (f *followRepository) GetFollowers(ctx context.Context, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error) {
+	return f.getPage(ctx, "followee_id", userId, after, limit)
+}
+
+// GetFollowing returns the edges starting at the user, newest first, starting after the cursor.
+func (f *followRepository) GetFollowing(ctx context.Context, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error) {
+	return f.getPage(ctx, "follower_id", userId, after, limit)
+}
+
+// GetFollowersAmong returns the edges pointing at the user from any of the
+// given followers, newest first, starting after the cursor.
+func (f *followRepository) GetFollowersAmong(ctx context.Context, userId string, followerIds []string, after *domain.PageCursor, limit int) ([]*domain.Follow, error) {
+	if len(followerIds) == 0 {
+		return []*domain.Follow{}, nil
+	}
+
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	followerOIds, err := toObjectIds(followerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.findPage(ctx, bson.M{
+		"followee_id": oid,
+		"follower_id": bson.M{"$in": followerOIds},
+	}, after, limit)
+}
+
+// GetFollowedAmong returns which of the given users the follower follows.
+func (f *followRepository) GetFollowedAmong(ctx context.Context, followerId string, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	followerOId, err := bson.ObjectIDFromHex(followerId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	objectIDs := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objectIDs = append(objectIDs, oid)
+	}
+
+	cursor, err := f.collection.Find(ctx, bson.M{
+		"follower_id": followerOId,
+		"followee_id": bson.M{"$in": objectIDs},
+	}, options.Find().SetProjection(bson.M{"followee_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followsDTO []mongoDTO.Follow
+	if err := cursor.All(ctx, &followsDTO); err != nil {
+		return nil, err
+	}
+
+	followed := make([]string, len(followsDTO))
+	for i, follow := range followsDTO {
+		followed[i] = follow.FolloweeId.Hex()
+	}
+
+	return followed, nil
+}
+
+// DeleteByUserId removes every edge the user is part of, keeping the
+// counters of the users on the other side correct.
+func (f *followRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	cursor, err := f.collection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"follower_id": oid},
+			{"followee_id": oid},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var followsDTO []mongoDTO.Follow
+	if err := cursor.All(ctx, &followsDTO); err != nil {
+		return err
+	}
+
+	for _, follow := range followsDTO {
+		if err := f.Unfollow(ctx, follow.FollowerId.Hex(), follow.FolloweeId.Hex()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrateEmbedded moves the followers and following arrays that used to be
+// embedded in user documents into edges, then recomputes every user's
+// counters and drops the arrays. It is safe to run again after an interruption.
+func (f *followRepository) MigrateEmbedded(ctx context.Context) (int64, int64, error) {
+	type legacyUser struct {
+		Id        bson.ObjectID `bson:"_id"`
+		Followers []string      `bson:"followers"`
+		Following []string      `bson:"following"`
+	}
+
+	cursor, err := f.userCollection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"followers": bson.M{"$exists": true}},
+			{"following": bson.M{"$exists": true}},
+		},
+	}, options.Find().SetProjection(bson.M{"followers": 1, "following": 1}))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var edges int64
+	now := time.Now()
+	for cursor.Next(ctx) {
+		var user legacyUser
+		if err := cursor.Decode(&user); err != nil {
+			return edges, 0, err
+		}
+
+		var models []mongo.WriteModel
+		addEdge := func(followerId, followeeId string) {
+			followerOId, followeeOId, err := f.edgeIds(followerId, followeeId)
+			if err != nil || followerOId == followeeOId {
+				return
+			}
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"follower_id": followerOId, "followee_id": followeeOId}).
+				SetUpdate(bson.M{"$setOnInsert": bson.M{"created_at": now}}).
+				SetUpsert(true))
+		}
+		for _, followeeId := range user.Following {
+			addEdge(user.Id.Hex(), followeeId)
+		}
+		for _, followerId := range user.Followers {
+			addEdge(followerId, user.Id.Hex())
+		}
+		if len(models) == 0 {
+			continue
+		}
+
+		result, err := f.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			return edges, 0, err
+		}
+		edges += result.UpsertedCount
+	}
+	if err := cursor.Err(); err != nil {
+		return edges, 0, err
+	}
+
+	users, err := f.recountAll(ctx)
+	return edges, users, err
+}
+
+func (f *followRepository) recountAll(ctx context.Context) (int64, error) {
+	cursor, err := f.userCollection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var users int64
+	for cursor.Next(ctx) {
+		var user struct {
+			Id bson.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			return users, err
+		}
+
+		followers, err := f.collection.CountDocuments(ctx, bson.M{"followee_id": user.Id})
+		if err != nil {
+			return users, err
+		}
+
+		following, err := f.collection.CountDocuments(ctx, bson.M{"follower_id": user.Id})
+		if err != nil {
+			return users, err
+		}
+
+		if _, err := f.userCollection.UpdateOne(ctx, bson.M{"_id": user.Id}, bson.M{
+			"$set":   bson.M{"followers_count": followers, "following_count": following},
+			"$unset": bson.M{"followers": "", "following": ""},
+		}); err != nil {
+			return users, err
+		}
+		users++
+	}
+
+	return users, cursor.Err()
+}
+
+func (f *followRepository) getPage(ctx context.Context, matchField, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return f.findPage(ctx, bson.M{matchField: oid}, after, limit)
+}
+
+func (f *followRepository) findPage(ctx context.Context, filter bson.M, after *domain.PageCursor, limit int) ([]*domain.Follow, error) {
+	if after != nil {
+		afterId, err := bson.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{"$lt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "_id": bson.M{"$lt": afterId}},
+		}
+	}
+
+	cursor, err := f.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followsDTO []mongoDTO.Follow
+	if err := cursor.All(ctx, &followsDTO); err != nil {
+		return nil, err
+	}
+
+	follows := make([]*domain.Follow, len(followsDTO))
+	for i, follow := range followsDTO {
+		follows[i] = mongoDTO.FromFollowDTOToCore(&follow)
+	}
+
+	return follows, nil
+}
+
+func (f *followRepository) getIds(ctx context.Context, matchField, idField, userId string) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	cursor, err := f.collection.Find(ctx, bson.M{matchField: oid}, options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetProjection(bson.M{idField: 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var followsDTO []mongoDTO.Follow
+	if err := cursor.All(ctx, &followsDTO); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(followsDTO))
+	for i, follow := range followsDTO {
+		if idField == "follower_id" {
+			ids[i] = follow.FollowerId.Hex()
+		} else {
+			ids[i] = follow.FolloweeId.Hex()
+		}
+	}
+
+	return ids, nil
+}
+
+func (f *followRepository) incrementCounters(ctx context.Context, followerId, followeeId bson.ObjectID, delta int) error {
+	if _, err := f.userCollection.UpdateOne(ctx, bson.M{"_id": followerId}, bson.M{
+		"$inc": bson.M{"following_count": delta},
+	}); err != nil {
+		return err
+	}
+
+	_, err := f.userCollection.UpdateOne(ctx, bson.M{"_id": followeeId}, bson.M{
+		"$inc": bson.M{"followers_count": delta},
+	})
+	return err
+}
+
+func (f *followRepository) edgeIds(followerId, followeeId string) (bson.ObjectID, bson.ObjectID, error) {
+	followerOId, err := bson.ObjectIDFromHex(followerId)
+	if err != nil {
+		return bson.ObjectID{}, bson.ObjectID{}, ErrInvalidId
+	}
+
+	followeeOId, err := bson.ObjectIDFromHex(followeeId)
+	if err != nil {
+		return bson.ObjectID{}, bson.ObjectID{}, ErrInvalidId
+	}
+
+	return followerOId, followeeOId, nil
+}
+
+func (f *followRepository) withTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := f.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
+
+func NewFollowRepository(database *mongo.Database, collectionName, userCollectionName string) FollowRepository {
+	return &followRepository{
+		collection:     database.Collection(collectionName),
+		userCollection: database.Collection(userCollectionName),
+	}
+}
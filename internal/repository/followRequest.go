@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type FollowRequestRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, request *domain.FollowRequest) error
+	GetById(ctx context.Context, id string) (*domain.FollowRequest, error)
+	Get(ctx context.Context, requesterId, targetId string) (*domain.FollowRequest, error)
+	GetByTargetId(ctx context.Context, targetId string, after *domain.PageCursor, limit int) ([]*domain.FollowRequest, error)
+	GetAllByTargetId(ctx context.Context, targetId string) ([]*domain.FollowRequest, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type followRequestRepository struct {
+	collection *mongo.Collection
+}
+
+func (f *followRequestRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := f.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "requester_id", Value: 1}, {Key: "target_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// Create stores the request. Requesting again while a request is pending is a no-op.
+func (f *followRequestRepository) Create(ctx context.Context, request *domain.FollowRequest) error {
+	requestDTO, err := mongoDTO.FromFollowRequestCoreToDTO(request)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.collection.InsertOne(ctx, requestDTO); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	}
+
+	request.Id = requestDTO.Id.Hex()
+	return nil
+}
+
+func (f *followRequestRepository) GetById(ctx context.Context, id string) (*domain.FollowRequest, error) {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return f.findOne(ctx, bson.M{"_id": oid})
+}
+
+func (f *followRequestRepository) Get(ctx context.Context, requesterId, targetId string) (*domain.FollowRequest, error) {
+	requesterOId, err := bson.ObjectIDFromHex(requesterId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	targetOId, err := bson.ObjectIDFromHex(targetId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return f.findOne(ctx, bson.M{"requester_id": requesterOId, "target_id": targetOId})
+}
+
+// GetByTargetId returns the requests sent to the user, newest first, starting after the cursor.
+func (f *followRequestRepository) GetByTargetId(ctx context.Context, targetId string, after *domain.PageCursor, limit int) ([]*domain.FollowRequest, error) {
+	oid, err := bson.ObjectIDFromHex(targetId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	filter := bson.M{"target_id": oid}
+	if after != nil {
+		afterId, err := bson.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{"$lt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "_id": bson.M{"$lt": afterId}},
+		}
+	}
+
+	return f.find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+}
+
+func (f *followRequestRepository) GetAllByTargetId(ctx context.Context, targetId string) ([]*domain.FollowRequest, error) {
+	oid, err := bson.ObjectIDFromHex(targetId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return f.find(ctx, bson.M{"target_id": oid}, options.Find().SetSort(bson.M{"created_at": 1}))
+}
+
+func (f *followRequestRepository) Delete(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := f.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (f *followRequestRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = f.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"requester_id": oid},
+			{"target_id": oid},
+		},
+	})
+	return err
+}
+
+func (f *followRequestRepository) findOne(ctx context.Context, filter bson.M) (*domain.FollowRequest, error) {
+	var requestDTO mongoDTO.FollowRequest
+	if err := f.collection.FindOne(ctx, filter).Decode(&requestDTO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return mongoDTO.FromFollowRequestDTOToCore(&requestDTO), nil
+}
+
+func (f *followRequestRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*domain.FollowRequest, error) {
+	cursor, err := f.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var requestsDTO []mongoDTO.FollowRequest
+	if err := cursor.All(ctx, &requestsDTO); err != nil {
+		return nil, err
+	}
+
+	requests := make([]*domain.FollowRequest, len(requestsDTO))
+	for i, request := range requestsDTO {
+		requests[i] = mongoDTO.FromFollowRequestDTOToCore(&request)
+	}
+
+	return requests, nil
+}
+
+func NewFollowRequestRepository(database *mongo.Database, collectionName string) FollowRequestRepository {
+	return &followRequestRepository{
+		collection: database.Collection(collectionName),
+	}
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+type ListRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, list *domain.List) error
+	GetById(ctx context.Context, id string) (*domain.List, error)
+	GetByOwnerId(ctx context.Context, ownerId string, includePrivate bool) ([]*domain.List, error)
+	GetByIds(ctx context.Context, ids []string) ([]*domain.List, error)
+	Update(ctx context.Context, list *domain.List) error
+	Delete(ctx context.Context, id string) error
+	AddMember(ctx context.Context, id, memberId string) error
+	RemoveMember(ctx context.Context, id, memberId string) error
+	RemoveMemberFromAll(ctx context.Context, memberId string) error
+	IncrementSubscribers(ctx context.Context, id string, delta int64) error
+}
+
+type listRepository struct {
+	collection *mongo.Collection
+}
+
+func (l *listRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := l.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "member_ids", Value: 1}}},
+	})
+	return err
+}
+
+func (l *listRepository) Create(ctx context.Context, list *domain.List) error {
+	listDTO, err := mongoDTO.FromListCoreToDTO(list)
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.collection.InsertOne(ctx, listDTO); err != nil {
+		return err
+	}
+
+	list.Id = listDTO.Id.Hex()
+	return nil
+}
+
+func (l *listRepository) GetById(ctx context.Context, id string) (*domain.List, error) {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	var listDTO mongoDTO.List
+	if err := l.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&listDTO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return mongoDTO.FromListDTOToCore(&listDTO), nil
+}
+
+// GetByOwnerId returns the owner's lists, newest first. Private lists are only
+// included when asked for.
+func (l *listRepository) GetByOwnerId(ctx context.Context, ownerId string, includePrivate bool) ([]*domain.List, error) {
+	oid, err := bson.ObjectIDFromHex(ownerId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	filter := bson.M{"owner_id": oid}
+	if !includePrivate {
+		filter["is_private"] = false
+	}
+
+	return l.find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+}
+
+func (l *listRepository) GetByIds(ctx context.Context, ids []string) ([]*domain.List, error) {
+	if len(ids) == 0 {
+		return []*domain.List{}, nil
+	}
+
+	oids, err := toObjectIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.find(ctx, bson.M{"_id": bson.M{"$in": oids}}, options.Find().SetSort(bson.M{"created_at": -1}))
+}
+
+func (l *listRepository) Update(ctx context.Context, list *domain.List) error {
+	oid, err := bson.ObjectIDFromHex(list.Id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := l.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{
+			"name":        list.Name,
+			"description": list.Description,
+			"is_private":  list.IsPrivate,
+			"updated_at":  list.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (l *listRepository) Delete(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := l.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// AddMember adds the member unless the list already holds ListMaxMembers
+// accounts. Adding an existing member is a no-op.
+func (l *listRepository) AddMember(ctx context.Context, id, memberId string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	memberOId, err := bson.ObjectIDFromHex(memberId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := l.collection.UpdateOne(ctx, bson.M{
+		"_id": oid,
+		fmt.Sprintf("member_ids.%d", domain.ListMaxMembers-1): bson.M{"$exists": false},
+	}, bson.M{
+		"$addToSet": bson.M{"member_ids": memberOId},
+		"$set":      bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := l.collection.CountDocuments(ctx, bson.M{"_id": oid})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrRecordNotFound
+		}
+		return ErrListFull
+	}
+
+	return nil
+}
+
+func (l *listRepository) RemoveMember(ctx context.Context, id, memberId string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	memberOId, err := bson.ObjectIDFromHex(memberId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := l.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$pull": bson.M{"member_ids": memberOId},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (l *listRepository) RemoveMemberFromAll(ctx context.Context, memberId string) error {
+	oid, err := bson.ObjectIDFromHex(memberId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = l.collection.UpdateMany(ctx, bson.M{"member_ids": oid}, bson.M{
+		"$pull": bson.M{"member_ids": oid},
+	})
+	return err
+}
+
+func (l *listRepository) IncrementSubscribers(ctx context.Context, id string, delta int64) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = l.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$inc": bson.M{"subscriber_count": delta},
+	})
+	return err
+}
+
+func (l *listRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*domain.List, error) {
+	cursor, err := l.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var listsDTO []mongoDTO.List
+	if err := cursor.All(ctx, &listsDTO); err != nil {
+		return nil, err
+	}
+
+	lists := make([]*domain.List, len(listsDTO))
+	for i, list := range listsDTO {
+		lists[i] = mongoDTO.FromListDTOToCore(&list)
+	}
+
+	return lists, nil
+}
+
+func NewListRepository(database *mongo.Database, collectionName string) ListRepository {
+	return &listRepository{
+		collection: database.Collection(collectionName),
+	}
+}
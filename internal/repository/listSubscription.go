@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ListSubscriptionRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, subscription *domain.ListSubscription) error
+	Delete(ctx context.Context, listId, userId string) error
+	IsSubscribed(ctx context.Context, listId, userId string) (bool, error)
+	GetListIdsByUserId(ctx context.Context, userId string) ([]string, error)
+	DeleteByListId(ctx context.Context, listId string) error
+}
+
+type listSubscriptionRepository struct {
+	collection *mongo.Collection
+}
+
+func (l *listSubscriptionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := l.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "list_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// Create stores the subscription. Subscribing again is a no-op that leaves
+// the subscription's Id empty, so callers can tell whether anything changed.
+func (l *listSubscriptionRepository) Create(ctx context.Context, subscription *domain.ListSubscription) error {
+	subscriptionDTO, err := mongoDTO.FromListSubscriptionCoreToDTO(subscription)
+	if err != nil {
+		return err
+	}
+
+	if _, err := l.collection.InsertOne(ctx, subscriptionDTO); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	}
+
+	subscription.Id = subscriptionDTO.Id.Hex()
+	return nil
+}
+
+func (l *listSubscriptionRepository) Delete(ctx context.Context, listId, userId string) error {
+	listOId, err := bson.ObjectIDFromHex(listId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	userOId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := l.collection.DeleteOne(ctx, bson.M{"list_id": listOId, "user_id": userOId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (l *listSubscriptionRepository) IsSubscribed(ctx context.Context, listId, userId string) (bool, error) {
+	listOId, err := bson.ObjectIDFromHex(listId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	userOId, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	count, err := l.collection.CountDocuments(ctx, bson.M{"list_id": listOId, "user_id": userOId}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (l *listSubscriptionRepository) GetListIdsByUserId(ctx context.Context, userId string) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	cursor, err := l.collection.Find(ctx, bson.M{"user_id": oid}, options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetProjection(bson.M{"list_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptionsDTO []mongoDTO.ListSubscription
+	if err := cursor.All(ctx, &subscriptionsDTO); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(subscriptionsDTO))
+	for i, subscription := range subscriptionsDTO {
+		ids[i] = subscription.ListId.Hex()
+	}
+
+	return ids, nil
+}
+
+func (l *listSubscriptionRepository) DeleteByListId(ctx context.Context, listId string) error {
+	oid, err := bson.ObjectIDFromHex(listId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = l.collection.DeleteMany(ctx, bson.M{"list_id": oid})
+	return err
+}
+
+func NewListSubscriptionRepository(database *mongo.Database, collectionName string) ListSubscriptionRepository {
+	return &listSubscriptionRepository{
+		collection: database.Collection(collectionName),
+	}
+}
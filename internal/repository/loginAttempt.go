@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"strconv"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, key string) (*domain.LoginAttempts, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Block(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	client *redis.Client
+	prefix string
+}
+
+func (l *loginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	values, err := l.client.HGetAll(ctx, l.prefix+key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := &domain.LoginAttempts{}
+	attempts.Failures, _ = strconv.Atoi(values["failures"])
+	if blockedUntil, err := strconv.ParseInt(values["blocked_until"], 10, 64); err == nil {
+		attempts.BlockedUntil = time.UnixMilli(blockedUntil)
+	}
+
+	return attempts, nil
+}
+
+// RecordFailure increments the failure counter and returns the new count.
+// The counter expires once no failure has been recorded for the window.
+func (l *loginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	pipe := l.client.TxPipeline()
+	incr := pipe.HIncrBy(ctx, l.prefix+key, "failures", 1)
+	pipe.Expire(ctx, l.prefix+key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+func (l *loginAttemptRepository) Block(ctx context.Context, key string, until time.Time) error {
+	pipe := l.client.TxPipeline()
+	pipe.HSet(ctx, l.prefix+key, "blocked_until", until.UnixMilli())
+	pipe.ExpireGT(ctx, l.prefix+key, time.Until(until))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (l *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return l.client.Del(ctx, l.prefix+key).Err()
+}
+
+func NewLoginAttemptRepository(client *redis.Client, prefix string) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		client: client,
+		prefix: prefix,
+	}
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+type MediaRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, media *domain.Media) error
+	GetById(ctx context.Context, id string) (*domain.Media, error)
+	GetByIds(ctx context.Context, ids []string) ([]*domain.Media, error)
+	GetByPostId(ctx context.Context, postId string) ([]*domain.Media, error)
+	GetByOwnerId(ctx context.Context, ownerId string) ([]*domain.Media, error)
+	GetUnattachedBefore(ctx context.Context, cutoff time.Time) ([]*domain.Media, error)
+	AddPart(ctx context.Context, id string, offset, size int64, key string) error
+	MarkReady(ctx context.Context, media *domain.Media) error
+	Attach(ctx context.Context, ids []string, postId string) error
+	Delete(ctx context.Context, id string) error
+}
+
+type mediaRepository struct {
+	collection *mongo.Collection
+}
+
+func (m *mediaRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := m.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}}},
+		{Keys: bson.D{{Key: "post_id", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	})
+	return err
+}
+
+func (m *mediaRepository) Create(ctx context.Context, media *domain.Media) error {
+	mediaDTO, err := mongoDTO.FromMediaCoreToDTO(media)
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.collection.InsertOne(ctx, mediaDTO); err != nil {
+		return err
+	}
+
+	media.Id = mediaDTO.Id.Hex()
+	return nil
+}
+
+func (m *mediaRepository) GetById(ctx context.Context, id string) (*domain.Media, error) {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	var mediaDTO mongoDTO.Media
+	if err := m.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&mediaDTO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return mongoDTO.FromMediaDTOToCore(&mediaDTO), nil
+}
+
+func (m *mediaRepository) GetByIds(ctx context.Context, ids []string) ([]*domain.Media, error) {
+	if len(ids) == 0 {
+		return []*domain.Media{}, nil
+	}
+
+	oids, err := toObjectIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+}
+
+func (m *mediaRepository) GetByPostId(ctx context.Context, postId string) ([]*domain.Media, error) {
+	oid, err := bson.ObjectIDFromHex(postId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return m.find(ctx, bson.M{"post_id": oid})
+}
+
+func (m *mediaRepository) GetByOwnerId(ctx context.Context, ownerId string) ([]*domain.Media, error) {
+	oid, err := bson.ObjectIDFromHex(ownerId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return m.find(ctx, bson.M{"owner_id": oid})
+}
+
+// GetUnattachedBefore returns media created before cutoff that no post uses,
+// including uploads that were never finished.
+func (m *mediaRepository) GetUnattachedBefore(ctx context.Context, cutoff time.Time) ([]*domain.Media, error) {
+	return m.find(ctx, bson.M{
+		"post_id":    bson.M{"$exists": false},
+		"created_at": bson.M{"$lt": cutoff},
+	})
+}
+
+// AddPart records the chunk stored under key for a pending upload. It only
+// applies when offset is where the upload left off, so concurrent or replayed
+// chunks fail with ErrUploadOffset instead of corrupting the upload.
+func (m *mediaRepository) AddPart(ctx context.Context, id string, offset, size int64, key string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := m.collection.UpdateOne(ctx, bson.M{
+		"_id":      oid,
+		"status":   domain.MediaStatusPending,
+		"received": offset,
+	}, bson.M{
+		"$inc":  bson.M{"received": size},
+		"$push": bson.M{"part_keys": key},
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := m.collection.CountDocuments(ctx, bson.M{"_id": oid})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrRecordNotFound
+		}
+		return ErrUploadOffset
+	}
+
+	return nil
+}
+
+func (m *mediaRepository) MarkReady(ctx context.Context, media *domain.Media) error {
+	oid, err := bson.ObjectIDFromHex(media.Id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	result, err := m.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{
+			"status":        domain.MediaStatusReady,
+			"url":           media.Url,
+			"thumbnail_url": media.ThumbnailUrl,
+			"width":         media.Width,
+			"height":        media.Height,
+		},
+		"$unset": bson.M{"part_keys": ""},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Attach marks the media as used by the post. Media already attached to
+// another post is left alone.
+func (m *mediaRepository) Attach(ctx context.Context, ids []string, postId string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	oids, err := toObjectIds(ids)
+	if err != nil {
+		return err
+	}
+
+	postOId, err := bson.ObjectIDFromHex(postId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = m.collection.UpdateMany(ctx, bson.M{
+		"_id":     bson.M{"$in": oids},
+		"post_id": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"post_id": postOId},
+	})
+	return err
+}
+
+func (m *mediaRepository) Delete(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = m.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
+
+func (m *mediaRepository) find(ctx context.Context, filter bson.M) ([]*domain.Media, error) {
+	cursor, err := m.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mediaDTO []mongoDTO.Media
+	if err := cursor.All(ctx, &mediaDTO); err != nil {
+		return nil, err
+	}
+
+	media := make([]*domain.Media, len(mediaDTO))
+	for i, item := range mediaDTO {
+		media[i] = mongoDTO.FromMediaDTOToCore(&item)
+	}
+
+	return media, nil
+}
+
+func NewMediaRepository(database *mongo.Database, collectionName string) MediaRepository {
+	return &mediaRepository{
+		collection: database.Collection(collectionName),
+	}
+}
@@ -13,6 +13,8 @@ import (
 type MessageRepository interface {
 	CreateMessage(ctx context.Context, message *domain.Message) error
 	GetMessagesBetween(ctx context.Context, user1, user2 string, skip, limit int64) ([]*domain.Message, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+	GetByUserId(ctx context.Context, userId string) ([]*domain.Message, error)
 }
 
 type messageRepository struct {
@@ -71,6 +73,45 @@ func (m *messageRepository) GetMessagesBetween(ctx context.Context, user1, user2
 	return results, nil
 }
 
+// GetByUserId returns every message the user sent or received, oldest first.
+func (m *messageRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.Message, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := m.collection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"sender": userId},
+			{"receiver": userId},
+		},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []mongoDTO.Message
+	if err := cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Message, len(dtos))
+	for i, dto := range dtos {
+		result[i] = mongoDTO.FromMessageDTOToCore(&dto)
+	}
+
+	return result, nil
+}
+
+// DeleteByUserId removes everything the user sent or received.
+func (m *messageRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	_, err := m.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"sender": userId},
+			{"receiver": userId},
+		},
+	})
+	return err
+}
+
 func NewMessageRepository(database *mongo.Database, collectionName string) MessageRepository {
 	return &messageRepository{
 		collection: database.Collection(collectionName),
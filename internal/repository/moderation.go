@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ModerationRepository is append-only: entries are never updated or deleted.
+type ModerationRepository interface {
+	Create(ctx context.Context, action *domain.ModerationAction) error
+	GetActions(ctx context.Context, page, limit int) ([]*domain.ModerationAction, int64, error)
+}
+
+type moderationRepository struct {
+	collection *mongo.Collection
+}
+
+func (m *moderationRepository) Create(ctx context.Context, action *domain.ModerationAction) error {
+	actionDTO, err := mongoDTO.FromModerationActionCoreToDTO(action)
+	if err != nil {
+		return err
+	}
+
+	res, err := m.collection.InsertOne(ctx, actionDTO)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		action.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (m *moderationRepository) GetActions(ctx context.Context, page, limit int) ([]*domain.ModerationAction, int64, error) {
+	total, err := m.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"_id": -1}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := m.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var actionsDTO []mongoDTO.ModerationAction
+	if err := cursor.All(ctx, &actionsDTO); err != nil {
+		return nil, 0, err
+	}
+
+	actions := make([]*domain.ModerationAction, len(actionsDTO))
+	for i, dto := range actionsDTO {
+		actions[i] = mongoDTO.FromModerationActionDTOToCore(&dto)
+	}
+
+	return actions, total, nil
+}
+
+func NewModerationRepository(database *mongo.Database, collectionName string) ModerationRepository {
+	return &moderationRepository{
+		collection: database.Collection(collectionName),
+	}
+}
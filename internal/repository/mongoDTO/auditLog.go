@@ -0,0 +1,62 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type AuditEvent struct {
+	Id        bson.ObjectID  `bson:"_id,omitempty"`
+	UserId    *bson.ObjectID `bson:"user_id,omitempty"`
+	Email     string         `bson:"email,omitempty"`
+	Event     string         `bson:"event"`
+	Outcome   string         `bson:"outcome"`
+	IP        string         `bson:"ip"`
+	UserAgent string         `bson:"user_agent"`
+	Details   string         `bson:"details,omitempty"`
+	CreatedAt time.Time      `bson:"created_at"`
+}
+
+func FromAuditEventCoreToDTO(input *domain.AuditEvent) (*AuditEvent, error) {
+	var userOID *bson.ObjectID
+	if input.UserId != "" {
+		oid, err := bson.ObjectIDFromHex(input.UserId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id: %w", err)
+		}
+		userOID = &oid
+	}
+
+	return &AuditEvent{
+		Id:        bson.NewObjectID(),
+		UserId:    userOID,
+		Email:     input.Email,
+		Event:     input.Event,
+		Outcome:   input.Outcome,
+		IP:        input.IP,
+		UserAgent: input.UserAgent,
+		Details:   input.Details,
+		CreatedAt: input.CreatedAt,
+	}, nil
+}
+
+func FromAuditEventDTOToCore(input *AuditEvent) *domain.AuditEvent {
+	var userId string
+	if input.UserId != nil {
+		userId = input.UserId.Hex()
+	}
+
+	return &domain.AuditEvent{
+		Id:        input.Id.Hex(),
+		UserId:    userId,
+		Email:     input.Email,
+		Event:     input.Event,
+		Outcome:   input.Outcome,
+		IP:        input.IP,
+		UserAgent: input.UserAgent,
+		Details:   input.Details,
+		CreatedAt: input.CreatedAt,
+	}
+}
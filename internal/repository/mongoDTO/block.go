@@ -0,0 +1,53 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type Block struct {
+	Id        bson.ObjectID `bson:"_id,omitempty"`
+	BlockerId bson.ObjectID `bson:"blocker_id"`
+	BlockedId bson.ObjectID `bson:"blocked_id"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+func FromBlockCoreToDTO(input *domain.Block) (*Block, error) {
+	blockerId, err := bson.ObjectIDFromHex(input.BlockerId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blocker id: %w", err)
+	}
+
+	blockedId, err := bson.ObjectIDFromHex(input.BlockedId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blocked id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &Block{
+		Id:        objectId,
+		BlockerId: blockerId,
+		BlockedId: blockedId,
+		CreatedAt: input.CreatedAt,
+	}, nil
+}
+
+func FromBlockDTOToCore(input *Block) *domain.Block {
+	return &domain.Block{
+		Id:        input.Id.Hex(),
+		BlockerId: input.BlockerId.Hex(),
+		BlockedId: input.BlockedId.Hex(),
+		CreatedAt: input.CreatedAt,
+	}
+}
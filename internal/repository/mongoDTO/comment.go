@@ -8,11 +8,25 @@ import (
 )
 
 type Comment struct {
-	Id        bson.ObjectID `bson:"_id,omitempty"`
-	PostId    bson.ObjectID `bson:"post_id"`
-	UserId    bson.ObjectID `bson:"user_id"`
-	Value     string        `bson:"value"`
-	CreatedAt time.Time     `bson:"created_at"`
+	Id         bson.ObjectID     `bson:"_id,omitempty"`
+	PostId     bson.ObjectID     `bson:"post_id"`
+	UserId     bson.ObjectID     `bson:"user_id"`
+	ParentId   *bson.ObjectID    `bson:"parent_id,omitempty"`
+	RootId     *bson.ObjectID    `bson:"root_id,omitempty"`
+	Depth      int               `bson:"depth"`
+	ReplyCount int64             `bson:"reply_count"`
+	Value      string            `bson:"value"`
+	Mentions   []Mention         `bson:"mentions,omitempty"`
+	Likes      []string          `bson:"likes,omitempty"`
+	Deleted    bool              `bson:"deleted,omitempty"`
+	EditedAt   *time.Time        `bson:"edited_at,omitempty"`
+	History    []CommentRevision `bson:"history,omitempty"`
+	CreatedAt  time.Time         `bson:"created_at"`
+}
+
+type CommentRevision struct {
+	Value     string    `bson:"value"`
+	CreatedAt time.Time `bson:"created_at"`
 }
 
 func FromCoreCommentToDTO(input *domain.Comment) (*Comment, error) {
@@ -36,21 +50,94 @@ func FromCoreCommentToDTO(input *domain.Comment) (*Comment, error) {
 		idOID = bson.NewObjectID()
 	}
 
+	var parentOID, rootOID *bson.ObjectID
+	if input.ParentId != "" {
+		oid, err := bson.ObjectIDFromHex(input.ParentId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent id: %w", err)
+		}
+		parentOID = &oid
+	}
+
+	if input.RootId != "" {
+		oid, err := bson.ObjectIDFromHex(input.RootId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root id: %w", err)
+		}
+		rootOID = &oid
+	}
+
 	return &Comment{
-		Id:        idOID,
-		PostId:    postDTO,
-		UserId:    userDTO,
-		Value:     input.Value,
-		CreatedAt: input.CreatedAt,
+		Id:         idOID,
+		PostId:     postDTO,
+		UserId:     userDTO,
+		ParentId:   parentOID,
+		RootId:     rootOID,
+		Depth:      input.Depth,
+		ReplyCount: input.ReplyCount,
+		Value:      input.Value,
+		Mentions:   FromMentionsCoreToDTO(input.Mentions),
+		Likes:      input.Likes,
+		Deleted:    input.Deleted,
+		EditedAt:   input.EditedAt,
+		History:    FromCommentRevisionsCoreToDTO(input.History),
+		CreatedAt:  input.CreatedAt,
 	}, nil
 }
 
 func FromCommentDTOToCore(input *Comment) *domain.Comment {
+	var parentId, rootId string
+	if input.ParentId != nil {
+		parentId = input.ParentId.Hex()
+	}
+	if input.RootId != nil {
+		rootId = input.RootId.Hex()
+	}
+
 	return &domain.Comment{
-		Id:        input.Id.Hex(),
-		PostId:    input.PostId.Hex(),
-		UserId:    input.UserId.Hex(),
+		Id:         input.Id.Hex(),
+		PostId:     input.PostId.Hex(),
+		UserId:     input.UserId.Hex(),
+		ParentId:   parentId,
+		RootId:     rootId,
+		Depth:      input.Depth,
+		ReplyCount: input.ReplyCount,
+		Value:      input.Value,
+		Mentions:   FromMentionsDTOToCore(input.Mentions),
+		Likes:      input.Likes,
+		Deleted:    input.Deleted,
+		EditedAt:   input.EditedAt,
+		History:    FromCommentRevisionsDTOToCore(input.History),
+		CreatedAt:  input.CreatedAt,
+	}
+}
+
+func FromCommentRevisionCoreToDTO(input domain.CommentRevision) CommentRevision {
+	return CommentRevision{
 		Value:     input.Value,
 		CreatedAt: input.CreatedAt,
 	}
 }
+
+func FromCommentRevisionsCoreToDTO(input []domain.CommentRevision) []CommentRevision {
+	if len(input) == 0 {
+		return nil
+	}
+
+	revisions := make([]CommentRevision, len(input))
+	for i, revision := range input {
+		revisions[i] = FromCommentRevisionCoreToDTO(revision)
+	}
+	return revisions
+}
+
+func FromCommentRevisionsDTOToCore(input []CommentRevision) []domain.CommentRevision {
+	revisions := make([]domain.CommentRevision, len(input))
+	for i, revision := range input {
+		revisions[i] = domain.CommentRevision{
+			Value:     revision.Value,
+			CreatedAt: revision.CreatedAt,
+		}
+	}
+	return revisions
+}
@@ -0,0 +1,53 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type DataExport struct {
+	Id          bson.ObjectID `bson:"_id,omitempty"`
+	UserId      bson.ObjectID `bson:"user_id"`
+	Status      string        `bson:"status"`
+	FilePath    string        `bson:"file_path,omitempty"`
+	Size        int64         `bson:"size,omitempty"`
+	Error       string        `bson:"error,omitempty"`
+	CreatedAt   time.Time     `bson:"created_at"`
+	CompletedAt *time.Time    `bson:"completed_at,omitempty"`
+	ExpiresAt   time.Time     `bson:"expires_at"`
+}
+
+func FromDataExportCoreToDTO(input *domain.DataExport) (*DataExport, error) {
+	userOID, err := bson.ObjectIDFromHex(input.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	return &DataExport{
+		Id:          bson.NewObjectID(),
+		UserId:      userOID,
+		Status:      input.Status,
+		FilePath:    input.FilePath,
+		Size:        input.Size,
+		Error:       input.Error,
+		CreatedAt:   input.CreatedAt,
+		CompletedAt: input.CompletedAt,
+		ExpiresAt:   input.ExpiresAt,
+	}, nil
+}
+
+func FromDataExportDTOToCore(input *DataExport) *domain.DataExport {
+	return &domain.DataExport{
+		Id:          input.Id.Hex(),
+		UserId:      input.UserId.Hex(),
+		Status:      input.Status,
+		FilePath:    input.FilePath,
+		Size:        input.Size,
+		Error:       input.Error,
+		CreatedAt:   input.CreatedAt,
+		CompletedAt: input.CompletedAt,
+		ExpiresAt:   input.ExpiresAt,
+	}
+}
@@ -0,0 +1,53 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type Follow struct {
+	Id         bson.ObjectID `bson:"_id,omitempty"`
+	FollowerId bson.ObjectID `bson:"follower_id"`
+	FolloweeId bson.ObjectID `bson:"followee_id"`
+	CreatedAt  time.Time     `bson:"created_at"`
+}
+
+func FromFollowCoreToDTO(input *domain.Follow) (*Follow, error) {
+	followerId, err := bson.ObjectIDFromHex(input.FollowerId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid follower id: %w", err)
+	}
+
+	followeeId, err := bson.ObjectIDFromHex(input.FolloweeId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid followee id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid follow id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &Follow{
+		Id:         objectId,
+		FollowerId: followerId,
+		FolloweeId: followeeId,
+		CreatedAt:  input.CreatedAt,
+	}, nil
+}
+
+func FromFollowDTOToCore(input *Follow) *domain.Follow {
+	return &domain.Follow{
+		Id:         input.Id.Hex(),
+		FollowerId: input.FollowerId.Hex(),
+		FolloweeId: input.FolloweeId.Hex(),
+		CreatedAt:  input.CreatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type FollowRequest struct {
+	Id          bson.ObjectID `bson:"_id,omitempty"`
+	RequesterId bson.ObjectID `bson:"requester_id"`
+	TargetId    bson.ObjectID `bson:"target_id"`
+	CreatedAt   time.Time     `bson:"created_at"`
+}
+
+func FromFollowRequestCoreToDTO(input *domain.FollowRequest) (*FollowRequest, error) {
+	requesterId, err := bson.ObjectIDFromHex(input.RequesterId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid requester id: %w", err)
+	}
+
+	targetId, err := bson.ObjectIDFromHex(input.TargetId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid follow request id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &FollowRequest{
+		Id:          objectId,
+		RequesterId: requesterId,
+		TargetId:    targetId,
+		CreatedAt:   input.CreatedAt,
+	}, nil
+}
+
+func FromFollowRequestDTOToCore(input *FollowRequest) *domain.FollowRequest {
+	return &domain.FollowRequest{
+		Id:          input.Id.Hex(),
+		RequesterId: input.RequesterId.Hex(),
+		TargetId:    input.TargetId.Hex(),
+		CreatedAt:   input.CreatedAt,
+	}
+}
@@ -0,0 +1,120 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type List struct {
+	Id              bson.ObjectID   `bson:"_id,omitempty"`
+	OwnerId         bson.ObjectID   `bson:"owner_id"`
+	Name            string          `bson:"name"`
+	Description     string          `bson:"description"`
+	IsPrivate       bool            `bson:"is_private"`
+	MemberIds       []bson.ObjectID `bson:"member_ids"`
+	SubscriberCount int64           `bson:"subscriber_count"`
+	CreatedAt       time.Time       `bson:"created_at"`
+	UpdatedAt       time.Time       `bson:"updated_at"`
+}
+
+type ListSubscription struct {
+	Id        bson.ObjectID `bson:"_id,omitempty"`
+	ListId    bson.ObjectID `bson:"list_id"`
+	UserId    bson.ObjectID `bson:"user_id"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+func FromListCoreToDTO(input *domain.List) (*List, error) {
+	ownerId, err := bson.ObjectIDFromHex(input.OwnerId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner id: %w", err)
+	}
+
+	memberIds := make([]bson.ObjectID, len(input.MemberIds))
+	for i, id := range input.MemberIds {
+		if memberIds[i], err = bson.ObjectIDFromHex(id); err != nil {
+			return nil, fmt.Errorf("invalid member id: %w", err)
+		}
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &List{
+		Id:              objectId,
+		OwnerId:         ownerId,
+		Name:            input.Name,
+		Description:     input.Description,
+		IsPrivate:       input.IsPrivate,
+		MemberIds:       memberIds,
+		SubscriberCount: input.SubscriberCount,
+		CreatedAt:       input.CreatedAt,
+		UpdatedAt:       input.UpdatedAt,
+	}, nil
+}
+
+func FromListDTOToCore(input *List) *domain.List {
+	memberIds := make([]string, len(input.MemberIds))
+	for i, id := range input.MemberIds {
+		memberIds[i] = id.Hex()
+	}
+
+	return &domain.List{
+		Id:              input.Id.Hex(),
+		OwnerId:         input.OwnerId.Hex(),
+		Name:            input.Name,
+		Description:     input.Description,
+		IsPrivate:       input.IsPrivate,
+		MemberIds:       memberIds,
+		SubscriberCount: input.SubscriberCount,
+		CreatedAt:       input.CreatedAt,
+		UpdatedAt:       input.UpdatedAt,
+	}
+}
+
+func FromListSubscriptionCoreToDTO(input *domain.ListSubscription) (*ListSubscription, error) {
+	listId, err := bson.ObjectIDFromHex(input.ListId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list id: %w", err)
+	}
+
+	userId, err := bson.ObjectIDFromHex(input.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list subscription id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &ListSubscription{
+		Id:        objectId,
+		ListId:    listId,
+		UserId:    userId,
+		CreatedAt: input.CreatedAt,
+	}, nil
+}
+
+func FromListSubscriptionDTOToCore(input *ListSubscription) *domain.ListSubscription {
+	return &domain.ListSubscription{
+		Id:        input.Id.Hex(),
+		ListId:    input.ListId.Hex(),
+		UserId:    input.UserId.Hex(),
+		CreatedAt: input.CreatedAt,
+	}
+}
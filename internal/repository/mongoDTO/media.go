@@ -0,0 +1,126 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type Media struct {
+	Id           bson.ObjectID  `bson:"_id,omitempty"`
+	OwnerId      bson.ObjectID  `bson:"owner_id"`
+	PostId       *bson.ObjectID `bson:"post_id,omitempty"`
+	Status       string         `bson:"status"`
+	Url          string         `bson:"url,omitempty"`
+	ThumbnailUrl string         `bson:"thumbnail_url,omitempty"`
+	Width        int            `bson:"width,omitempty"`
+	Height       int            `bson:"height,omitempty"`
+	Size         int64          `bson:"size"`
+	Received     int64          `bson:"received"`
+	Parts        []string       `bson:"part_keys,omitempty"`
+	CreatedAt    time.Time      `bson:"created_at"`
+}
+
+type Attachment struct {
+	MediaId      string `bson:"media_id"`
+	Url          string `bson:"url"`
+	ThumbnailUrl string `bson:"thumbnail_url"`
+	Width        int    `bson:"width"`
+	Height       int    `bson:"height"`
+}
+
+func FromMediaCoreToDTO(input *domain.Media) (*Media, error) {
+	ownerId, err := bson.ObjectIDFromHex(input.OwnerId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner id: %w", err)
+	}
+
+	var postId *bson.ObjectID
+	if input.PostId != "" {
+		oid, err := bson.ObjectIDFromHex(input.PostId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid post id: %w", err)
+		}
+		postId = &oid
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid media id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &Media{
+		Id:           objectId,
+		OwnerId:      ownerId,
+		PostId:       postId,
+		Status:       input.Status,
+		Url:          input.Url,
+		ThumbnailUrl: input.ThumbnailUrl,
+		Width:        input.Width,
+		Height:       input.Height,
+		Size:         input.Size,
+		Received:     input.Received,
+		Parts:        input.Parts,
+		CreatedAt:    input.CreatedAt,
+	}, nil
+}
+
+func FromMediaDTOToCore(input *Media) *domain.Media {
+	media := &domain.Media{
+		Id:           input.Id.Hex(),
+		OwnerId:      input.OwnerId.Hex(),
+		Status:       input.Status,
+		Url:          input.Url,
+		ThumbnailUrl: input.ThumbnailUrl,
+		Width:        input.Width,
+		Height:       input.Height,
+		Size:         input.Size,
+		Received:     input.Received,
+		Parts:        input.Parts,
+		CreatedAt:    input.CreatedAt,
+	}
+	if input.PostId != nil {
+		media.PostId = input.PostId.Hex()
+	}
+	return media
+}
+
+func FromAttachmentsCoreToDTO(input []domain.Attachment) []Attachment {
+	if len(input) == 0 {
+		return nil
+	}
+
+	attachments := make([]Attachment, len(input))
+	for i, attachment := range input {
+		attachments[i] = Attachment{
+			MediaId:      attachment.MediaId,
+			Url:          attachment.Url,
+			ThumbnailUrl: attachment.ThumbnailUrl,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+		}
+	}
+
+	return attachments
+}
+
+func FromAttachmentsDTOToCore(input []Attachment) []domain.Attachment {
+	attachments := make([]domain.Attachment, len(input))
+	for i, attachment := range input {
+		attachments[i] = domain.Attachment{
+			MediaId:      attachment.MediaId,
+			Url:          attachment.Url,
+			ThumbnailUrl: attachment.ThumbnailUrl,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+		}
+	}
+
+	return attachments
+}
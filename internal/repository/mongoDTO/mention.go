@@ -0,0 +1,42 @@
+package mongoDTO
+
+import "github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+
+type Mention struct {
+	UserId string `bson:"user_id"`
+	Handle string `bson:"handle"`
+	Start  int    `bson:"start"`
+	End    int    `bson:"end"`
+}
+
+func FromMentionsCoreToDTO(input []domain.Mention) []Mention {
+	if len(input) == 0 {
+		return nil
+	}
+
+	mentions := make([]Mention, len(input))
+	for i, mention := range input {
+		mentions[i] = Mention{
+			UserId: mention.UserId,
+			Handle: mention.Handle,
+			Start:  mention.Start,
+			End:    mention.End,
+		}
+	}
+
+	return mentions
+}
+
+func FromMentionsDTOToCore(input []Mention) []domain.Mention {
+	mentions := make([]domain.Mention, len(input))
+	for i, mention := range input {
+		mentions[i] = domain.Mention{
+			UserId: mention.UserId,
+			Handle: mention.Handle,
+			Start:  mention.Start,
+			End:    mention.End,
+		}
+	}
+
+	return mentions
+}
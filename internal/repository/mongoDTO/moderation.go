@@ -0,0 +1,63 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type ModerationAction struct {
+	Id            bson.ObjectID `bson:"_id,omitempty"`
+	ActorId       bson.ObjectID `bson:"actor_id"`
+	Action        string        `bson:"action"`
+	TargetId      bson.ObjectID `bson:"target_id"`
+	TargetOwnerId bson.ObjectID `bson:"target_owner_id"`
+	Reason        string        `bson:"reason"`
+	Details       string        `bson:"details"`
+	CreatedAt     time.Time     `bson:"created_at"`
+}
+
+func FromModerationActionCoreToDTO(input *domain.ModerationAction) (*ModerationAction, error) {
+	actorOID, err := bson.ObjectIDFromHex(input.ActorId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actor id: %w", err)
+	}
+
+	targetOID, err := bson.ObjectIDFromHex(input.TargetId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target id: %w", err)
+	}
+
+	var ownerOID bson.ObjectID
+	if input.TargetOwnerId != "" {
+		ownerOID, err = bson.ObjectIDFromHex(input.TargetOwnerId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target owner id: %w", err)
+		}
+	}
+
+	return &ModerationAction{
+		Id:            bson.NewObjectID(),
+		ActorId:       actorOID,
+		Action:        input.Action,
+		TargetId:      targetOID,
+		TargetOwnerId: ownerOID,
+		Reason:        input.Reason,
+		Details:       input.Details,
+		CreatedAt:     input.CreatedAt,
+	}, nil
+}
+
+func FromModerationActionDTOToCore(input *ModerationAction) *domain.ModerationAction {
+	return &domain.ModerationAction{
+		Id:            input.Id.Hex(),
+		ActorId:       input.ActorId.Hex(),
+		Action:        input.Action,
+		TargetId:      input.TargetId.Hex(),
+		TargetOwnerId: input.TargetOwnerId.Hex(),
+		Reason:        input.Reason,
+		Details:       input.Details,
+		CreatedAt:     input.CreatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type Mute struct {
+	Id        bson.ObjectID `bson:"_id,omitempty"`
+	MuterId   bson.ObjectID `bson:"muter_id"`
+	MutedId   bson.ObjectID `bson:"muted_id"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+func FromMuteCoreToDTO(input *domain.Mute) (*Mute, error) {
+	muterId, err := bson.ObjectIDFromHex(input.MuterId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid muter id: %w", err)
+	}
+
+	mutedId, err := bson.ObjectIDFromHex(input.MutedId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid muted id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mute id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &Mute{
+		Id:        objectId,
+		MuterId:   muterId,
+		MutedId:   mutedId,
+		CreatedAt: input.CreatedAt,
+	}, nil
+}
+
+func FromMuteDTOToCore(input *Mute) *domain.Mute {
+	return &domain.Mute{
+		Id:        input.Id.Hex(),
+		MuterId:   input.MuterId.Hex(),
+		MutedId:   input.MutedId.Hex(),
+		CreatedAt: input.CreatedAt,
+	}
+}
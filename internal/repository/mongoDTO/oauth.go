@@ -0,0 +1,140 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type OAuthClient struct {
+	Id               bson.ObjectID `bson:"_id,omitempty"`
+	OwnerId          bson.ObjectID `bson:"owner_id"`
+	Name             string        `bson:"name"`
+	ClientSecretHash string        `bson:"client_secret_hash"`
+	Public           bool          `bson:"public"`
+	RedirectURIs     []string      `bson:"redirect_uris"`
+	Scopes           []string      `bson:"scopes"`
+	CreatedAt        time.Time     `bson:"created_at"`
+}
+
+type OAuthGrant struct {
+	Id        bson.ObjectID `bson:"_id,omitempty"`
+	UserId    bson.ObjectID `bson:"user_id"`
+	ClientId  bson.ObjectID `bson:"client_id"`
+	Scopes    []string      `bson:"scopes"`
+	CreatedAt time.Time     `bson:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+type OAuthToken struct {
+	Id               bson.ObjectID `bson:"_id,omitempty"`
+	ClientId         bson.ObjectID `bson:"client_id"`
+	UserId           bson.ObjectID `bson:"user_id"`
+	Scopes           []string      `bson:"scopes"`
+	AccessTokenHash  string        `bson:"access_token_hash"`
+	RefreshTokenHash string        `bson:"refresh_token_hash"`
+	AccessExpiresAt  time.Time     `bson:"access_expires_at"`
+	RefreshExpiresAt time.Time     `bson:"refresh_expires_at"`
+	CreatedAt        time.Time     `bson:"created_at"`
+}
+
+func FromOAuthClientCoreToDTO(input *domain.OAuthClient) (*OAuthClient, error) {
+	ownerOID, err := bson.ObjectIDFromHex(input.OwnerId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &OAuthClient{
+		Id:               objectId,
+		OwnerId:          ownerOID,
+		Name:             input.Name,
+		ClientSecretHash: input.ClientSecretHash,
+		Public:           input.Public,
+		RedirectURIs:     input.RedirectURIs,
+		Scopes:           input.Scopes,
+		CreatedAt:        input.CreatedAt,
+	}, nil
+}
+
+func FromOAuthClientDTOToCore(input *OAuthClient) *domain.OAuthClient {
+	return &domain.OAuthClient{
+		Id:               input.Id.Hex(),
+		OwnerId:          input.OwnerId.Hex(),
+		Name:             input.Name,
+		ClientSecretHash: input.ClientSecretHash,
+		Public:           input.Public,
+		RedirectURIs:     input.RedirectURIs,
+		Scopes:           input.Scopes,
+		CreatedAt:        input.CreatedAt,
+	}
+}
+
+func FromOAuthGrantDTOToCore(input *OAuthGrant) *domain.OAuthGrant {
+	return &domain.OAuthGrant{
+		Id:        input.Id.Hex(),
+		UserId:    input.UserId.Hex(),
+		ClientId:  input.ClientId.Hex(),
+		Scopes:    input.Scopes,
+		CreatedAt: input.CreatedAt,
+		UpdatedAt: input.UpdatedAt,
+	}
+}
+
+func FromOAuthTokenCoreToDTO(input *domain.OAuthToken) (*OAuthToken, error) {
+	clientOID, err := bson.ObjectIDFromHex(input.ClientId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client id: %w", err)
+	}
+
+	userOID, err := bson.ObjectIDFromHex(input.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &OAuthToken{
+		Id:               objectId,
+		ClientId:         clientOID,
+		UserId:           userOID,
+		Scopes:           input.Scopes,
+		AccessTokenHash:  input.AccessTokenHash,
+		RefreshTokenHash: input.RefreshTokenHash,
+		AccessExpiresAt:  input.AccessExpiresAt,
+		RefreshExpiresAt: input.RefreshExpiresAt,
+		CreatedAt:        input.CreatedAt,
+	}, nil
+}
+
+func FromOAuthTokenDTOToCore(input *OAuthToken) *domain.OAuthToken {
+	return &domain.OAuthToken{
+		Id:               input.Id.Hex(),
+		ClientId:         input.ClientId.Hex(),
+		UserId:           input.UserId.Hex(),
+		Scopes:           input.Scopes,
+		AccessTokenHash:  input.AccessTokenHash,
+		RefreshTokenHash: input.RefreshTokenHash,
+		AccessExpiresAt:  input.AccessExpiresAt,
+		RefreshExpiresAt: input.RefreshExpiresAt,
+		CreatedAt:        input.CreatedAt,
+	}
+}
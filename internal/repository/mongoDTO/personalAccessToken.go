@@ -0,0 +1,60 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type PersonalAccessToken struct {
+	Id         bson.ObjectID `bson:"_id,omitempty"`
+	UserId     bson.ObjectID `bson:"user_id"`
+	Name       string        `bson:"name"`
+	TokenHash  string        `bson:"token_hash"`
+	Scopes     []string      `bson:"scopes"`
+	ExpiresAt  *time.Time    `bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time    `bson:"last_used_at,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at"`
+}
+
+func FromPersonalAccessTokenCoreToDTO(input *domain.PersonalAccessToken) (*PersonalAccessToken, error) {
+	userOID, err := bson.ObjectIDFromHex(input.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid personal access token id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &PersonalAccessToken{
+		Id:         objectId,
+		UserId:     userOID,
+		Name:       input.Name,
+		TokenHash:  input.TokenHash,
+		Scopes:     input.Scopes,
+		ExpiresAt:  input.ExpiresAt,
+		LastUsedAt: input.LastUsedAt,
+		CreatedAt:  input.CreatedAt,
+	}, nil
+}
+
+func FromPersonalAccessTokenDTOToCore(input *PersonalAccessToken) *domain.PersonalAccessToken {
+	return &domain.PersonalAccessToken{
+		Id:         input.Id.Hex(),
+		UserId:     input.UserId.Hex(),
+		Name:       input.Name,
+		TokenHash:  input.TokenHash,
+		Scopes:     input.Scopes,
+		ExpiresAt:  input.ExpiresAt,
+		LastUsedAt: input.LastUsedAt,
+		CreatedAt:  input.CreatedAt,
+	}
+}
@@ -8,16 +8,22 @@ import (
 )
 
 type Post struct {
-	Id           bson.ObjectID `bson:"_id,omitempty"`
-	Creator      string        `bson:"creator"`
-	Title        string        `bson:"title"`
-	Message      string        `bson:"message"`
-	FirstName    string        `bson:"first_name"`
-	LastName     string        `bson:"last_name"`
-	SelectedFile string        `bson:"selected_file"`
-	Likes        []string      `bson:"likes"`
-	Comments     []string      `bson:"comments"`
-	CreatedAt    time.Time     `bson:"created_at"`
+	Id      bson.ObjectID `bson:"_id,omitempty"`
+	Creator string        `bson:"creator"`
+	// Kind is left out for plain posts, which is all posts written before reposts.
+	Kind        string         `bson:"kind,omitempty"`
+	OriginalId  *bson.ObjectID `bson:"original_id,omitempty"`
+	Title       string         `bson:"title"`
+	Message     string         `bson:"message"`
+	FirstName   string         `bson:"first_name"`
+	LastName    string         `bson:"last_name"`
+	Media       []Attachment   `bson:"media,omitempty"`
+	Likes       []string       `bson:"likes"`
+	Comments    []string       `bson:"comments"`
+	Mentions    []Mention      `bson:"mentions,omitempty"`
+	RepostCount int64          `bson:"repost_count"`
+	QuoteCount  int64          `bson:"quote_count"`
+	CreatedAt   time.Time      `bson:"created_at"`
 }
 
 func FromPostCoreToDTO(input *domain.Post) (*Post, error) {
@@ -33,31 +39,65 @@ func FromPostCoreToDTO(input *domain.Post) (*Post, error) {
 		objectId = bson.NewObjectID()
 	}
 
+	var originalId *bson.ObjectID
+	if input.OriginalId != "" {
+		oid, err := bson.ObjectIDFromHex(input.OriginalId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid original post id: %w", err)
+		}
+		originalId = &oid
+	}
+
+	kind := input.Kind
+	if kind == domain.PostKindPost {
+		kind = ""
+	}
+
 	return &Post{
-		Id:           objectId,
-		Creator:      input.Creator,
-		Title:        input.Title,
-		Message:      input.Message,
-		FirstName:    input.FirstName,
-		LastName:     input.LastName,
-		SelectedFile: input.SelectedFile,
-		Likes:        input.Likes,
-		Comments:     input.Comments,
-		CreatedAt:    input.CreatedAt,
+		Id:          objectId,
+		Creator:     input.Creator,
+		Kind:        kind,
+		OriginalId:  originalId,
+		Title:       input.Title,
+		Message:     input.Message,
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		Media:       FromAttachmentsCoreToDTO(input.Media),
+		Likes:       input.Likes,
+		Comments:    input.Comments,
+		Mentions:    FromMentionsCoreToDTO(input.Mentions),
+		RepostCount: input.RepostCount,
+		QuoteCount:  input.QuoteCount,
+		CreatedAt:   input.CreatedAt,
 	}, nil
 }
 
 func FromPostDTOToCore(input *Post) *domain.Post {
+	kind := input.Kind
+	if kind == "" {
+		kind = domain.PostKindPost
+	}
+
+	var originalId string
+	if input.OriginalId != nil {
+		originalId = input.OriginalId.Hex()
+	}
+
 	return &domain.Post{
-		Id:           input.Id.Hex(),
-		Creator:      input.Creator,
-		Title:        input.Title,
-		Message:      input.Message,
-		FirstName:    input.FirstName,
-		LastName:     input.LastName,
-		SelectedFile: input.SelectedFile,
-		Likes:        input.Likes,
-		Comments:     input.Comments,
-		CreatedAt:    input.CreatedAt,
+		Id:          input.Id.Hex(),
+		Creator:     input.Creator,
+		Kind:        kind,
+		OriginalId:  originalId,
+		Title:       input.Title,
+		Message:     input.Message,
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		Media:       FromAttachmentsDTOToCore(input.Media),
+		Likes:       input.Likes,
+		Comments:    input.Comments,
+		Mentions:    FromMentionsDTOToCore(input.Mentions),
+		RepostCount: input.RepostCount,
+		QuoteCount:  input.QuoteCount,
+		CreatedAt:   input.CreatedAt,
 	}
 }
@@ -0,0 +1,27 @@
+package mongoDTO
+
+import (
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type Suggestion struct {
+	User        User            `bson:"user"`
+	Score       int64           `bson:"score"`
+	MutualCount int64           `bson:"mutual_count"`
+	MutualIds   []bson.ObjectID `bson:"mutual_ids"`
+}
+
+func FromSuggestionDTOToCore(input *Suggestion) *domain.Suggestion {
+	mutualIds := make([]string, len(input.MutualIds))
+	for i, id := range input.MutualIds {
+		mutualIds[i] = id.Hex()
+	}
+
+	return &domain.Suggestion{
+		User:        FromUserDTOToCore(&input.User),
+		Score:       input.Score,
+		MutualCount: input.MutualCount,
+		MutualIds:   mutualIds,
+	}
+}
@@ -4,18 +4,27 @@ import (
 	"fmt"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"strings"
+	"time"
 )
 
 type User struct {
-	Id        bson.ObjectID `bson:"_id,omitempty"`
-	FirstName string        `bson:"first_name"`
-	LastName  string        `bson:"last_name"`
-	Email     string        `bson:"email"`
-	Password  string        `bson:"password"`
-	ImageUrl  string        `bson:"image_url"`
-	Bio       string        `bson:"bio"`
-	Followers []string      `bson:"followers"`
-	Following []string      `bson:"following"`
+	Id              bson.ObjectID `bson:"_id,omitempty"`
+	FirstName       string        `bson:"first_name"`
+	LastName        string        `bson:"last_name"`
+	Email           string        `bson:"email"`
+	Handle          string        `bson:"handle,omitempty"`
+	HandleLower     string        `bson:"handle_lower,omitempty"`
+	Password        string        `bson:"password"`
+	ImageUrl        string        `bson:"image_url"`
+	BannerUrl       string        `bson:"banner_url"`
+	Bio             string        `bson:"bio"`
+	Role            string        `bson:"role"`
+	IsPrivate       bool          `bson:"is_private"`
+	FollowersCount  int64         `bson:"followers_count"`
+	FollowingCount  int64         `bson:"following_count"`
+	DeactivatedAt   *time.Time    `bson:"deactivated_at,omitempty"`
+	HandleChangedAt *time.Time    `bson:"handle_changed_at,omitempty"`
 }
 
 func FromUserCoreToDTO(input *domain.User) (*User, error) {
@@ -32,28 +41,41 @@ func FromUserCoreToDTO(input *domain.User) (*User, error) {
 	}
 
 	return &User{
-		Id:        objectId,
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		Email:     input.Email,
-		Password:  input.Password,
-		ImageUrl:  input.ImageUrl,
-		Bio:       input.Bio,
-		Followers: input.Followers,
-		Following: input.Following,
+		Id:              objectId,
+		FirstName:       input.FirstName,
+		LastName:        input.LastName,
+		Email:           input.Email,
+		Handle:          input.Handle,
+		HandleLower:     strings.ToLower(input.Handle),
+		Password:        input.Password,
+		ImageUrl:        input.ImageUrl,
+		BannerUrl:       input.BannerUrl,
+		Bio:             input.Bio,
+		Role:            input.Role,
+		IsPrivate:       input.IsPrivate,
+		FollowersCount:  input.FollowersCount,
+		FollowingCount:  input.FollowingCount,
+		DeactivatedAt:   input.DeactivatedAt,
+		HandleChangedAt: input.HandleChangedAt,
 	}, nil
 }
 
 func FromUserDTOToCore(input *User) *domain.User {
 	return &domain.User{
-		Id:        input.Id.Hex(),
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		Email:     input.Email,
-		Password:  input.Password,
-		ImageUrl:  input.ImageUrl,
-		Bio:       input.Bio,
-		Followers: input.Followers,
-		Following: input.Following,
+		Id:              input.Id.Hex(),
+		FirstName:       input.FirstName,
+		LastName:        input.LastName,
+		Email:           input.Email,
+		Handle:          input.Handle,
+		Password:        input.Password,
+		ImageUrl:        input.ImageUrl,
+		BannerUrl:       input.BannerUrl,
+		Bio:             input.Bio,
+		Role:            input.Role,
+		IsPrivate:       input.IsPrivate,
+		FollowersCount:  input.FollowersCount,
+		FollowingCount:  input.FollowingCount,
+		DeactivatedAt:   input.DeactivatedAt,
+		HandleChangedAt: input.HandleChangedAt,
 	}
 }
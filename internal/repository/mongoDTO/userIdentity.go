@@ -0,0 +1,54 @@
+package mongoDTO
+
+import (
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"time"
+)
+
+type UserIdentity struct {
+	Id        bson.ObjectID `bson:"_id,omitempty"`
+	UserId    bson.ObjectID `bson:"user_id"`
+	Provider  string        `bson:"provider"`
+	Subject   string        `bson:"subject"`
+	Email     string        `bson:"email"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+func FromUserIdentityCoreToDTO(input *domain.UserIdentity) (*UserIdentity, error) {
+	userOID, err := bson.ObjectIDFromHex(input.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	var objectId bson.ObjectID
+	if input.Id != "" {
+		objectId, err = bson.ObjectIDFromHex(input.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user identity id: %w", err)
+		}
+	} else {
+		objectId = bson.NewObjectID()
+	}
+
+	return &UserIdentity{
+		Id:        objectId,
+		UserId:    userOID,
+		Provider:  input.Provider,
+		Subject:   input.Subject,
+		Email:     input.Email,
+		CreatedAt: input.CreatedAt,
+	}, nil
+}
+
+func FromUserIdentityDTOToCore(input *UserIdentity) *domain.UserIdentity {
+	return &domain.UserIdentity{
+		Id:        input.Id.Hex(),
+		UserId:    input.UserId.Hex(),
+		Provider:  input.Provider,
+		Subject:   input.Subject,
+		Email:     input.Email,
+		CreatedAt: input.CreatedAt,
+	}
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type MuteRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, mute *domain.Mute) error
+	Delete(ctx context.Context, muterId, mutedId string) error
+	IsMuted(ctx context.Context, muterId, mutedId string) (bool, error)
+	GetMutedIds(ctx context.Context, muterId string) ([]string, error)
+	GetByMuterId(ctx context.Context, muterId string, after *domain.PageCursor, limit int) ([]*domain.Mute, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type muteRepository struct {
+	collection *mongo.Collection
+}
+
+func (m *muteRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := m.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "muter_id", Value: 1}, {Key: "muted_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "muted_id", Value: 1}}},
+		{Keys: bson.D{{Key: "muter_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// Create stores the mute. Muting someone already muted is a no-op.
+func (m *muteRepository) Create(ctx context.Context, mute *domain.Mute) error {
+	muteDTO, err := mongoDTO.FromMuteCoreToDTO(mute)
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.collection.InsertOne(ctx, muteDTO); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	}
+
+	mute.Id = muteDTO.Id.Hex()
+	return nil
+}
+
+// Delete removes the mute. Unmuting someone not muted is a no-op.
+func (m *muteRepository) Delete(ctx context.Context, muterId, mutedId string) error {
+	muterOId, err := bson.ObjectIDFromHex(muterId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	mutedOId, err := bson.ObjectIDFromHex(mutedId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = m.collection.DeleteOne(ctx, bson.M{"muter_id": muterOId, "muted_id": mutedOId})
+	return err
+}
+
+func (m *muteRepository) IsMuted(ctx context.Context, muterId, mutedId string) (bool, error) {
+	muterOId, err := bson.ObjectIDFromHex(muterId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	mutedOId, err := bson.ObjectIDFromHex(mutedId)
+	if err != nil {
+		return false, ErrInvalidId
+	}
+
+	count, err := m.collection.CountDocuments(ctx, bson.M{"muter_id": muterOId, "muted_id": mutedOId}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (m *muteRepository) GetMutedIds(ctx context.Context, muterId string) ([]string, error) {
+	oid, err := bson.ObjectIDFromHex(muterId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	mutes, err := m.find(ctx, bson.M{"muter_id": oid}, options.Find())
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(mutes))
+	for i, mute := range mutes {
+		ids[i] = mute.MutedId
+	}
+
+	return ids, nil
+}
+
+// GetByMuterId returns the users the muter muted, newest first, starting after the cursor.
+func (m *muteRepository) GetByMuterId(ctx context.Context, muterId string, after *domain.PageCursor, limit int) ([]*domain.Mute, error) {
+	oid, err := bson.ObjectIDFromHex(muterId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	filter := bson.M{"muter_id": oid}
+	if after != nil {
+		afterId, err := bson.ObjectIDFromHex(after.Id)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{"$lt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "_id": bson.M{"$lt": afterId}},
+		}
+	}
+
+	return m.find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+}
+
+func (m *muteRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = m.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"muter_id": oid},
+			{"muted_id": oid},
+		},
+	})
+	return err
+}
+
+func (m *muteRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptionsBuilder) ([]*domain.Mute, error) {
+	cursor, err := m.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mutesDTO []mongoDTO.Mute
+	if err := cursor.All(ctx, &mutesDTO); err != nil {
+		return nil, err
+	}
+
+	mutes := make([]*domain.Mute, len(mutesDTO))
+	for i, mute := range mutesDTO {
+		mutes[i] = mongoDTO.FromMuteDTOToCore(&mute)
+	}
+
+	return mutes, nil
+}
+
+func NewMuteRepository(database *mongo.Database, collectionName string) MuteRepository {
+	return &muteRepository{
+		collection: database.Collection(collectionName),
+	}
+}
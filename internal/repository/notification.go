@@ -14,6 +14,7 @@ type NotificationRepository interface {
 	Create(ctx context.Context, notification *domain.Notification) error
 	MarkAsRead(ctx context.Context, userId string) error
 	GetByUserId(ctx context.Context, userId string) ([]*domain.Notification, error)
+	DeleteByUserId(ctx context.Context, userId string) error
 }
 
 type notificationRepository struct {
@@ -77,6 +78,22 @@ func (n *notificationRepository) GetByUserId(ctx context.Context, userId string)
 	return notifications, nil
 }
 
+// DeleteByUserId removes everything the user sent or received.
+func (n *notificationRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = n.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"sender_id": oid},
+			{"receiver_id": oid},
+		},
+	})
+	return err
+}
+
 func NewNotificationRepository(database *mongo.Database, collectionName string) NotificationRepository {
 	return &notificationRepository{
 		collection: database.Collection(collectionName),
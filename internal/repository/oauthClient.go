@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *domain.OAuthClient) error
+	GetById(ctx context.Context, id string) (*domain.OAuthClient, error)
+	GetByIds(ctx context.Context, ids []string) ([]*domain.OAuthClient, error)
+	GetByOwnerId(ctx context.Context, ownerId string) ([]*domain.OAuthClient, error)
+	Delete(ctx context.Context, id, ownerId string) error
+}
+
+type oauthClientRepository struct {
+	collection *mongo.Collection
+}
+
+func (o *oauthClientRepository) Create(ctx context.Context, client *domain.OAuthClient) error {
+	clientDTO, err := mongoDTO.FromOAuthClientCoreToDTO(client)
+	if err != nil {
+		return err
+	}
+
+	res, err := o.collection.InsertOne(ctx, clientDTO)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		client.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (o *oauthClientRepository) GetById(ctx context.Context, id string) (*domain.OAuthClient, error) {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	var clientDTO mongoDTO.OAuthClient
+	if err := o.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&clientDTO); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mongoDTO.FromOAuthClientDTOToCore(&clientDTO), nil
+}
+
+func (o *oauthClientRepository) GetByIds(ctx context.Context, ids []string) ([]*domain.OAuthClient, error) {
+	if len(ids) == 0 {
+		return []*domain.OAuthClient{}, nil
+	}
+
+	objectIDs := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objectIDs = append(objectIDs, oid)
+	}
+
+	return o.find(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+}
+
+func (o *oauthClientRepository) GetByOwnerId(ctx context.Context, ownerId string) ([]*domain.OAuthClient, error) {
+	oid, err := bson.ObjectIDFromHex(ownerId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner id: %w", err)
+	}
+
+	return o.find(ctx, bson.M{"owner_id": oid})
+}
+
+func (o *oauthClientRepository) Delete(ctx context.Context, id, ownerId string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	ownerOID, err := bson.ObjectIDFromHex(ownerId)
+	if err != nil {
+		return fmt.Errorf("invalid owner id: %w", err)
+	}
+
+	result, err := o.collection.DeleteOne(ctx, bson.M{"_id": oid, "owner_id": ownerOID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (o *oauthClientRepository) find(ctx context.Context, filter bson.M) ([]*domain.OAuthClient, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cursor, err := o.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clientsDTO []mongoDTO.OAuthClient
+	if err := cursor.All(ctx, &clientsDTO); err != nil {
+		return nil, err
+	}
+
+	clients := make([]*domain.OAuthClient, len(clientsDTO))
+	for i, dto := range clientsDTO {
+		clients[i] = mongoDTO.FromOAuthClientDTOToCore(&dto)
+	}
+
+	return clients, nil
+}
+
+func NewOAuthClientRepository(database *mongo.Database, collectionName string) OAuthClientRepository {
+	return &oauthClientRepository{
+		collection: database.Collection(collectionName),
+	}
+}
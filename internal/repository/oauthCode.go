@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"time"
+)
+
+type OAuthCodeRepository interface {
+	Save(ctx context.Context, codeHash string, code *domain.OAuthAuthorizationCode, ttl time.Duration) error
+	Take(ctx context.Context, codeHash string) (*domain.OAuthAuthorizationCode, error)
+}
+
+type oauthCodeRepository struct {
+	client *redis.Client
+	prefix string
+}
+
+func (o *oauthCodeRepository) Save(ctx context.Context, codeHash string, code *domain.OAuthAuthorizationCode, ttl time.Duration) error {
+	payload, err := json.Marshal(code)
+	if err != nil {
+		return err
+	}
+	return o.client.Set(ctx, o.prefix+codeHash, payload, ttl).Err()
+}
+
+// Take returns the authorization code and deletes it so it can only be redeemed once.
+func (o *oauthCodeRepository) Take(ctx context.Context, codeHash string) (*domain.OAuthAuthorizationCode, error) {
+	payload, err := o.client.GetDel(ctx, o.prefix+codeHash).Bytes()
+	if err != nil {
+		switch {
+		case errors.Is(err, redis.Nil):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	var code domain.OAuthAuthorizationCode
+	if err := json.Unmarshal(payload, &code); err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func NewOAuthCodeRepository(client *redis.Client, prefix string) OAuthCodeRepository {
+	return &oauthCodeRepository{
+		client: client,
+		prefix: prefix,
+	}
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+type OAuthGrantRepository interface {
+	Upsert(ctx context.Context, userId, clientId string, scopes []string) error
+	Get(ctx context.Context, userId, clientId string) (*domain.OAuthGrant, error)
+	GetByUserId(ctx context.Context, userId string) ([]*domain.OAuthGrant, error)
+	Delete(ctx context.Context, userId, clientId string) error
+	DeleteByClientId(ctx context.Context, clientId string) error
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type oauthGrantRepository struct {
+	collection *mongo.Collection
+}
+
+// Upsert adds the given scopes to the user's grant for the client, creating it if needed.
+func (o *oauthGrantRepository) Upsert(ctx context.Context, userId, clientId string, scopes []string) error {
+	filter, err := o.filter(userId, clientId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = o.collection.UpdateOne(ctx, filter, bson.M{
+		"$addToSet":    bson.M{"scopes": bson.M{"$each": scopes}},
+		"$set":         bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+func (o *oauthGrantRepository) Get(ctx context.Context, userId, clientId string) (*domain.OAuthGrant, error) {
+	filter, err := o.filter(userId, clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	var grantDTO mongoDTO.OAuthGrant
+	if err := o.collection.FindOne(ctx, filter).Decode(&grantDTO); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mongoDTO.FromOAuthGrantDTOToCore(&grantDTO), nil
+}
+
+func (o *oauthGrantRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.OAuthGrant, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+
+	cursor, err := o.collection.Find(ctx, bson.M{"user_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var grantsDTO []mongoDTO.OAuthGrant
+	if err := cursor.All(ctx, &grantsDTO); err != nil {
+		return nil, err
+	}
+
+	grants := make([]*domain.OAuthGrant, len(grantsDTO))
+	for i, dto := range grantsDTO {
+		grants[i] = mongoDTO.FromOAuthGrantDTOToCore(&dto)
+	}
+
+	return grants, nil
+}
+
+func (o *oauthGrantRepository) Delete(ctx context.Context, userId, clientId string) error {
+	filter, err := o.filter(userId, clientId)
+	if err != nil {
+		return err
+	}
+
+	result, err := o.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (o *oauthGrantRepository) DeleteByClientId(ctx context.Context, clientId string) error {
+	oid, err := bson.ObjectIDFromHex(clientId)
+	if err != nil {
+		return fmt.Errorf("invalid client id: %w", err)
+	}
+
+	_, err = o.collection.DeleteMany(ctx, bson.M{"client_id": oid})
+	return err
+}
+
+func (o *oauthGrantRepository) filter(userId, clientId string) (bson.M, error) {
+	userOID, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	clientOID, err := bson.ObjectIDFromHex(clientId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	return bson.M{"user_id": userOID, "client_id": clientOID}, nil
+}
+
+func (o *oauthGrantRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = o.collection.DeleteMany(ctx, bson.M{"user_id": oid})
+	return err
+}
+
+func NewOAuthGrantRepository(database *mongo.Database, collectionName string) OAuthGrantRepository {
+	return &oauthGrantRepository{
+		collection: database.Collection(collectionName),
+	}
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OAuthTokenRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, token *domain.OAuthToken) error
+	GetByAccessTokenHash(ctx context.Context, hash string) (*domain.OAuthToken, error)
+	GetByRefreshTokenHash(ctx context.Context, hash string) (*domain.OAuthToken, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByUserAndClient(ctx context.Context, userId, clientId string) error
+	DeleteByClientId(ctx context.Context, clientId string) error
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type oauthTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (o *oauthTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := o.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// Requests made with a token look it up by the hash of the access
+		// token; refreshing and revoking by either hash.
+		{
+			Keys:    bson.D{{Key: "access_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "refresh_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	})
+	return err
+}
+
+func (o *oauthTokenRepository) Create(ctx context.Context, token *domain.OAuthToken) error {
+	tokenDTO, err := mongoDTO.FromOAuthTokenCoreToDTO(token)
+	if err != nil {
+		return err
+	}
+
+	res, err := o.collection.InsertOne(ctx, tokenDTO)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		token.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (o *oauthTokenRepository) GetByAccessTokenHash(ctx context.Context, hash string) (*domain.OAuthToken, error) {
+	return o.findOne(ctx, bson.M{"access_token_hash": hash})
+}
+
+func (o *oauthTokenRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*domain.OAuthToken, error) {
+	return o.findOne(ctx, bson.M{"refresh_token_hash": hash})
+}
+
+func (o *oauthTokenRepository) Delete(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid token id: %w", err)
+	}
+
+	_, err = o.collection.DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
+
+func (o *oauthTokenRepository) DeleteByUserAndClient(ctx context.Context, userId, clientId string) error {
+	userOID, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	clientOID, err := bson.ObjectIDFromHex(clientId)
+	if err != nil {
+		return fmt.Errorf("invalid client id: %w", err)
+	}
+
+	_, err = o.collection.DeleteMany(ctx, bson.M{"user_id": userOID, "client_id": clientOID})
+	return err
+}
+
+func (o *oauthTokenRepository) DeleteByClientId(ctx context.Context, clientId string) error {
+	oid, err := bson.ObjectIDFromHex(clientId)
+	if err != nil {
+		return fmt.Errorf("invalid client id: %w", err)
+	}
+
+	_, err = o.collection.DeleteMany(ctx, bson.M{"client_id": oid})
+	return err
+}
+
+func (o *oauthTokenRepository) findOne(ctx context.Context, filter bson.M) (*domain.OAuthToken, error) {
+	var tokenDTO mongoDTO.OAuthToken
+	if err := o.collection.FindOne(ctx, filter).Decode(&tokenDTO); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mongoDTO.FromOAuthTokenDTOToCore(&tokenDTO), nil
+}
+
+func (o *oauthTokenRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = o.collection.DeleteMany(ctx, bson.M{"user_id": oid})
+	return err
+}
+
+func NewOAuthTokenRepository(database *mongo.Database, collectionName string) OAuthTokenRepository {
+	return &oauthTokenRepository{
+		collection: database.Collection(collectionName),
+	}
+}
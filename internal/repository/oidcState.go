@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"time"
+)
+
+type OIDCStateRepository interface {
+	Save(ctx context.Context, state string, data *domain.OIDCState, ttl time.Duration) error
+	Take(ctx context.Context, state string) (*domain.OIDCState, error)
+}
+
+type oidcStateRepository struct {
+	client *redis.Client
+	prefix string
+}
+
+func (o *oidcStateRepository) Save(ctx context.Context, state string, data *domain.OIDCState, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return o.client.Set(ctx, o.prefix+state, payload, ttl).Err()
+}
+
+// Take returns the stored state and deletes it so it can only be used once.
+func (o *oidcStateRepository) Take(ctx context.Context, state string) (*domain.OIDCState, error) {
+	payload, err := o.client.GetDel(ctx, o.prefix+state).Bytes()
+	if err != nil {
+		switch {
+		case errors.Is(err, redis.Nil):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	var data domain.OIDCState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func NewOIDCStateRepository(client *redis.Client, prefix string) OIDCStateRepository {
+	return &oidcStateRepository{
+		client: client,
+		prefix: prefix,
+	}
+}
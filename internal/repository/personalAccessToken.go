@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+type PersonalAccessTokenRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, token *domain.PersonalAccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error)
+	GetByUserId(ctx context.Context, userId string) ([]*domain.PersonalAccessToken, error)
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
+	Delete(ctx context.Context, id, userId string) error
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type personalAccessTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func (p *personalAccessTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := p.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// Every request made with a token looks it up by its hash.
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (p *personalAccessTokenRepository) Create(ctx context.Context, token *domain.PersonalAccessToken) error {
+	tokenDTO, err := mongoDTO.FromPersonalAccessTokenCoreToDTO(token)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.collection.InsertOne(ctx, tokenDTO)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		token.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (p *personalAccessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.PersonalAccessToken, error) {
+	var tokenDTO mongoDTO.PersonalAccessToken
+	if err := p.collection.FindOne(ctx, bson.M{"token_hash": tokenHash}).Decode(&tokenDTO); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mongoDTO.FromPersonalAccessTokenDTOToCore(&tokenDTO), nil
+}
+
+func (p *personalAccessTokenRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.PersonalAccessToken, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := p.collection.Find(ctx, bson.M{"user_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokensDTO []mongoDTO.PersonalAccessToken
+	if err := cursor.All(ctx, &tokensDTO); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.PersonalAccessToken, len(tokensDTO))
+	for i, dto := range tokensDTO {
+		tokens[i] = mongoDTO.FromPersonalAccessTokenDTOToCore(&dto)
+	}
+
+	return tokens, nil
+}
+
+func (p *personalAccessTokenRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid personal access token id: %w", err)
+	}
+
+	_, err = p.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{"last_used_at": usedAt},
+	})
+	return err
+}
+
+func (p *personalAccessTokenRepository) Delete(ctx context.Context, id, userId string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	userOID, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	result, err := p.collection.DeleteOne(ctx, bson.M{"_id": oid, "user_id": userOID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (p *personalAccessTokenRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = p.collection.DeleteMany(ctx, bson.M{"user_id": oid})
+	return err
+}
+
+func NewPersonalAccessTokenRepository(database *mongo.Database, collectionName string) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{
+		collection: database.Collection(collectionName),
+	}
+}
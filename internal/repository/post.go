@@ -13,22 +13,49 @@ import (
 )
 
 type PostRepository interface {
+	EnsureIndexes(ctx context.Context) error
 	CreatePost(ctx context.Context, post *domain.Post) error
 	GetPostById(ctx context.Context, id string) (*domain.Post, error)
+	GetPostsByIds(ctx context.Context, ids []string) ([]*domain.Post, error)
+	GetRepost(ctx context.Context, creatorId, originalId string) (*domain.Post, error)
+	IncrementReposts(ctx context.Context, id string, delta int64) error
+	IncrementQuotes(ctx context.Context, id string, delta int64) error
+	DeleteReposts(ctx context.Context, originalIds []string) error
 	GetPostsByCreator(ctx context.Context, creatorId string) ([]*domain.Post, error)
 	UpdatePost(ctx context.Context, post *domain.Post) error
 	ToggleLike(ctx context.Context, postId, userId string) error
 	AddComment(ctx context.Context, postId, commentId string) error
 	DeletePost(ctx context.Context, id string) error
 	RemoveCommentFromPost(ctx context.Context, postId, commentId string) error
+	RemoveComments(ctx context.Context, commentIds []string) error
+	RemoveLikesByUser(ctx context.Context, userId string) error
+	GetLikedByUser(ctx context.Context, userId string) ([]*domain.Post, error)
+	DeleteByCreator(ctx context.Context, creatorId string) error
 	GetFeedPosts(ctx context.Context, creatorIds []string, page, limit int) ([]*domain.Post, int64, error)
 	SearchPosts(ctx context.Context, query string) ([]*domain.Post, error)
+	MigrateLegacyFiles(ctx context.Context, convert func(ctx context.Context, postId, creatorId, data string) (*domain.Attachment, error)) (migrated int64, failed int64, err error)
 }
 
 type postRepository struct {
 	collection *mongo.Collection
 }
 
+func (p *postRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := p.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "creator", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "original_id", Value: 1}}},
+		// A user reposts a post at most once.
+		{
+			Keys: bson.D{{Key: "creator", Value: 1}, {Key: "original_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"kind": domain.PostKindRepost}),
+		},
+	})
+	return err
+}
+
+// CreatePost stores the post. It returns ErrAlreadyReposted for a second
+// repost of the same post by the same user.
 func (p *postRepository) CreatePost(ctx context.Context, post *domain.Post) error {
 	postDTO, err := mongoDTO.FromPostCoreToDTO(post)
 	if err != nil {
@@ -37,6 +64,9 @@ func (p *postRepository) CreatePost(ctx context.Context, post *domain.Post) erro
 
 	res, err := p.collection.InsertOne(ctx, postDTO)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) && post.IsRepost() {
+			return ErrAlreadyReposted
+		}
 		return err
 	}
 
@@ -66,6 +96,84 @@ func (p *postRepository) GetPostById(ctx context.Context, id string) (*domain.Po
 	return mongoDTO.FromPostDTOToCore(&postDTO), nil
 }
 
+func (p *postRepository) GetPostsByIds(ctx context.Context, ids []string) ([]*domain.Post, error) {
+	if len(ids) == 0 {
+		return []*domain.Post{}, nil
+	}
+
+	oids, err := toObjectIds(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := p.collection.Find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var postsDTO []mongoDTO.Post
+	if err := cursor.All(ctx, &postsDTO); err != nil {
+		return nil, err
+	}
+
+	posts := make([]*domain.Post, len(postsDTO))
+	for i, dto := range postsDTO {
+		posts[i] = mongoDTO.FromPostDTOToCore(&dto)
+	}
+
+	return posts, nil
+}
+
+// GetRepost returns the user's repost of the original post.
+func (p *postRepository) GetRepost(ctx context.Context, creatorId, originalId string) (*domain.Post, error) {
+	oid, err := bson.ObjectIDFromHex(originalId)
+	if err != nil {
+		return nil, ErrInvalidId
+	}
+
+	var postDTO mongoDTO.Post
+	if err := p.collection.FindOne(ctx, bson.M{
+		"creator":     creatorId,
+		"kind":        domain.PostKindRepost,
+		"original_id": oid,
+	}).Decode(&postDTO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return mongoDTO.FromPostDTOToCore(&postDTO), nil
+}
+
+func (p *postRepository) IncrementReposts(ctx context.Context, id string, delta int64) error {
+	return p.increment(ctx, id, "repost_count", delta)
+}
+
+func (p *postRepository) IncrementQuotes(ctx context.Context, id string, delta int64) error {
+	return p.increment(ctx, id, "quote_count", delta)
+}
+
+// DeleteReposts removes every repost of the given posts. Quotes stay, as
+// they are posts in their own right.
+func (p *postRepository) DeleteReposts(ctx context.Context, originalIds []string) error {
+	if len(originalIds) == 0 {
+		return nil
+	}
+
+	oids, err := toObjectIds(originalIds)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.collection.DeleteMany(ctx, bson.M{
+		"kind":        domain.PostKindRepost,
+		"original_id": bson.M{"$in": oids},
+	})
+	return err
+}
+
 func (p *postRepository) GetPostsByCreator(ctx context.Context, creatorId string) ([]*domain.Post, error) {
 	filter := bson.M{"creator": creatorId}
 
@@ -104,9 +212,10 @@ func (p *postRepository) UpdatePost(ctx context.Context, post *domain.Post) erro
 
 	_, err = p.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
 		"$set": bson.M{
-			"title":         postDTO.Title,
-			"message":       postDTO.Message,
-			"selected_file": postDTO.SelectedFile,
+			"title":    postDTO.Title,
+			"message":  postDTO.Message,
+			"media":    postDTO.Media,
+			"mentions": postDTO.Mentions,
 		},
 	})
 
@@ -180,40 +289,155 @@ func (p *postRepository) RemoveCommentFromPost(ctx context.Context, postId, comm
 	return err
 }
 
+// RemoveComments drops the given comment ids from whichever posts reference them.
+func (p *postRepository) RemoveComments(ctx context.Context, commentIds []string) error {
+	if len(commentIds) == 0 {
+		return nil
+	}
+
+	_, err := p.collection.UpdateMany(ctx, bson.M{
+		"comments": bson.M{"$in": commentIds},
+	}, bson.M{
+		"$pullAll": bson.M{"comments": commentIds},
+	})
+	return err
+}
+
+func (p *postRepository) GetLikedByUser(ctx context.Context, userId string) ([]*domain.Post, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := p.collection.Find(ctx, bson.M{"likes": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []mongoDTO.Post
+	if err := cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Post, len(dtos))
+	for i, dto := range dtos {
+		result[i] = mongoDTO.FromPostDTOToCore(&dto)
+	}
+
+	return result, nil
+}
+
+func (p *postRepository) RemoveLikesByUser(ctx context.Context, userId string) error {
+	_, err := p.collection.UpdateMany(ctx, bson.M{"likes": userId}, bson.M{
+		"$pull": bson.M{"likes": userId},
+	})
+	return err
+}
+
+func (p *postRepository) DeleteByCreator(ctx context.Context, creatorId string) error {
+	_, err := p.collection.DeleteMany(ctx, bson.M{"creator": creatorId})
+	return err
+}
+
+// feedWindowFactor sizes the first window of newest shares GetFeedPosts
+// groups, relative to the posts it needs to fill the page.
+const feedWindowFactor = 2
+
+// GetFeedPosts pages through the posts and reposts of the given creators,
+// newest first. A post shared several times appears once, at its latest share.
+//
+// Grouping shares into posts only looks at a window of the newest shares, so
+// the cost follows the page rather than everything the creators ever posted.
+// The window grows when reposts of the same posts leave it short of a full
+// page. The total is exact once the window holds the whole feed; before
+// that it counts every share, the way the feed did before reposts.
 func (p *postRepository) GetFeedPosts(ctx context.Context, creatorIds []string, page, limit int) ([]*domain.Post, int64, error) {
 	if len(creatorIds) == 0 {
 		return nil, 0, nil
 	}
 
 	filter := bson.M{"creator": bson.M{"$in": creatorIds}}
+	skip := int64((page - 1) * limit)
+	window := int64(page * limit * feedWindowFactor)
 
-	total, err := p.collection.CountDocuments(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
+	for {
+		posts, grouped, scanned, err := p.feedWindow(ctx, filter, window, skip, int64(limit))
+		if err != nil {
+			return nil, 0, err
+		}
 
-	opts := options.Find().
-		SetSort(bson.M{"_id": -1}).
-		SetSkip(int64((page - 1) * limit)).
-		SetLimit(int64(limit))
+		if scanned < window {
+			return posts, grouped, nil
+		}
 
-	cursor, err := p.collection.Find(ctx, filter, opts)
+		if grouped >= skip+int64(limit) {
+			total, err := p.collection.CountDocuments(ctx, filter)
+			if err != nil {
+				return nil, 0, err
+			}
+			return posts, total, nil
+		}
+
+		window *= 4
+	}
+}
+
+// feedWindow groups the newest window shares matching filter into posts and
+// returns a page of them, along with how many posts and shares the window held.
+func (p *postRepository) feedWindow(ctx context.Context, filter bson.M, window, skip, limit int64) ([]*domain.Post, int64, int64, error) {
+	cursor, err := p.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		{{Key: "$limit", Value: window}},
+		// A repost stands for the post it shares, so the post itself and all
+		// its reposts fall into one group and the newest of them is kept.
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$kind", domain.PostKindRepost}},
+				"$original_id",
+				"$_id",
+			}},
+			"post":   bson.M{"$first": "$$ROOT"},
+			"shares": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"window": mongo.Pipeline{{{Key: "$group", Value: bson.M{
+				"_id":     nil,
+				"grouped": bson.M{"$sum": 1},
+				"scanned": bson.M{"$sum": "$shares"},
+			}}}},
+			"posts": mongo.Pipeline{
+				{{Key: "$sort", Value: bson.D{{Key: "post._id", Value: -1}}}},
+				{{Key: "$skip", Value: skip}},
+				{{Key: "$limit", Value: limit}},
+				{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$post"}}},
+			},
+		}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, 0, err
 	}
 	defer cursor.Close(ctx)
 
-	var postsDTO []mongoDTO.Post
-	if err := cursor.All(ctx, &postsDTO); err != nil {
-		return nil, 0, err
+	var result []struct {
+		Window []struct {
+			Grouped int64 `bson:"grouped"`
+			Scanned int64 `bson:"scanned"`
+		} `bson:"window"`
+		Posts []mongoDTO.Post `bson:"posts"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, 0, 0, err
 	}
 
-	posts := make([]*domain.Post, len(postsDTO))
-	for i, dto := range postsDTO {
+	if len(result) == 0 || len(result[0].Window) == 0 {
+		return []*domain.Post{}, 0, 0, nil
+	}
+
+	posts := make([]*domain.Post, len(result[0].Posts))
+	for i, dto := range result[0].Posts {
 		posts[i] = mongoDTO.FromPostDTOToCore(&dto)
 	}
 
-	return posts, total, nil
+	return posts, result[0].Window[0].Grouped, result[0].Window[0].Scanned, nil
 }
 
 func (p *postRepository) SearchPosts(ctx context.Context, query string) ([]*domain.Post, error) {
@@ -248,6 +472,66 @@ func (p *postRepository) SearchPosts(ctx context.Context, query string) ([]*doma
 	return posts, nil
 }
 
+// MigrateLegacyFiles moves the base64 images posts used to carry in
+// selected_file into media: convert stores one and returns its attachment,
+// which replaces the payload. Posts whose payload can't be converted keep it
+// and are counted as failed. It is safe to run again after an interruption.
+func (p *postRepository) MigrateLegacyFiles(ctx context.Context, convert func(ctx context.Context, postId, creatorId, data string) (*domain.Attachment, error)) (int64, int64, error) {
+	type legacyPost struct {
+		Id           bson.ObjectID `bson:"_id"`
+		Creator      string        `bson:"creator"`
+		SelectedFile string        `bson:"selected_file"`
+	}
+
+	if _, err := p.collection.UpdateMany(ctx, bson.M{"selected_file": ""}, bson.M{
+		"$unset": bson.M{"selected_file": ""},
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	cursor, err := p.collection.Find(ctx, bson.M{
+		"selected_file": bson.M{"$exists": true},
+	}, options.Find().SetProjection(bson.M{"creator": 1, "selected_file": 1}))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var migrated, failed int64
+	for cursor.Next(ctx) {
+		var post legacyPost
+		if err := cursor.Decode(&post); err != nil {
+			return migrated, failed, err
+		}
+
+		attachment, err := convert(ctx, post.Id.Hex(), post.Creator, post.SelectedFile)
+		if err != nil {
+			failed++
+			continue
+		}
+
+		if _, err := p.collection.UpdateOne(ctx, bson.M{"_id": post.Id}, bson.M{
+			"$push":  bson.M{"media": mongoDTO.FromAttachmentsCoreToDTO([]domain.Attachment{*attachment})[0]},
+			"$unset": bson.M{"selected_file": ""},
+		}); err != nil {
+			return migrated, failed, err
+		}
+		migrated++
+	}
+
+	return migrated, failed, cursor.Err()
+}
+
+func (p *postRepository) increment(ctx context.Context, id, field string, delta int64) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrInvalidId
+	}
+
+	_, err = p.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$inc": bson.M{field: delta}})
+	return err
+}
+
 func NewPostRepository(database *mongo.Database, collectionName string) PostRepository {
 	return &postRepository{
 		collection: database.Collection(collectionName),
@@ -0,0 +1,202 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// testDatabase connects to the MongoDB in MONGODB_TEST_URI and returns a
+// fresh database that is dropped afterwards. Tests needing one are skipped
+// when the variable isn't set.
+func testDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI is not set")
+	}
+
+	client, err := mongo.Connect(options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	database := client.Database("xgopher_test_" + bson.NewObjectID().Hex())
+	t.Cleanup(func() {
+		_ = database.Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+
+	return database
+}
+
+func TestPostRepositoryMigrateLegacyFiles(t *testing.T) {
+	database := testDatabase(t)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 50))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+
+	creator := bson.NewObjectID()
+	posts := map[string]bson.M{
+		"base64":   {"_id": bson.NewObjectID(), "creator": creator.Hex(), "selected_file": base64.StdEncoding.EncodeToString(buf.Bytes())},
+		"data url": {"_id": bson.NewObjectID(), "creator": creator.Hex(), "selected_file": "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())},
+		"invalid":  {"_id": bson.NewObjectID(), "creator": creator.Hex(), "selected_file": "not an image"},
+		"empty":    {"_id": bson.NewObjectID(), "creator": creator.Hex(), "selected_file": ""},
+		"none":     {"_id": bson.NewObjectID(), "creator": creator.Hex()},
+	}
+	for _, post := range posts {
+		if _, err := database.Collection("post").InsertOne(ctx, post); err != nil {
+			t.Fatalf("insert post: %v", err)
+		}
+	}
+
+	cfg := &config.Config{Media: config.Media{MaxSize: 1 << 20, MaxDimension: 64, ThumbnailSize: 16}}
+	fileStorage := storage.NewLocal(storage.WithDir(t.TempDir()), storage.WithBaseURL("http://localhost:4000/uploads"))
+	postRepository := repository.NewPostRepository(database, "post")
+	mediaRepository := repository.NewMediaRepository(database, "media")
+	mediaService := service.NewMediaService(cfg, mediaRepository, fileStorage)
+
+	migrated, failed, err := postRepository.MigrateLegacyFiles(ctx, mediaService.ImportLegacy)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if migrated != 2 || failed != 1 {
+		t.Errorf("expected 2 migrated and 1 failed, got %d and %d", migrated, failed)
+	}
+
+	for name, legacy := range posts {
+		t.Run(name, func(t *testing.T) {
+			id := legacy["_id"].(bson.ObjectID)
+
+			var raw bson.M
+			if err := database.Collection("post").FindOne(ctx, bson.M{"_id": id}).Decode(&raw); err != nil {
+				t.Fatalf("find post: %v", err)
+			}
+
+			switch name {
+			case "invalid":
+				if raw["selected_file"] != legacy["selected_file"] {
+					t.Errorf("expected the payload to be kept, got %v", raw["selected_file"])
+				}
+				return
+			case "empty", "none":
+				if _, ok := raw["selected_file"]; ok {
+					t.Errorf("expected no selected_file, got %v", raw["selected_file"])
+				}
+				return
+			}
+
+			if _, ok := raw["selected_file"]; ok {
+				t.Error("expected selected_file to be removed")
+			}
+
+			post, err := postRepository.GetPostById(ctx, id.Hex())
+			if err != nil {
+				t.Fatalf("get post: %v", err)
+			}
+			if len(post.Media) != 1 {
+				t.Fatalf("expected one attachment, got %+v", post.Media)
+			}
+
+			media, err := mediaRepository.GetById(ctx, post.Media[0].MediaId)
+			if err != nil {
+				t.Fatalf("get media: %v", err)
+			}
+			if media.PostId != id.Hex() || media.OwnerId != creator.Hex() || !media.IsReady() {
+				t.Errorf("expected ready media of the creator on the post, got %+v", media)
+			}
+			if post.Media[0].Url != media.Url || post.Media[0].Width != 64 || post.Media[0].Height != 32 {
+				t.Errorf("expected a 64x32 attachment at %s, got %+v", media.Url, post.Media[0])
+			}
+
+			key, _ := fileStorage.Key(media.Url)
+			if _, err := fileStorage.Get(ctx, key); err != nil {
+				t.Errorf("expected the image in storage: %v", err)
+			}
+		})
+	}
+
+	// Running it again finds nothing left to convert but the invalid payload.
+	if migrated, failed, err := postRepository.MigrateLegacyFiles(ctx, mediaService.ImportLegacy); err != nil || migrated != 0 || failed != 1 {
+		t.Errorf("expected a rerun to migrate 0 and fail 1, got %d, %d (%v)", migrated, failed, err)
+	}
+}
+
+func TestPostRepositoryGetFeedPosts(t *testing.T) {
+	database := testDatabase(t)
+	ctx := context.Background()
+
+	// Ids are increasing, so later inserts are newer. "a" writes three posts,
+	// then seven others repost the oldest, filling the first windows with
+	// shares of a single post.
+	var docs []any
+	post := func(creator string) bson.ObjectID {
+		id := bson.NewObjectID()
+		docs = append(docs, bson.M{"_id": id, "creator": creator, "message": "post"})
+		return id
+	}
+	p1, p2, p3 := post("a"), post("a"), post("a")
+	var latestRepost bson.ObjectID
+	for _, creator := range []string{"b", "c", "d", "e", "f", "g", "h"} {
+		latestRepost = bson.NewObjectID()
+		docs = append(docs, bson.M{"_id": latestRepost, "creator": creator, "kind": domain.PostKindRepost, "original_id": p1})
+	}
+	if _, err := database.Collection("post").InsertMany(ctx, docs); err != nil {
+		t.Fatalf("insert posts: %v", err)
+	}
+
+	postRepository := repository.NewPostRepository(database, "post")
+	everyone := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	tests := []struct {
+		name       string
+		creatorIds []string
+		page       int
+		limit      int
+		wantIds    []bson.ObjectID
+		wantTotal  int64
+	}{
+		{name: "reposts collapse into the latest share", creatorIds: everyone, page: 1, limit: 2, wantIds: []bson.ObjectID{latestRepost, p3}, wantTotal: 3},
+		{name: "last page", creatorIds: everyone, page: 2, limit: 2, wantIds: []bson.ObjectID{p2}, wantTotal: 3},
+		{name: "past the end", creatorIds: everyone, page: 3, limit: 2, wantIds: nil, wantTotal: 3},
+		{name: "feed larger than the window", creatorIds: []string{"a"}, page: 1, limit: 1, wantIds: []bson.ObjectID{p3}, wantTotal: 3},
+		{name: "no posts", creatorIds: []string{"nobody"}, page: 1, limit: 2, wantIds: nil, wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, total, err := postRepository.GetFeedPosts(ctx, tt.creatorIds, tt.page, tt.limit)
+			if err != nil {
+				t.Fatalf("get feed: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("expected total %d, got %d", tt.wantTotal, total)
+			}
+
+			if len(posts) != len(tt.wantIds) {
+				t.Fatalf("expected %d posts, got %d", len(tt.wantIds), len(posts))
+			}
+			for i, post := range posts {
+				if post.Id != tt.wantIds[i].Hex() {
+					t.Errorf("post %d: expected %s, got %s", i, tt.wantIds[i].Hex(), post.Id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"time"
+)
+
+// Ranking weights. A mutual connection is the strongest signal, followed by
+// the candidate having liked the viewer's posts, then recent posting.
+const (
+	suggestionMutualWeight      = 3
+	suggestionInteractionWeight = 2
+	suggestionActivityWeight    = 1
+	suggestionSignalCap         = 5
+	suggestionMutualsShown      = 2
+)
+
+// SuggestionQuery describes whose suggestions to compute. Candidates are the
+// users followed by FollowingIds, minus ExcludeIds.
+type SuggestionQuery struct {
+	ViewerId      string
+	FollowingIds  []string
+	ExcludeIds    []string
+	ActiveSince   time.Time
+	Offset, Limit int
+}
+
+// SuggestionRepository ranks who-to-follow candidates in a single aggregation
+// over the follow graph, joining users and posts for the remaining signals.
+type SuggestionRepository interface {
+	GetSuggestions(ctx context.Context, query *SuggestionQuery) ([]*domain.Suggestion, int64, error)
+}
+
+type suggestionRepository struct {
+	followCollection   *mongo.Collection
+	userCollectionName string
+	postCollectionName string
+}
+
+func (s *suggestionRepository) GetSuggestions(ctx context.Context, query *SuggestionQuery) ([]*domain.Suggestion, int64, error) {
+	following, err := toObjectIds(query.FollowingIds)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	exclude, err := toObjectIds(query.ExcludeIds)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"follower_id": bson.M{"$in": following},
+			"followee_id": bson.M{"$nin": exclude},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$followee_id",
+			"mutual_count": bson.M{"$sum": 1},
+			"mutual_ids":   bson.M{"$push": "$follower_id"},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         s.userCollectionName,
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "user",
+		}}},
+		{{Key: "$unwind", Value: "$user"}},
+		{{Key: "$match", Value: bson.M{
+			"user.deactivated_at": bson.M{"$exists": false},
+			"user.is_private":     bson.M{"$ne": true},
+		}}},
+		// Posts reference their creator by hex id.
+		{{Key: "$addFields", Value: bson.M{"candidate": bson.M{"$toString": "$_id"}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": s.postCollectionName,
+			"let":  bson.M{"candidate": "$candidate"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"$expr":      bson.M{"$eq": bson.A{"$creator", "$$candidate"}},
+					"created_at": bson.M{"$gte": query.ActiveSince},
+				}}},
+				{{Key: "$count", Value: "n"}},
+			},
+			"as": "recent_posts",
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": s.postCollectionName,
+			"let":  bson.M{"candidate": "$candidate"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{
+					"creator": query.ViewerId,
+					"$expr":   bson.M{"$in": bson.A{"$$candidate", bson.M{"$ifNull": bson.A{"$likes", bson.A{}}}}},
+				}}},
+				{{Key: "$count", Value: "n"}},
+			},
+			"as": "interactions",
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"score": bson.M{"$add": bson.A{
+				bson.M{"$multiply": bson.A{"$mutual_count", suggestionMutualWeight}},
+				bson.M{"$multiply": bson.A{s.cappedCount("$interactions"), suggestionInteractionWeight}},
+				bson.M{"$multiply": bson.A{s.cappedCount("$recent_posts"), suggestionActivityWeight}},
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "mutual_count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"results": mongo.Pipeline{
+				{{Key: "$skip", Value: query.Offset}},
+				{{Key: "$limit", Value: query.Limit}},
+				{{Key: "$project", Value: bson.M{
+					"user":         1,
+					"score":        1,
+					"mutual_count": 1,
+					"mutual_ids":   bson.M{"$slice": bson.A{"$mutual_ids", suggestionMutualsShown}},
+				}}},
+			},
+			"total": mongo.Pipeline{
+				{{Key: "$count", Value: "n"}},
+			},
+		}}},
+	}
+
+	cursor, err := s.followCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets []struct {
+		Results []mongoDTO.Suggestion `bson:"results"`
+		Total   []struct {
+			N int64 `bson:"n"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &facets); err != nil {
+		return nil, 0, err
+	}
+
+	if len(facets) == 0 || len(facets[0].Total) == 0 {
+		return []*domain.Suggestion{}, 0, nil
+	}
+
+	suggestions := make([]*domain.Suggestion, len(facets[0].Results))
+	for i, suggestion := range facets[0].Results {
+		suggestions[i] = mongoDTO.FromSuggestionDTOToCore(&suggestion)
+	}
+
+	return suggestions, facets[0].Total[0].N, nil
+}
+
+// cappedCount reads the count out of a $count lookup, capped so a single
+// signal cannot outweigh the others.
+func (s *suggestionRepository) cappedCount(field string) bson.M {
+	return bson.M{"$min": bson.A{
+		bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{field + ".n", 0}}, 0}},
+		suggestionSignalCap,
+	}}
+}
+
+func toObjectIds(ids []string) ([]bson.ObjectID, error) {
+	oids := make([]bson.ObjectID, len(ids))
+	for i, id := range ids {
+		oid, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, ErrInvalidId
+		}
+		oids[i] = oid
+	}
+	return oids, nil
+}
+
+func NewSuggestionRepository(database *mongo.Database, followCollectionName, userCollectionName, postCollectionName string) SuggestionRepository {
+	return &suggestionRepository{
+		followCollection:   database.Collection(followCollectionName),
+		userCollectionName: userCollectionName,
+		postCollectionName: postCollectionName,
+	}
+}
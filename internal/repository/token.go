@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"time"
 )
 
@@ -16,6 +18,8 @@ type TokenRepository interface {
 	DeleteRefreshToken(ctx context.Context, token string) error
 	DeleteRefreshTokenById(ctx context.Context, id string) error
 	DeleteExpired(ctx context.Context) error
+	DeleteByUserId(ctx context.Context, userId string) error
+	GetByUserId(ctx context.Context, userId string) ([]*domain.RefreshToken, error)
 }
 
 type tokenRepository struct {
@@ -81,6 +85,43 @@ func (t *tokenRepository) DeleteExpired(ctx context.Context) error {
 	return err
 }
 
+func (t *tokenRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.RefreshToken, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := t.collection.Find(ctx, bson.M{"user_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []mongoDTO.RefreshToken
+	if err := cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.RefreshToken, len(dtos))
+	for i, dto := range dtos {
+		result[i] = mongoDTO.FromRefreshTokenDTOToCore(&dto)
+	}
+
+	return result, nil
+}
+
+func (t *tokenRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = t.collection.DeleteMany(ctx, bson.M{"user_id": oid})
+	return err
+}
+
 func NewTokenRepository(database *mongo.Database, collectionName string) TokenRepository {
 	return &tokenRepository{
 		collection: database.Collection(collectionName),
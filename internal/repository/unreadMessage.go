@@ -15,6 +15,7 @@ type UnreadMessageRepository interface {
 	IncrementUnread(ctx context.Context, senderId, receiverId string) error
 	GetUnreadByReceiver(ctx context.Context, receiverId string) ([]*domain.UnreadMessage, error)
 	MarkAsRead(ctx context.Context, receiverId, senderId string) error
+	DeleteByUserId(ctx context.Context, userId string) error
 }
 
 type unreadMessageRepository struct {
@@ -113,6 +114,22 @@ func (u *unreadMessageRepository) MarkAsRead(ctx context.Context, receiverId, se
 	return err
 }
 
+// DeleteByUserId removes everything the user sent or received.
+func (u *unreadMessageRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = u.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"sender_id": oid},
+			{"receiver_id": oid},
+		},
+	})
+	return err
+}
+
 func NewUnreadMessageRepository(database *mongo.Database, collectionName string) UnreadMessageRepository {
 	return &unreadMessageRepository{
 		collection: database.Collection(collectionName),
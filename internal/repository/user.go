@@ -8,18 +8,28 @@ import (
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"strings"
+	"time"
 )
 
 type UserRepository interface {
+	EnsureIndexes(ctx context.Context) error
 	CreateUser(ctx context.Context, user *domain.User) error
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetUserById(ctx context.Context, id string) (*domain.User, error)
+	GetUserByHandle(ctx context.Context, handle string) (*domain.User, error)
+	GetUsersByHandles(ctx context.Context, handles []string) ([]*domain.User, error)
 	GetUsersByIds(ctx context.Context, ids []string) ([]*domain.User, error)
 	GetUsersBySearch(ctx context.Context, query string) ([]*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
-	Follow(ctx context.Context, followerId, followeeId string) error
-	Unfollow(ctx context.Context, followerId, followeeId string) error
+	UpdateAvatar(ctx context.Context, id, url string) error
+	UpdateBanner(ctx context.Context, id, url string) error
+	UpdateRole(ctx context.Context, id, role string) error
+	UpdateHandle(ctx context.Context, id, handle string, at time.Time) error
+	Deactivate(ctx context.Context, id string, at time.Time) error
+	Reactivate(ctx context.Context, id string) error
+	GetDeactivatedBefore(ctx context.Context, cutoff time.Time) ([]*domain.User, error)
 	DeleteUser(ctx context.Context, id string) error
 }
 
@@ -27,6 +37,25 @@ type userRepository struct {
 	collection *mongo.Collection
 }
 
+// EnsureIndexes makes emails and handles unique, so a signup or handle change
+// racing another for the same value fails with ErrDuplicateEmail or
+// ErrDuplicateHandle instead of both succeeding.
+func (u *userRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := u.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Handles compare case-insensitively; users without one yet are left out.
+		{
+			Keys: bson.D{{Key: "handle_lower", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"handle_lower": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
 func (u *userRepository) CreateUser(ctx context.Context, user *domain.User) error {
 	userDTO, err := mongoDTO.FromUserCoreToDTO(user)
 	if err != nil {
@@ -35,8 +64,11 @@ func (u *userRepository) CreateUser(ctx context.Context, user *domain.User) erro
 
 	res, err := u.collection.InsertOne(ctx, userDTO)
 	if err != nil {
-		if u.isDuplicateEmailError(err) {
+		switch {
+		case u.isDuplicateKeyError(err, "email"):
 			return ErrDuplicateEmail
+		case u.isDuplicateKeyError(err, "handle"):
+			return ErrDuplicateHandle
 		}
 		return err
 	}
@@ -76,6 +108,53 @@ func (u *userRepository) GetUserById(ctx context.Context, id string) (*domain.Us
 	return mongoDTO.FromUserDTOToCore(&userDTO), nil
 }
 
+// GetUserByHandle looks the user up by handle, ignoring case.
+func (u *userRepository) GetUserByHandle(ctx context.Context, handle string) (*domain.User, error) {
+	var userDTO mongoDTO.User
+
+	if err := u.collection.FindOne(ctx, bson.M{"handle_lower": strings.ToLower(handle)}).Decode(&userDTO); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return mongoDTO.FromUserDTOToCore(&userDTO), nil
+}
+
+// GetUsersByHandles returns the active users owning any of the handles, ignoring case.
+func (u *userRepository) GetUsersByHandles(ctx context.Context, handles []string) ([]*domain.User, error) {
+	if len(handles) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	lowered := make([]string, len(handles))
+	for i, handle := range handles {
+		lowered[i] = strings.ToLower(handle)
+	}
+
+	cursor, err := u.collection.Find(ctx, bson.M{
+		"handle_lower":   bson.M{"$in": lowered},
+		"deactivated_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var usersDTO []mongoDTO.User
+	if err := cursor.All(ctx, &usersDTO); err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.User, len(usersDTO))
+	for i, dto := range usersDTO {
+		users[i] = mongoDTO.FromUserDTOToCore(&dto)
+	}
+
+	return users, nil
+}
+
 func (u *userRepository) GetUsersByIds(ctx context.Context, ids []string) ([]*domain.User, error) {
 	if len(ids) == 0 {
 		return []*domain.User{}, nil
@@ -91,7 +170,8 @@ func (u *userRepository) GetUsersByIds(ctx context.Context, ids []string) ([]*do
 	}
 
 	cursor, err := u.collection.Find(ctx, bson.M{
-		"_id": bson.M{"$in": objectIDs},
+		"_id":            bson.M{"$in": objectIDs},
+		"deactivated_at": bson.M{"$exists": false},
 	})
 	if err != nil {
 		return nil, err
@@ -121,7 +201,9 @@ func (u *userRepository) GetUsersBySearch(ctx context.Context, query string) ([]
 			{"first_name": bson.M{"$regex": query, "$options": "i"}},
 			{"last_name": bson.M{"$regex": query, "$options": "i"}},
 			{"email": bson.M{"$regex": query, "$options": "i"}},
+			{"handle": bson.M{"$regex": query, "$options": "i"}},
 		},
+		"deactivated_at": bson.M{"$exists": false},
 	}
 
 	cursor, err := u.collection.Find(ctx, filter)
@@ -154,8 +236,8 @@ func (u *userRepository) UpdateUser(ctx context.Context, user *domain.User) erro
 		"$set": bson.M{
 			"first_name": user.FirstName,
 			"last_name":  user.LastName,
-			"image_url":  user.ImageUrl,
 			"bio":        user.Bio,
+			"is_private": user.IsPrivate,
 		},
 	}
 
@@ -170,58 +252,141 @@ func (u *userRepository) UpdateUser(ctx context.Context, user *domain.User) erro
 	return nil
 }
 
-func (u *userRepository) Follow(ctx context.Context, followerId, followeeId string) error {
-	followerOId, err := bson.ObjectIDFromHex(followerId)
+func (u *userRepository) UpdateAvatar(ctx context.Context, id, url string) error {
+	return u.setField(ctx, id, "image_url", url)
+}
+
+func (u *userRepository) UpdateBanner(ctx context.Context, id, url string) error {
+	return u.setField(ctx, id, "banner_url", url)
+}
+
+func (u *userRepository) setField(ctx context.Context, id, field string, value any) error {
+	oid, err := bson.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid follower id: %w", err)
+		return fmt.Errorf("invalid user id: %w", err)
 	}
 
-	followeeOId, err := bson.ObjectIDFromHex(followeeId)
+	result, err := u.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{field: value},
+	})
 	if err != nil {
-		return fmt.Errorf("invalid followee id: %w", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
 	}
 
-	if _, err := u.collection.UpdateOne(ctx, bson.M{"_id": followeeOId}, bson.M{
-		"$addToSet": bson.M{"followers": followerId},
-	}); err != nil {
-		return err
+	return nil
+}
+
+func (u *userRepository) UpdateRole(ctx context.Context, id, role string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
 	}
 
-	if _, err := u.collection.UpdateOne(ctx, bson.M{"_id": followerOId}, bson.M{
-		"$addToSet": bson.M{"following": followeeId},
-	}); err != nil {
+	result, err := u.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{"role": role},
+	})
+	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
 
 	return nil
 }
 
-func (u *userRepository) Unfollow(ctx context.Context, followerId, followeeId string) error {
-	followerOId, err := bson.ObjectIDFromHex(followerId)
+func (u *userRepository) UpdateHandle(ctx context.Context, id, handle string, at time.Time) error {
+	oid, err := bson.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid follower id: %w", err)
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	result, err := u.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{
+			"handle":            handle,
+			"handle_lower":      strings.ToLower(handle),
+			"handle_changed_at": at,
+		},
+	})
+	if err != nil {
+		if u.isDuplicateKeyError(err, "handle") {
+			return ErrDuplicateHandle
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
 	}
 
-	followeeOId, err := bson.ObjectIDFromHex(followeeId)
+	return nil
+}
+
+func (u *userRepository) Deactivate(ctx context.Context, id string, at time.Time) error {
+	oid, err := bson.ObjectIDFromHex(id)
 	if err != nil {
-		return fmt.Errorf("invalid followee id: %w", err)
+		return fmt.Errorf("invalid user id: %w", err)
 	}
 
-	if _, err := u.collection.UpdateOne(ctx, bson.M{"_id": followeeOId}, bson.M{
-		"$pull": bson.M{"followers": followerId},
-	}); err != nil {
+	result, err := u.collection.UpdateOne(ctx, bson.M{
+		"_id":            oid,
+		"deactivated_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{"deactivated_at": at},
+	})
+	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (u *userRepository) Reactivate(ctx context.Context, id string) error {
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
 
-	if _, err := u.collection.UpdateOne(ctx, bson.M{"_id": followerOId}, bson.M{
-		"$pull": bson.M{"following": followeeId},
-	}); err != nil {
+	result, err := u.collection.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$unset": bson.M{"deactivated_at": ""},
+	})
+	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
 
 	return nil
 }
 
+func (u *userRepository) GetDeactivatedBefore(ctx context.Context, cutoff time.Time) ([]*domain.User, error) {
+	cursor, err := u.collection.Find(ctx, bson.M{
+		"deactivated_at": bson.M{"$lte": cutoff},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var usersDTO []mongoDTO.User
+	if err := cursor.All(ctx, &usersDTO); err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.User, len(usersDTO))
+	for i, dto := range usersDTO {
+		users[i] = mongoDTO.FromUserDTOToCore(&dto)
+	}
+
+	return users, nil
+}
+
 func (u *userRepository) DeleteUser(ctx context.Context, id string) error {
 	oid, err := bson.ObjectIDFromHex(id)
 	if err != nil {
@@ -240,11 +405,11 @@ func (u *userRepository) DeleteUser(ctx context.Context, id string) error {
 	return nil
 }
 
-func (u *userRepository) isDuplicateEmailError(err error) bool {
+func (u *userRepository) isDuplicateKeyError(err error, field string) bool {
 	var we mongo.WriteException
 	if errors.As(err, &we) {
 		for _, e := range we.WriteErrors {
-			if e.Code == 11000 && strings.Contains(e.Message, "email") {
+			if e.Code == 11000 && strings.Contains(e.Message, field) {
 				return true
 			}
 		}
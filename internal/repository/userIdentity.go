@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository/mongoDTO"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type UserIdentityRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, identity *domain.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+	GetByUserId(ctx context.Context, userId string) ([]*domain.UserIdentity, error)
+}
+
+type userIdentityRepository struct {
+	collection *mongo.Collection
+}
+
+func (u *userIdentityRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := u.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	return err
+}
+
+// Create links the identity. It returns ErrIdentityLinked when the provider
+// subject is already linked to a user.
+func (u *userIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	identityDTO, err := mongoDTO.FromUserIdentityCoreToDTO(identity)
+	if err != nil {
+		return err
+	}
+
+	res, err := u.collection.InsertOne(ctx, identityDTO)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrIdentityLinked
+		}
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(bson.ObjectID); ok {
+		identity.Id = oid.Hex()
+	}
+
+	return nil
+}
+
+func (u *userIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	var identityDTO mongoDTO.UserIdentity
+	if err := u.collection.FindOne(ctx, bson.M{"provider": provider, "subject": subject}).Decode(&identityDTO); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mongoDTO.FromUserIdentityDTOToCore(&identityDTO), nil
+}
+
+func (u *userIdentityRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.UserIdentity, error) {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := u.collection.Find(ctx, bson.M{"user_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var dtos []mongoDTO.UserIdentity
+	if err := cursor.All(ctx, &dtos); err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.UserIdentity, len(dtos))
+	for i, dto := range dtos {
+		result[i] = mongoDTO.FromUserIdentityDTOToCore(&dto)
+	}
+
+	return result, nil
+}
+
+func (u *userIdentityRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	oid, err := bson.ObjectIDFromHex(userId)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	_, err = u.collection.DeleteMany(ctx, bson.M{"user_id": oid})
+	return err
+}
+
+func NewUserIdentityRepository(database *mongo.Database, collectionName string) UserIdentityRepository {
+	return &userIdentityRepository{
+		collection: database.Collection(collectionName),
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"time"
+)
+
+type VerificationTokenRepository interface {
+	Save(ctx context.Context, tokenHash string, token *domain.VerificationToken, ttl time.Duration) error
+	Take(ctx context.Context, purpose, tokenHash string) (*domain.VerificationToken, error)
+}
+
+type verificationTokenRepository struct {
+	client *redis.Client
+	prefix string
+}
+
+func (v *verificationTokenRepository) key(purpose, tokenHash string) string {
+	return v.prefix + purpose + ":" + tokenHash
+}
+
+func (v *verificationTokenRepository) Save(ctx context.Context, tokenHash string, token *domain.VerificationToken, ttl time.Duration) error {
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return v.client.Set(ctx, v.key(token.Purpose, tokenHash), payload, ttl).Err()
+}
+
+// Take returns the token and deletes it so it can only be redeemed once.
+// Tokens issued for another purpose are never found.
+func (v *verificationTokenRepository) Take(ctx context.Context, purpose, tokenHash string) (*domain.VerificationToken, error) {
+	payload, err := v.client.GetDel(ctx, v.key(purpose, tokenHash)).Bytes()
+	if err != nil {
+		switch {
+		case errors.Is(err, redis.Nil):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	var token domain.VerificationToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func NewVerificationTokenRepository(client *redis.Client, prefix string) VerificationTokenRepository {
+	return &verificationTokenRepository{
+		client: client,
+		prefix: prefix,
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"time"
+)
+
+type AccountService interface {
+	Deactivate(ctx context.Context, actorId, id string) error
+	Purge(ctx context.Context, userId string) error
+	PurgeExpired(ctx context.Context) (int, error)
+	IsActive(ctx context.Context, userId string) (bool, error)
+}
+
+type accountService struct {
+	config                        *config.Config
+	userRepository                repository.UserRepository
+	followRepository              repository.FollowRepository
+	followRequestRepository       repository.FollowRequestRepository
+	blockRepository               repository.BlockRepository
+	muteRepository                repository.MuteRepository
+	postRepository                repository.PostRepository
+	commentRepository             repository.CommentRepository
+	messageRepository             repository.MessageRepository
+	unreadMessageRepository       repository.UnreadMessageRepository
+	notificationRepository        repository.NotificationRepository
+	tokenRepository               repository.TokenRepository
+	personalAccessTokenRepository repository.PersonalAccessTokenRepository
+	oauthClientRepository         repository.OAuthClientRepository
+	oauthGrantRepository          repository.OAuthGrantRepository
+	oauthTokenRepository          repository.OAuthTokenRepository
+	userIdentityRepository        repository.UserIdentityRepository
+	dataExportService             DataExportService
+	listService                   ListService
+	mediaService                  MediaService
+	storage                       storage.Storage
+}
+
+// Deactivate hides the account and signs it out everywhere. Logging in again
+// before the grace period ends restores it; afterwards it is purged.
+func (a *accountService) Deactivate(ctx context.Context, actorId, id string) error {
+	if !canManageUser(ctx, actorId, id) {
+		return repository.ErrForbidden
+	}
+
+	if err := a.userRepository.Deactivate(ctx, id, time.Now()); err != nil {
+		return err
+	}
+
+	if err := a.revokeCredentials(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke credentials: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeExpired purges every account whose grace period has ended and returns
+// how many were purged. A failing account does not hold up the others; it is
+// reported in the error and retried on the next run.
+func (a *accountService) PurgeExpired(ctx context.Context) (int, error) {
+	users, err := a.userRepository.GetDeactivatedBefore(ctx, time.Now().Add(-a.config.Account.GracePeriod))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	var errs []error
+	for _, user := range users {
+		if err := a.Purge(ctx, user.Id); err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge user %s: %w", user.Id, err))
+			continue
+		}
+		purged++
+	}
+
+	if len(errs) > 0 {
+		return purged, fmt.Errorf("failed to purge %d of %d users: %w", len(errs), len(users), errors.Join(errs...))
+	}
+
+	return purged, nil
+}
+
+// IsActive reports whether the user exists and is not deactivated. Access
+// tokens are stateless, so requests check it to stop working as soon as the
+// account is deactivated instead of when the token expires.
+func (a *accountService) IsActive(ctx context.Context, userId string) (bool, error) {
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) || errors.Is(err, repository.ErrInvalidId) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return !user.IsDeactivated(), nil
+}
+
+// Purge deletes the user and everything that references them. Every step is
+// idempotent and the user document goes last, so an interrupted purge can be
+// run again. The audit and moderation logs are kept on purpose.
+func (a *accountService) Purge(ctx context.Context, userId string) error {
+	posts, err := a.postRepository.GetPostsByCreator(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	postIds := make([]string, len(posts))
+	for i, post := range posts {
+		postIds[i] = post.Id
+	}
+
+	if err := a.commentRepository.DeleteByPostIds(ctx, postIds); err != nil {
+		return fmt.Errorf("failed to delete comments on posts: %w", err)
+	}
+
+	if err := deleteShares(ctx, a.postRepository, posts...); err != nil {
+		return fmt.Errorf("failed to delete reposts: %w", err)
+	}
+
+	if err := a.postRepository.DeleteByCreator(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete posts: %w", err)
+	}
+
+	if err := a.mediaService.DeleteByOwnerId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete media: %w", err)
+	}
+
+	commentIds, err := a.commentRepository.GetIdsByUserId(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	if err := a.postRepository.RemoveComments(ctx, commentIds); err != nil {
+		return fmt.Errorf("failed to detach comments: %w", err)
+	}
+
+	if err := a.commentRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	if err := a.postRepository.RemoveLikesByUser(ctx, userId); err != nil {
+		return fmt.Errorf("failed to remove likes: %w", err)
+	}
+
+	if err := a.commentRepository.RemoveLikesByUser(ctx, userId); err != nil {
+		return fmt.Errorf("failed to remove comment likes: %w", err)
+	}
+
+	if err := a.followRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to remove follows: %w", err)
+	}
+
+	if err := a.followRequestRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete follow requests: %w", err)
+	}
+
+	if err := a.blockRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete blocks: %w", err)
+	}
+
+	if err := a.muteRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete mutes: %w", err)
+	}
+
+	if err := a.messageRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+
+	if err := a.unreadMessageRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete unread counters: %w", err)
+	}
+
+	if err := a.notificationRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+
+	if err := a.revokeCredentials(ctx, userId); err != nil {
+		return fmt.Errorf("failed to revoke credentials: %w", err)
+	}
+
+	clients, err := a.oauthClientRepository.GetByOwnerId(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get oauth clients: %w", err)
+	}
+
+	for _, client := range clients {
+		if err := a.oauthGrantRepository.DeleteByClientId(ctx, client.Id); err != nil {
+			return fmt.Errorf("failed to delete oauth client grants: %w", err)
+		}
+		if err := a.oauthTokenRepository.DeleteByClientId(ctx, client.Id); err != nil {
+			return fmt.Errorf("failed to delete oauth client tokens: %w", err)
+		}
+		if err := a.oauthClientRepository.Delete(ctx, client.Id, userId); err != nil {
+			return fmt.Errorf("failed to delete oauth client: %w", err)
+		}
+	}
+
+	if err := a.userIdentityRepository.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete linked identities: %w", err)
+	}
+
+	if err := a.listService.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete lists: %w", err)
+	}
+
+	if err := a.dataExportService.DeleteByUserId(ctx, userId); err != nil {
+		return fmt.Errorf("failed to delete data exports: %w", err)
+	}
+
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	for _, url := range []string{user.ImageUrl, user.BannerUrl} {
+		if key, ok := a.storage.Key(url); ok {
+			if err := a.storage.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to delete profile images: %w", err)
+			}
+		}
+	}
+
+	return a.userRepository.DeleteUser(ctx, userId)
+}
+
+// revokeCredentials invalidates every way the user could still call the API:
+// refresh tokens, personal access tokens and tokens granted to OAuth apps.
+func (a *accountService) revokeCredentials(ctx context.Context, userId string) error {
+	if err := a.tokenRepository.DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	if err := a.personalAccessTokenRepository.DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	if err := a.oauthGrantRepository.DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	return a.oauthTokenRepository.DeleteByUserId(ctx, userId)
+}
+
+func NewAccountService(
+	config *config.Config,
+	userRepository repository.UserRepository,
+	followRepository repository.FollowRepository,
+	followRequestRepository repository.FollowRequestRepository,
+	blockRepository repository.BlockRepository,
+	muteRepository repository.MuteRepository,
+	postRepository repository.PostRepository,
+	commentRepository repository.CommentRepository,
+	messageRepository repository.MessageRepository,
+	unreadMessageRepository repository.UnreadMessageRepository,
+	notificationRepository repository.NotificationRepository,
+	tokenRepository repository.TokenRepository,
+	personalAccessTokenRepository repository.PersonalAccessTokenRepository,
+	oauthClientRepository repository.OAuthClientRepository,
+	oauthGrantRepository repository.OAuthGrantRepository,
+	oauthTokenRepository repository.OAuthTokenRepository,
+	userIdentityRepository repository.UserIdentityRepository,
+	dataExportService DataExportService,
+	listService ListService,
+	mediaService MediaService,
+	storage storage.Storage,
+) AccountService {
+	return &accountService{
+		config:                        config,
+		userRepository:                userRepository,
+		followRepository:              followRepository,
+		followRequestRepository:       followRequestRepository,
+		blockRepository:               blockRepository,
+		muteRepository:                muteRepository,
+		postRepository:                postRepository,
+		commentRepository:             commentRepository,
+		messageRepository:             messageRepository,
+		unreadMessageRepository:       unreadMessageRepository,
+		notificationRepository:        notificationRepository,
+		tokenRepository:               tokenRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		oauthClientRepository:         oauthClientRepository,
+		oauthGrantRepository:          oauthGrantRepository,
+		oauthTokenRepository:          oauthTokenRepository,
+		userIdentityRepository:        userIdentityRepository,
+		dataExportService:             dataExportService,
+		listService:                   listService,
+		mediaService:                  mediaService,
+		storage:                       storage,
+	}
+}
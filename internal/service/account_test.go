@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestAccountServiceIsActive(t *testing.T) {
+	deactivatedAt := time.Now().Add(-time.Hour)
+	service := &accountService{userRepository: newFakeUserRepository(
+		&domain.User{Id: "1"},
+		&domain.User{Id: "2", DeactivatedAt: &deactivatedAt},
+	)}
+
+	tests := []struct {
+		name   string
+		userId string
+		want   bool
+	}{
+		{name: "active user", userId: "1", want: true},
+		{name: "deactivated user", userId: "2", want: false},
+		{name: "purged user", userId: "3", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, err := service.IsActive(context.Background(), tt.userId)
+			if err != nil {
+				t.Fatalf("is active: %v", err)
+			}
+			if active != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, active)
+			}
+		})
+	}
+}
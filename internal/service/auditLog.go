@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"io"
+	"time"
+)
+
+const (
+	AuditExportFormatJSON = "json"
+	AuditExportFormatCSV  = "csv"
+)
+
+type AuditLogService interface {
+	Record(ctx context.Context, event *domain.AuditEvent)
+	GetUserEvents(ctx context.Context, userId string, page, limit int) ([]*dto.AuditEventResp, int64, error)
+	QueryEvents(ctx context.Context, filter *domain.AuditLogFilter, page, limit int) ([]*dto.AuditEventResp, int64, error)
+	ExportEvents(ctx context.Context, filter *domain.AuditLogFilter, format string, w io.Writer) (int, error)
+}
+
+type auditLogService struct {
+	auditLogRepository repository.AuditLogRepository
+}
+
+// Record stamps the event with the client IP, user agent and time taken from the
+// request context. A failing audit write never fails the action being audited.
+func (a *auditLogService) Record(ctx context.Context, event *domain.AuditEvent) {
+	event.IP = utils.ClientIPFromContext(ctx)
+	event.UserAgent = utils.UserAgentFromContext(ctx)
+	event.CreatedAt = time.Now()
+	_ = a.auditLogRepository.Create(context.WithoutCancel(ctx), event)
+}
+
+func (a *auditLogService) GetUserEvents(ctx context.Context, userId string, page, limit int) ([]*dto.AuditEventResp, int64, error) {
+	return a.QueryEvents(ctx, &domain.AuditLogFilter{UserId: userId}, page, limit)
+}
+
+func (a *auditLogService) QueryEvents(ctx context.Context, filter *domain.AuditLogFilter, page, limit int) ([]*dto.AuditEventResp, int64, error) {
+	events, total, err := a.auditLogRepository.Query(ctx, filter, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]*dto.AuditEventResp, len(events))
+	for i, event := range events {
+		resp[i] = a.toAuditEventResp(event)
+	}
+
+	return resp, total, nil
+}
+
+// ExportEvents writes every matching event to w as JSON lines or CSV and returns how many were written.
+func (a *auditLogService) ExportEvents(ctx context.Context, filter *domain.AuditLogFilter, format string, w io.Writer) (int, error) {
+	var (
+		count int
+		write func(*dto.AuditEventResp) error
+		flush = func() error { return nil }
+	)
+
+	switch format {
+	case AuditExportFormatJSON:
+		encoder := json.NewEncoder(w)
+		write = func(event *dto.AuditEventResp) error {
+			return encoder.Encode(event)
+		}
+	case AuditExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"id", "created_at", "event", "outcome", "user_id", "email", "ip", "user_agent", "details"}); err != nil {
+			return 0, err
+		}
+		write = func(event *dto.AuditEventResp) error {
+			return writer.Write([]string{
+				event.Id,
+				event.CreatedAt.UTC().Format(time.RFC3339),
+				event.Event,
+				event.Outcome,
+				event.UserId,
+				event.Email,
+				event.IP,
+				event.UserAgent,
+				event.Details,
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	default:
+		return 0, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	err := a.auditLogRepository.Each(ctx, filter, func(event *domain.AuditEvent) error {
+		count++
+		return write(a.toAuditEventResp(event))
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, flush()
+}
+
+func (a *auditLogService) toAuditEventResp(input *domain.AuditEvent) *dto.AuditEventResp {
+	return &dto.AuditEventResp{
+		Id:        input.Id,
+		UserId:    input.UserId,
+		Email:     input.Email,
+		Event:     input.Event,
+		Outcome:   input.Outcome,
+		IP:        input.IP,
+		UserAgent: input.UserAgent,
+		Details:   input.Details,
+		CreatedAt: input.CreatedAt,
+	}
+}
+
+func NewAuditLogService(auditLogRepository repository.AuditLogRepository) AuditLogService {
+	return &auditLogService{
+		auditLogRepository: auditLogRepository,
+	}
+}
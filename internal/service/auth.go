@@ -9,6 +9,8 @@ import (
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
 	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,12 +19,34 @@ type AuthService interface {
 	Login(ctx context.Context, input *dto.LoginReq) (*dto.AuthResp, error)
 	RefreshToken(ctx context.Context, input *dto.RefreshTokenReq) (*dto.AuthResp, error)
 	Logout(ctx context.Context, input *dto.RefreshTokenReq) error
+	CreateSession(ctx context.Context, user *domain.User, method string) (*dto.AuthResp, error)
 }
 
+// LoginThrottledError is returned while an account or IP has to wait before trying to log in again.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return repository.ErrTooManyAttempts
+}
+
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := utils.HashPassword("x-gopher-timing-equalizer")
+	return hash
+})
+
 type authService struct {
-	config          *config.Config
-	userRepository  repository.UserRepository
-	tokenRepository repository.TokenRepository
+	config                 *config.Config
+	userRepository         repository.UserRepository
+	tokenRepository        repository.TokenRepository
+	loginAttemptRepository repository.LoginAttemptRepository
+	notificationRepository repository.NotificationRepository
+	auditLogService        AuditLogService
 }
 
 func (a *authService) Register(ctx context.Context, input *dto.RegisterReq) (*dto.AuthResp, error) {
@@ -35,6 +59,12 @@ func (a *authService) Register(ctx context.Context, input *dto.RegisterReq) (*dt
 		return nil, repository.ErrDuplicateEmail
 	}
 
+	if _, err := a.userRepository.GetUserByHandle(ctx, input.Handle); err == nil {
+		return nil, repository.ErrDuplicateHandle
+	} else if !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get user by handle: %w", err)
+	}
+
 	user, err := a.toUser(input)
 	if err != nil {
 		return nil, err
@@ -44,35 +74,58 @@ func (a *authService) Register(ctx context.Context, input *dto.RegisterReq) (*dt
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	a.audit(ctx, user.Id, user.Email, domain.AuditEventSignup, domain.AuditOutcomeSuccess, "password")
+
 	return a.generateAuthResp(ctx, user)
 }
 
 func (a *authService) Login(ctx context.Context, input *dto.LoginReq) (*dto.AuthResp, error) {
+	accountKey := "account:" + strings.ToLower(input.Email)
+	ipKey := "ip:" + utils.ClientIPFromContext(ctx)
+
+	if err := a.checkLoginThrottle(ctx, accountKey, ipKey); err != nil {
+		a.audit(ctx, "", input.Email, domain.AuditEventLogin, domain.AuditOutcomeFailure, "throttled")
+		return nil, err
+	}
+
 	user, err := a.userRepository.GetUserByEmail(ctx, input.Email)
-	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrRecordNotFound):
-			return nil, repository.ErrRecordNotFound
-		default:
-			return nil, fmt.Errorf("failed to get user by email: %w", err)
-		}
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
-	if !utils.CheckPasswordHash(user.Password, input.Password) {
-		return nil, fmt.Errorf("invalid credentials")
+	// Unknown emails still pay for a bcrypt comparison so they cannot be told apart by timing.
+	hash, known := dummyPasswordHash(), false
+	if user != nil && user.Password != "" {
+		hash, known = user.Password, true
 	}
 
-	return a.generateAuthResp(ctx, user)
+	if matched := utils.CheckPasswordHash(hash, input.Password); !matched || !known {
+		a.recordLoginFailure(ctx, user, accountKey, ipKey)
+		var userId string
+		if user != nil {
+			userId = user.Id
+		}
+		a.audit(ctx, userId, input.Email, domain.AuditEventLogin, domain.AuditOutcomeFailure, "invalid credentials")
+		return nil, repository.ErrInvalidCredentials
+	}
+
+	_ = a.loginAttemptRepository.Reset(ctx, accountKey)
+
+	return a.CreateSession(ctx, user, "password")
 }
 
 func (a *authService) RefreshToken(ctx context.Context, input *dto.RefreshTokenReq) (*dto.AuthResp, error) {
-	claim, err := utils.ValidateToken(input.RefreshToken, a.config.JWT.Secret)
+	claim, err := utils.ValidateToken(a.config, input.RefreshToken, utils.TokenTypeRefresh)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		a.audit(ctx, "", "", domain.AuditEventRefresh, domain.AuditOutcomeFailure, "invalid token")
+		return nil, fmt.Errorf("%w: %w", utils.ErrInvalidToken, err)
 	}
 
 	refreshToken, err := a.tokenRepository.GetValidRefreshToken(ctx, input.RefreshToken)
 	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			a.audit(ctx, claim.UserId, claim.Email, domain.AuditEventRefresh, domain.AuditOutcomeFailure, "token revoked or reused")
+		}
 		return nil, fmt.Errorf("failed to get refresh token: %w", err)
 	}
 
@@ -85,11 +138,118 @@ func (a *authService) RefreshToken(ctx context.Context, input *dto.RefreshTokenR
 		return nil, fmt.Errorf("failed to delete refresh token: %w", err)
 	}
 
+	a.audit(ctx, user.Id, user.Email, domain.AuditEventRefresh, domain.AuditOutcomeSuccess, "")
+
 	return a.generateAuthResp(ctx, user)
 }
 
 func (a *authService) Logout(ctx context.Context, input *dto.RefreshTokenReq) error {
-	return a.tokenRepository.DeleteRefreshToken(ctx, input.RefreshToken)
+	if err := a.tokenRepository.DeleteRefreshToken(ctx, input.RefreshToken); err != nil {
+		return err
+	}
+
+	// Logging out does not require a valid token; its claims are only read for the audit entry.
+	if claim, err := utils.ValidateToken(a.config, input.RefreshToken, utils.TokenTypeRefresh); err == nil {
+		a.audit(ctx, claim.UserId, claim.Email, domain.AuditEventLogout, domain.AuditOutcomeSuccess, "")
+	}
+
+	return nil
+}
+
+// CreateSession issues tokens for a user that was already authenticated by the given method,
+// e.g. "password", "magic_link" or an identity provider, and records the login.
+func (a *authService) CreateSession(ctx context.Context, user *domain.User, method string) (*dto.AuthResp, error) {
+	// Logging in during the grace period restores a deactivated account.
+	if user.IsDeactivated() {
+		if err := a.userRepository.Reactivate(ctx, user.Id); err != nil {
+			return nil, fmt.Errorf("failed to reactivate user: %w", err)
+		}
+		user.DeactivatedAt = nil
+	}
+
+	resp, err := a.generateAuthResp(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	a.audit(ctx, user.Id, user.Email, domain.AuditEventLogin, domain.AuditOutcomeSuccess, method)
+
+	return resp, nil
+}
+
+func (a *authService) audit(ctx context.Context, userId, email, event, outcome, details string) {
+	a.auditLogService.Record(ctx, &domain.AuditEvent{
+		UserId:  userId,
+		Email:   email,
+		Event:   event,
+		Outcome: outcome,
+		Details: details,
+	})
+}
+
+// checkLoginThrottle fails open when the attempt store is unavailable so an outage does not lock everyone out.
+func (a *authService) checkLoginThrottle(ctx context.Context, keys ...string) error {
+	var retryAfter time.Duration
+	for _, key := range keys {
+		attempts, err := a.loginAttemptRepository.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		retryAfter = max(retryAfter, time.Until(attempts.BlockedUntil))
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (a *authService) recordLoginFailure(ctx context.Context, user *domain.User, accountKey, ipKey string) {
+	now := time.Now()
+	cfg := a.config.Login
+
+	if failures, err := a.loginAttemptRepository.RecordFailure(ctx, accountKey, cfg.Window); err == nil {
+		switch {
+		case failures >= cfg.MaxAttempts:
+			_ = a.loginAttemptRepository.Block(ctx, accountKey, now.Add(cfg.LockoutDuration))
+			if failures == cfg.MaxAttempts && user != nil {
+				a.notifyLockout(ctx, user)
+			}
+		case failures > cfg.FreeAttempts:
+			_ = a.loginAttemptRepository.Block(ctx, accountKey, now.Add(a.loginDelay(failures)))
+		}
+	}
+
+	if failures, err := a.loginAttemptRepository.RecordFailure(ctx, ipKey, cfg.Window); err == nil {
+		switch {
+		case failures >= cfg.IPMaxAttempts:
+			_ = a.loginAttemptRepository.Block(ctx, ipKey, now.Add(cfg.LockoutDuration))
+		case failures > cfg.FreeAttempts:
+			_ = a.loginAttemptRepository.Block(ctx, ipKey, now.Add(a.loginDelay(failures)))
+		}
+	}
+}
+
+// loginDelay doubles the wait for every failure past the free attempts, up to MaxDelay.
+func (a *authService) loginDelay(failures int) time.Duration {
+	exponent := min(failures-a.config.Login.FreeAttempts-1, 16)
+	return min(a.config.Login.BaseDelay<<exponent, a.config.Login.MaxDelay)
+}
+
+func (a *authService) notifyLockout(ctx context.Context, user *domain.User) {
+	notif := &domain.Notification{
+		SenderId:   user.Id,
+		ReceiverId: user.Id,
+		TargetId:   user.Id,
+		Details:    "Your account was temporarily locked after too many failed login attempts. If this wasn't you, consider changing your password.",
+		IsRead:     false,
+		CreatedAt:  time.Now(),
+		NotificationUser: domain.NotificationUser{
+			Name: "X-Gopher Security",
+		},
+	}
+	_ = a.notificationRepository.Create(ctx, notif)
 }
 
 func (a *authService) toUser(input *dto.RegisterReq) (*domain.User, error) {
@@ -102,14 +262,14 @@ func (a *authService) toUser(input *dto.RegisterReq) (*domain.User, error) {
 		FirstName: input.FirstName,
 		LastName:  input.LastName,
 		Email:     input.Email,
+		Handle:    input.Handle,
 		Password:  hashedPassword,
-		Followers: make([]string, 0),
-		Following: make([]string, 0),
+		Role:      domain.RoleUser,
 	}, nil
 }
 
 func (a *authService) generateAuthResp(ctx context.Context, user *domain.User) (*dto.AuthResp, error) {
-	accessToken, refreshToken, err := utils.GenerateToken(a.config, user.Id, user.Email)
+	accessToken, refreshToken, err := utils.GenerateToken(a.config, user.Id, user.Email, user.Role, domain.PermissionsFor(user.Role))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -127,23 +287,30 @@ func (a *authService) generateAuthResp(ctx context.Context, user *domain.User) (
 
 	return &dto.AuthResp{
 		User: dto.UserResp{
-			Id:        user.Id,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			ImageUrl:  user.ImageUrl,
-			Followers: user.Followers,
-			Following: user.Following,
+			Id:             user.Id,
+			FirstName:      user.FirstName,
+			LastName:       user.LastName,
+			Email:          user.Email,
+			Handle:         user.Handle,
+			ImageUrl:       user.ImageUrl,
+			BannerUrl:      user.BannerUrl,
+			Role:           user.Role,
+			IsPrivate:      user.IsPrivate,
+			FollowersCount: user.FollowersCount,
+			FollowingCount: user.FollowingCount,
 		},
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func NewAuthService(config *config.Config, userRepository repository.UserRepository, tokenRepository repository.TokenRepository) AuthService {
+func NewAuthService(config *config.Config, userRepository repository.UserRepository, tokenRepository repository.TokenRepository, loginAttemptRepository repository.LoginAttemptRepository, notificationRepository repository.NotificationRepository, auditLogService AuditLogService) AuthService {
 	return &authService{
-		config:          config,
-		userRepository:  userRepository,
-		tokenRepository: tokenRepository,
+		config:                 config,
+		userRepository:         userRepository,
+		tokenRepository:        tokenRepository,
+		loginAttemptRepository: loginAttemptRepository,
+		notificationRepository: notificationRepository,
+		auditLogService:        auditLogService,
 	}
 }
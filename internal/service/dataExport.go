@@ -0,0 +1,631 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+type DataExportService interface {
+	RequestExport(ctx context.Context, userId string) (*dto.DataExportResp, error)
+	GetExports(ctx context.Context, userId string) ([]*dto.DataExportResp, error)
+	OpenArchive(ctx context.Context, id, expires, signature string) (*os.File, *domain.DataExport, error)
+	DeleteExpired(ctx context.Context) (int, error)
+	FailStale(ctx context.Context) (int, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type dataExportService struct {
+	config                        *config.Config
+	dataExportRepository          repository.DataExportRepository
+	userRepository                repository.UserRepository
+	followRepository              repository.FollowRepository
+	postRepository                repository.PostRepository
+	commentRepository             repository.CommentRepository
+	messageRepository             repository.MessageRepository
+	notificationRepository        repository.NotificationRepository
+	tokenRepository               repository.TokenRepository
+	personalAccessTokenRepository repository.PersonalAccessTokenRepository
+	oauthGrantRepository          repository.OAuthGrantRepository
+	oauthClientRepository         repository.OAuthClientRepository
+	userIdentityRepository        repository.UserIdentityRepository
+	auditLogRepository            repository.AuditLogRepository
+}
+
+// exportTimedOut is the error recorded on an export that stayed pending past
+// the build timeout, most likely because the process building it stopped.
+const exportTimedOut = "export did not finish in time"
+
+// archiveFile is one JSON file in the export archive.
+type archiveFile struct {
+	Name        string
+	Description string
+	Count       int
+	Data        any
+}
+
+var exportIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>X-Gopher data export</title>
+</head>
+<body>
+<h1>Your X-Gopher data</h1>
+<p>Exported for {{.Name}} ({{.Email}}) on {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}.</p>
+<table>
+<thead><tr><th>File</th><th>Contents</th><th>Entries</th></tr></thead>
+<tbody>
+{{range .Files}}<tr><td><a href="{{.Name}}">{{.Name}}</a></td><td>{{.Description}}</td><td>{{.Count}}</td></tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// RequestExport queues an export and builds it in the background. Only one
+// export per user can be in progress at a time; one pending for longer than
+// the build timeout is marked failed and no longer counts.
+func (d *dataExportService) RequestExport(ctx context.Context, userId string) (*dto.DataExportResp, error) {
+	exports, err := d.dataExportRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exports: %w", err)
+	}
+
+	now := time.Now()
+	for _, export := range exports {
+		if export.Status != domain.DataExportStatusPending {
+			continue
+		}
+		if now.Sub(export.CreatedAt) < d.config.DataExport.BuildTimeout {
+			return nil, repository.ErrTooManyAttempts
+		}
+		if err := d.dataExportRepository.MarkFailed(ctx, export.Id, exportTimedOut, now); err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to fail stale export: %w", err)
+		}
+	}
+
+	export := &domain.DataExport{
+		UserId:    userId,
+		Status:    domain.DataExportStatusPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(d.config.DataExport.Retention),
+	}
+
+	if err := d.dataExportRepository.Create(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	go d.build(context.WithoutCancel(ctx), export)
+
+	return d.toDataExportResp(export), nil
+}
+
+func (d *dataExportService) GetExports(ctx context.Context, userId string) ([]*dto.DataExportResp, error) {
+	exports, err := d.dataExportRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dto.DataExportResp, 0, len(exports))
+	for _, export := range exports {
+		if export.IsExpired() {
+			continue
+		}
+		resp = append(resp, d.toDataExportResp(export))
+	}
+
+	return resp, nil
+}
+
+// OpenArchive checks the signed download link and opens the archive it points to.
+// The caller must close the returned file.
+func (d *dataExportService) OpenArchive(ctx context.Context, id, expires, signature string) (*os.File, *domain.DataExport, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, nil, utils.ErrInvalidToken
+	}
+
+	if !utils.VerifySignature(d.signingKey(), d.linkMessage(id, expiresAt), signature) {
+		return nil, nil, utils.ErrInvalidToken
+	}
+
+	export, err := d.dataExportRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if export.Status != domain.DataExportStatusReady || export.IsExpired() {
+		return nil, nil, repository.ErrRecordNotFound
+	}
+
+	file, err := os.Open(export.FilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, repository.ErrRecordNotFound
+		}
+		return nil, nil, err
+	}
+
+	return file, export, nil
+}
+
+func (d *dataExportService) DeleteExpired(ctx context.Context) (int, error) {
+	exports, err := d.dataExportRepository.GetExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return d.delete(ctx, exports)
+}
+
+// FailStale marks exports pending for longer than the build timeout as failed.
+func (d *dataExportService) FailStale(ctx context.Context) (int, error) {
+	now := time.Now()
+	failed, err := d.dataExportRepository.FailStale(ctx, now.Add(-d.config.DataExport.BuildTimeout), exportTimedOut, now)
+	return int(failed), err
+}
+
+func (d *dataExportService) DeleteByUserId(ctx context.Context, userId string) error {
+	exports, err := d.dataExportRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.delete(ctx, exports)
+	return err
+}
+
+func (d *dataExportService) delete(ctx context.Context, exports []*domain.DataExport) (int, error) {
+	deleted := 0
+	for _, export := range exports {
+		if export.FilePath != "" {
+			if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return deleted, fmt.Errorf("failed to remove archive %s: %w", export.Id, err)
+			}
+		}
+		if err := d.dataExportRepository.Delete(ctx, export.Id); err != nil {
+			return deleted, fmt.Errorf("failed to delete export %s: %w", export.Id, err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// build gives up after the build timeout, when the export may already have
+// been marked failed as stale.
+func (d *dataExportService) build(ctx context.Context, export *domain.DataExport) {
+	buildCtx, cancel := context.WithTimeout(ctx, d.config.DataExport.BuildTimeout)
+	defer cancel()
+
+	path, size, err := d.writeArchive(buildCtx, export)
+	if err != nil {
+		_ = d.dataExportRepository.MarkFailed(ctx, export.Id, err.Error(), time.Now())
+		return
+	}
+
+	if err := d.dataExportRepository.MarkReady(ctx, export.Id, path, size, time.Now()); err != nil {
+		_ = os.Remove(path)
+	}
+}
+
+// writeArchive writes the ZIP to a temporary file first so a half-written
+// archive is never served, then moves it into place.
+func (d *dataExportService) writeArchive(ctx context.Context, export *domain.DataExport) (string, int64, error) {
+	user, err := d.userRepository.GetUserById(ctx, export.UserId)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	files, err := d.collect(ctx, user)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := os.MkdirAll(d.config.DataExport.Dir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(d.config.DataExport.Dir, export.Id+"-*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	for _, file := range files {
+		w, err := archive.Create(file.Name)
+		if err != nil {
+			return "", 0, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.Data); err != nil {
+			return "", 0, fmt.Errorf("failed to write %s: %w", file.Name, err)
+		}
+	}
+
+	index, err := archive.Create("index.html")
+	if err != nil {
+		return "", 0, err
+	}
+	if err := exportIndexTemplate.Execute(index, map[string]any{
+		"Name":        user.FirstName + " " + user.LastName,
+		"Email":       user.Email,
+		"GeneratedAt": time.Now().UTC(),
+		"Files":       files,
+	}); err != nil {
+		return "", 0, fmt.Errorf("failed to write index: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", 0, err
+	}
+
+	size, err := tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	path := filepath.Join(d.config.DataExport.Dir, export.Id+".zip")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	return path, size, nil
+}
+
+func (d *dataExportService) collect(ctx context.Context, user *domain.User) ([]*archiveFile, error) {
+	posts, err := d.postRepository.GetPostsByCreator(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	exportedPosts := make([]*dto.PostResp, len(posts))
+	for i, post := range posts {
+		exportedPosts[i] = &dto.PostResp{
+			Id:          post.Id,
+			Creator:     post.Creator,
+			Kind:        post.Kind,
+			OriginalId:  post.OriginalId,
+			Title:       post.Title,
+			Message:     post.Message,
+			FirstName:   post.FirstName,
+			LastName:    post.LastName,
+			Media:       toAttachmentResps(post.Media),
+			Likes:       post.Likes,
+			Comments:    post.Comments,
+			RepostCount: post.RepostCount,
+			QuoteCount:  post.QuoteCount,
+			CreatedAt:   post.CreatedAt,
+		}
+	}
+
+	comments, err := d.commentRepository.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+
+	exportedComments := make([]dto.ExportedComment, len(comments))
+	for i, comment := range comments {
+		exportedComments[i] = dto.ExportedComment{
+			Id:        comment.Id,
+			PostId:    comment.PostId,
+			ParentId:  comment.ParentId,
+			Value:     comment.Value,
+			EditedAt:  comment.EditedAt,
+			CreatedAt: comment.CreatedAt,
+		}
+	}
+
+	liked, err := d.postRepository.GetLikedByUser(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get likes: %w", err)
+	}
+
+	likes := make([]dto.ExportedLike, len(liked))
+	for i, post := range liked {
+		likes[i] = dto.ExportedLike{
+			PostId:      post.Id,
+			PostTitle:   post.Title,
+			PostCreator: post.Creator,
+		}
+	}
+
+	follows, err := d.collectFollows(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := d.messageRepository.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	exportedMessages := make([]dto.ExportedMessage, len(messages))
+	for i, message := range messages {
+		exported := dto.ExportedMessage{Id: message.Id, Direction: "sent", Counterpart: message.Receiver, Content: message.Content}
+		if message.Sender != user.Id {
+			exported.Direction, exported.Counterpart = "received", message.Sender
+		}
+		exportedMessages[i] = exported
+	}
+
+	notifications, err := d.notificationRepository.GetByUserId(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	exportedNotifications := make([]dto.ExportedNotification, len(notifications))
+	for i, notification := range notifications {
+		exportedNotifications[i] = dto.ExportedNotification{
+			Id:        notification.Id,
+			Details:   notification.Details,
+			SenderId:  notification.SenderId,
+			TargetId:  notification.TargetId,
+			IsRead:    notification.IsRead,
+			CreatedAt: notification.CreatedAt,
+		}
+	}
+
+	sessions, sessionCount, err := d.collectSessions(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var securityLog []*dto.AuditEventResp
+	if err := d.auditLogRepository.Each(ctx, &domain.AuditLogFilter{UserId: user.Id}, func(event *domain.AuditEvent) error {
+		securityLog = append(securityLog, &dto.AuditEventResp{
+			Id:        event.Id,
+			Event:     event.Event,
+			Outcome:   event.Outcome,
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			Details:   event.Details,
+			CreatedAt: event.CreatedAt,
+		})
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to get security log: %w", err)
+	}
+
+	profile := &dto.UserResp{
+		Id:             user.Id,
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		Email:          user.Email,
+		Handle:         user.Handle,
+		ImageUrl:       user.ImageUrl,
+		BannerUrl:      user.BannerUrl,
+		Bio:            user.Bio,
+		Role:           user.Role,
+		IsPrivate:      user.IsPrivate,
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+	}
+
+	return []*archiveFile{
+		{Name: "profile.json", Description: "Your profile", Count: 1, Data: profile},
+		{Name: "posts.json", Description: "Posts you created", Count: len(exportedPosts), Data: exportedPosts},
+		{Name: "comments.json", Description: "Comments you wrote", Count: len(exportedComments), Data: exportedComments},
+		{Name: "likes.json", Description: "Posts you liked", Count: len(likes), Data: likes},
+		{Name: "follows.json", Description: "Accounts you follow and that follow you", Count: len(follows.Followers) + len(follows.Following), Data: follows},
+		{Name: "messages.json", Description: "Messages you sent and received", Count: len(exportedMessages), Data: exportedMessages},
+		{Name: "notifications.json", Description: "Notifications you received", Count: len(exportedNotifications), Data: exportedNotifications},
+		{Name: "sessions.json", Description: "Active sessions, access tokens, authorized apps and linked accounts", Count: sessionCount, Data: sessions},
+		{Name: "security_log.json", Description: "Sign-ins and other security events", Count: len(securityLog), Data: securityLog},
+	}, nil
+}
+
+func (d *dataExportService) collectFollows(ctx context.Context, user *domain.User) (*dto.ExportedFollows, error) {
+	refs := func(ids []string) ([]dto.ExportedUserRef, error) {
+		users, err := d.userRepository.GetUsersByIds(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]dto.ExportedUserRef, len(users))
+		for i, u := range users {
+			result[i] = dto.ExportedUserRef{Id: u.Id, FirstName: u.FirstName, LastName: u.LastName}
+		}
+		return result, nil
+	}
+
+	followerIds, err := d.followRepository.GetFollowerIds(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+
+	followingIds, err := d.followRepository.GetFollowingIds(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following: %w", err)
+	}
+
+	followers, err := refs(followerIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followers: %w", err)
+	}
+
+	following, err := refs(followingIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following: %w", err)
+	}
+
+	return &dto.ExportedFollows{Followers: followers, Following: following}, nil
+}
+
+func (d *dataExportService) collectSessions(ctx context.Context, userId string) (*dto.ExportedSessions, int, error) {
+	refreshTokens, err := d.tokenRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	sessions := &dto.ExportedSessions{
+		RefreshSessions: make([]dto.ExportedRefreshSession, len(refreshTokens)),
+	}
+	for i, token := range refreshTokens {
+		sessions.RefreshSessions[i] = dto.ExportedRefreshSession{Id: token.Id, CreatedAt: token.CreatedAt, ExpiresAt: token.ExpiresAt}
+	}
+
+	pats, err := d.personalAccessTokenRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get personal access tokens: %w", err)
+	}
+
+	sessions.PersonalAccessTokens = make([]*dto.PersonalAccessTokenResp, len(pats))
+	for i, pat := range pats {
+		sessions.PersonalAccessTokens[i] = &dto.PersonalAccessTokenResp{
+			Id:         pat.Id,
+			Name:       pat.Name,
+			Scopes:     pat.Scopes,
+			ExpiresAt:  pat.ExpiresAt,
+			LastUsedAt: pat.LastUsedAt,
+			CreatedAt:  pat.CreatedAt,
+		}
+	}
+
+	grants, err := d.oauthGrantRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get oauth grants: %w", err)
+	}
+
+	clientIds := make([]string, len(grants))
+	for i, grant := range grants {
+		clientIds[i] = grant.ClientId
+	}
+
+	clients, err := d.oauthClientRepository.GetByIds(ctx, clientIds)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get oauth clients: %w", err)
+	}
+
+	names := make(map[string]string, len(clients))
+	for _, client := range clients {
+		names[client.Id] = client.Name
+	}
+
+	sessions.OAuthAuthorizations = make([]*dto.OAuthAuthorizationResp, len(grants))
+	for i, grant := range grants {
+		sessions.OAuthAuthorizations[i] = &dto.OAuthAuthorizationResp{
+			ClientId:   grant.ClientId,
+			ClientName: names[grant.ClientId],
+			Scopes:     grant.Scopes,
+			CreatedAt:  grant.CreatedAt,
+			UpdatedAt:  grant.UpdatedAt,
+		}
+	}
+
+	identities, err := d.userIdentityRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get linked identities: %w", err)
+	}
+
+	sessions.LinkedIdentities = make([]dto.ExportedIdentity, len(identities))
+	for i, identity := range identities {
+		sessions.LinkedIdentities[i] = dto.ExportedIdentity{Provider: identity.Provider, Email: identity.Email, LinkedAt: identity.CreatedAt}
+	}
+
+	count := len(refreshTokens) + len(pats) + len(grants) + len(identities)
+	return sessions, count, nil
+}
+
+func (d *dataExportService) signingKey() string {
+	if d.config.DataExport.SigningKey != "" {
+		return d.config.DataExport.SigningKey
+	}
+	return d.config.JWT.Secret
+}
+
+func (d *dataExportService) linkMessage(id string, expiresAt int64) string {
+	return "data-export:" + id + ":" + strconv.FormatInt(expiresAt, 10)
+}
+
+// downloadURL signs a link to the archive. The link expires after LinkTTL or
+// with the archive, whichever comes first.
+func (d *dataExportService) downloadURL(export *domain.DataExport) (string, time.Time) {
+	expiresAt := time.Now().Add(d.config.DataExport.LinkTTL)
+	if export.ExpiresAt.Before(expiresAt) {
+		expiresAt = export.ExpiresAt
+	}
+	expiresAt = expiresAt.Truncate(time.Second)
+
+	link := withQuery(d.config.DataExport.DownloadURL+"/"+url.PathEscape(export.Id)+"/download", url.Values{
+		"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"signature": {utils.Sign(d.signingKey(), d.linkMessage(export.Id, expiresAt.Unix()))},
+	})
+
+	return link, expiresAt
+}
+
+func (d *dataExportService) toDataExportResp(input *domain.DataExport) *dto.DataExportResp {
+	resp := &dto.DataExportResp{
+		Id:          input.Id,
+		Status:      input.Status,
+		Size:        input.Size,
+		Error:       input.Error,
+		CreatedAt:   input.CreatedAt,
+		CompletedAt: input.CompletedAt,
+		ExpiresAt:   input.ExpiresAt,
+	}
+
+	if input.Status == domain.DataExportStatusReady {
+		link, expiresAt := d.downloadURL(input)
+		resp.DownloadURL = link
+		resp.LinkExpireAt = &expiresAt
+	}
+
+	return resp
+}
+
+func NewDataExportService(
+	config *config.Config,
+	dataExportRepository repository.DataExportRepository,
+	userRepository repository.UserRepository,
+	followRepository repository.FollowRepository,
+	postRepository repository.PostRepository,
+	commentRepository repository.CommentRepository,
+	messageRepository repository.MessageRepository,
+	notificationRepository repository.NotificationRepository,
+	tokenRepository repository.TokenRepository,
+	personalAccessTokenRepository repository.PersonalAccessTokenRepository,
+	oauthGrantRepository repository.OAuthGrantRepository,
+	oauthClientRepository repository.OAuthClientRepository,
+	userIdentityRepository repository.UserIdentityRepository,
+	auditLogRepository repository.AuditLogRepository,
+) DataExportService {
+	return &dataExportService{
+		config:                        config,
+		dataExportRepository:          dataExportRepository,
+		userRepository:                userRepository,
+		followRepository:              followRepository,
+		postRepository:                postRepository,
+		commentRepository:             commentRepository,
+		messageRepository:             messageRepository,
+		notificationRepository:        notificationRepository,
+		tokenRepository:               tokenRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		oauthGrantRepository:          oauthGrantRepository,
+		oauthClientRepository:         oauthClientRepository,
+		userIdentityRepository:        userIdentityRepository,
+		auditLogRepository:            auditLogRepository,
+	}
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDataExportService writes archives to a temporary directory and knows
+// users "1" (Ada) and "2" (Grace), with nothing else stored yet.
+func newTestDataExportService(t *testing.T) *dataExportService {
+	return &dataExportService{
+		config: &config.Config{DataExport: config.DataExport{
+			Dir:          t.TempDir(),
+			DownloadURL:  "http://localhost:8080/v1/exports",
+			Retention:    168 * time.Hour,
+			LinkTTL:      24 * time.Hour,
+			SigningKey:   "test-signing-key",
+			BuildTimeout: 30 * time.Minute,
+		}},
+		dataExportRepository: &fakeDataExportRepository{},
+		userRepository: newFakeUserRepository(
+			&domain.User{Id: "1", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"},
+			&domain.User{Id: "2", FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com"},
+		),
+		followRepository:              &fakeFollowRepository{},
+		postRepository:                &fakePostRepository{},
+		commentRepository:             &fakeCommentRepository{},
+		messageRepository:             &fakeMessageRepository{},
+		notificationRepository:        &fakeNotificationRepository{},
+		tokenRepository:               &fakeTokenRepository{},
+		personalAccessTokenRepository: &fakePersonalAccessTokenRepository{},
+		oauthGrantRepository:          &fakeOAuthGrantRepository{},
+		oauthClientRepository:         &fakeOAuthClientRepository{},
+		userIdentityRepository:        &fakeUserIdentityRepository{},
+		auditLogRepository:            &fakeAuditLogRepository{},
+	}
+}
+
+// buildExport builds an export for userId synchronously and returns it ready.
+func buildExport(t *testing.T, service *dataExportService, userId string) *domain.DataExport {
+	t.Helper()
+	ctx := context.Background()
+
+	export := &domain.DataExport{UserId: userId, Status: domain.DataExportStatusPending, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(service.config.DataExport.Retention)}
+	if err := service.dataExportRepository.Create(ctx, export); err != nil {
+		t.Fatalf("create export: %v", err)
+	}
+	service.build(ctx, export)
+
+	built, err := service.dataExportRepository.GetById(ctx, export.Id)
+	if err != nil {
+		t.Fatalf("get export: %v", err)
+	}
+	if built.Status != domain.DataExportStatusReady {
+		t.Fatalf("expected a ready export, got %+v", built)
+	}
+	return built
+}
+
+func TestDataExportRequestExport(t *testing.T) {
+	// Nobody is registered as "3", so the background build of an accepted
+	// request fails straight away without writing an archive.
+	const userId = "3"
+
+	tests := []struct {
+		name     string
+		existing []*domain.DataExport
+		// concurrent is an export another request stores between the check and the insert.
+		concurrent *domain.DataExport
+		wantErr    error
+		// wantFailed lists existing exports expected to end up failed.
+		wantFailed []string
+	}{
+		{name: "first export"},
+		{
+			name: "finished exports don't count",
+			existing: []*domain.DataExport{
+				{Id: "ready", UserId: userId, Status: domain.DataExportStatusReady, CreatedAt: time.Now().Add(-time.Minute)},
+				{Id: "failed", UserId: userId, Status: domain.DataExportStatusFailed, CreatedAt: time.Now().Add(-time.Minute)},
+			},
+		},
+		{
+			name:     "export in progress",
+			existing: []*domain.DataExport{{Id: "pending", UserId: userId, Status: domain.DataExportStatusPending, CreatedAt: time.Now().Add(-time.Minute)}},
+			wantErr:  repository.ErrTooManyAttempts,
+		},
+		{
+			name:     "another user's export in progress",
+			existing: []*domain.DataExport{{Id: "pending", UserId: "4", Status: domain.DataExportStatusPending, CreatedAt: time.Now().Add(-time.Minute)}},
+		},
+		{
+			name:       "export pending past the build timeout",
+			existing:   []*domain.DataExport{{Id: "stale", UserId: userId, Status: domain.DataExportStatusPending, CreatedAt: time.Now().Add(-time.Hour)}},
+			wantFailed: []string{"stale"},
+		},
+		{
+			name:       "concurrent request",
+			concurrent: &domain.DataExport{UserId: userId, Status: domain.DataExportStatusPending, CreatedAt: time.Now()},
+			wantErr:    repository.ErrTooManyAttempts,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestDataExportService(t)
+			ctx := context.Background()
+
+			exports := service.dataExportRepository.(*fakeDataExportRepository)
+			exports.exports = tt.existing
+			if tt.concurrent != nil {
+				exports.beforeCreate = func() {
+					exports.beforeCreate = nil
+					if err := exports.Create(ctx, tt.concurrent); err != nil {
+						t.Errorf("concurrent create: %v", err)
+					}
+				}
+			}
+
+			resp, err := service.RequestExport(ctx, userId)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && resp.Status != domain.DataExportStatusPending {
+				t.Errorf("expected a pending export, got %+v", resp)
+			}
+
+			for _, existing := range tt.existing {
+				stored, err := exports.GetById(ctx, existing.Id)
+				if err != nil {
+					t.Fatalf("get export %s: %v", existing.Id, err)
+				}
+				if failed := slices.Contains(tt.wantFailed, existing.Id); failed && (stored.Status != domain.DataExportStatusFailed || stored.Error != exportTimedOut) {
+					t.Errorf("expected export %s to have timed out, got %+v", existing.Id, stored)
+				} else if !failed && stored.Status != existing.Status {
+					t.Errorf("expected export %s to stay %s, got %s", existing.Id, existing.Status, stored.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestDataExportFailStale(t *testing.T) {
+	service := newTestDataExportService(t)
+	ctx := context.Background()
+
+	exports := service.dataExportRepository.(*fakeDataExportRepository)
+	exports.exports = []*domain.DataExport{
+		{Id: "stale", UserId: "1", Status: domain.DataExportStatusPending, CreatedAt: time.Now().Add(-time.Hour)},
+		{Id: "building", UserId: "2", Status: domain.DataExportStatusPending, CreatedAt: time.Now().Add(-time.Minute)},
+		{Id: "ready", UserId: "2", Status: domain.DataExportStatusReady, CreatedAt: time.Now().Add(-time.Hour)},
+	}
+
+	failed, err := service.FailStale(ctx)
+	if err != nil || failed != 1 {
+		t.Fatalf("expected 1 export failed, got %d (%v)", failed, err)
+	}
+
+	want := map[string]string{"stale": domain.DataExportStatusFailed, "building": domain.DataExportStatusPending, "ready": domain.DataExportStatusReady}
+	for id, status := range want {
+		if stored, _ := exports.GetById(ctx, id); stored.Status != status {
+			t.Errorf("expected export %s to be %s, got %s", id, status, stored.Status)
+		}
+	}
+
+	// A build that finishes after its export timed out leaves it failed.
+	if err := exports.MarkReady(ctx, "stale", "/tmp/stale.zip", 1, time.Now()); !errors.Is(err, repository.ErrRecordNotFound) {
+		t.Errorf("expected a timed out export not to become ready, got %v", err)
+	}
+}
+
+func TestDataExportArchive(t *testing.T) {
+	service := newTestDataExportService(t)
+
+	service.followRepository.(*fakeFollowRepository).follows = map[string][]string{"1": {"2"}, "2": {"1"}}
+	service.postRepository.(*fakePostRepository).posts = map[string]*domain.Post{
+		"p1": {Id: "p1", Creator: "1", Title: "Notes", Message: "On the analytical engine"},
+		"p2": {Id: "p2", Creator: "2", Title: "Bugs", Likes: []string{"1"}},
+	}
+	service.commentRepository.(*fakeCommentRepository).comments = map[string]*domain.Comment{
+		"c1": {Id: "c1", PostId: "p2", UserId: "1", Value: "A moth!"},
+		"c2": {Id: "c2", PostId: "p1", UserId: "2", Value: "Not Ada's"},
+	}
+	service.messageRepository.(*fakeMessageRepository).messages = []*domain.Message{
+		{Id: "m1", Sender: "1", Receiver: "2", Content: "hello"},
+		{Id: "m2", Sender: "2", Receiver: "1", Content: "hi"},
+	}
+	service.notificationRepository.(*fakeNotificationRepository).created = []*domain.Notification{
+		{Id: "n1", ReceiverId: "1", SenderId: "2", Details: "Grace liked your post"},
+	}
+	service.auditLogRepository.(*fakeAuditLogRepository).events = []*domain.AuditEvent{
+		{Id: "e1", UserId: "1", Event: "login", Outcome: "success"},
+		{Id: "e2", UserId: "2", Event: "login", Outcome: "success"},
+	}
+
+	export := buildExport(t, service, "1")
+
+	archive, err := zip.OpenReader(export.FilePath)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer archive.Close()
+
+	files := make(map[string][]byte)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		files[file.Name] = data
+	}
+
+	if info, err := os.Stat(export.FilePath); err != nil || info.Size() != export.Size {
+		t.Errorf("expected a %d byte archive, got %v (%v)", export.Size, info, err)
+	}
+
+	wantNames := []string{"comments.json", "follows.json", "index.html", "likes.json", "messages.json", "notifications.json", "posts.json", "profile.json", "security_log.json", "sessions.json"}
+	if names := slices.Sorted(maps.Keys(files)); !slices.Equal(names, wantNames) {
+		t.Fatalf("expected files %v, got %v", wantNames, names)
+	}
+
+	decode := func(name string, v any) {
+		t.Helper()
+		if err := json.Unmarshal(files[name], v); err != nil {
+			t.Fatalf("decode %s: %v", name, err)
+		}
+	}
+
+	var profile dto.UserResp
+	decode("profile.json", &profile)
+	if profile.Id != "1" || profile.Email != "ada@example.com" {
+		t.Errorf("expected Ada's profile, got %+v", profile)
+	}
+
+	var posts []dto.PostResp
+	decode("posts.json", &posts)
+	if len(posts) != 1 || posts[0].Id != "p1" {
+		t.Errorf("expected only Ada's post, got %+v", posts)
+	}
+
+	var comments []dto.ExportedComment
+	decode("comments.json", &comments)
+	if len(comments) != 1 || comments[0].Id != "c1" {
+		t.Errorf("expected only Ada's comment, got %+v", comments)
+	}
+
+	var likes []dto.ExportedLike
+	decode("likes.json", &likes)
+	if len(likes) != 1 || likes[0].PostId != "p2" || likes[0].PostCreator != "2" {
+		t.Errorf("expected the liked post p2, got %+v", likes)
+	}
+
+	var follows dto.ExportedFollows
+	decode("follows.json", &follows)
+	if len(follows.Followers) != 1 || follows.Followers[0].Id != "2" || len(follows.Following) != 1 || follows.Following[0].FirstName != "Grace" {
+		t.Errorf("expected Grace as follower and followed, got %+v", follows)
+	}
+
+	var messages []dto.ExportedMessage
+	decode("messages.json", &messages)
+	if len(messages) != 2 || messages[0].Direction != "sent" || messages[1].Direction != "received" || messages[1].Counterpart != "2" {
+		t.Errorf("expected one sent and one received message, got %+v", messages)
+	}
+
+	var securityLog []dto.AuditEventResp
+	decode("security_log.json", &securityLog)
+	if len(securityLog) != 1 || securityLog[0].Id != "e1" {
+		t.Errorf("expected only Ada's security events, got %+v", securityLog)
+	}
+
+	index := string(files["index.html"])
+	if !strings.Contains(index, "Ada Lovelace (ada@example.com)") || !strings.Contains(index, `<a href="posts.json">posts.json</a></td><td>Posts you created</td><td>1</td>`) {
+		t.Errorf("expected the index to describe Ada's files, got:\n%s", index)
+	}
+}
+
+func TestDataExportOpenArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		// link changes the signed link's parts before opening.
+		link func(service *dataExportService, id, expires, signature *string)
+		// stored changes the stored export before opening.
+		stored  func(export *domain.DataExport)
+		wantErr error
+	}{
+		{name: "valid link"},
+		{
+			name:    "tampered signature",
+			link:    func(_ *dataExportService, _, _, signature *string) { *signature = "A" + (*signature)[1:] },
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name:    "missing signature",
+			link:    func(_ *dataExportService, _, _, signature *string) { *signature = "" },
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name: "extended expiry",
+			link: func(_ *dataExportService, _, expires, _ *string) {
+				at, _ := strconv.ParseInt(*expires, 10, 64)
+				*expires = strconv.FormatInt(at+3600, 10)
+			},
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name:    "malformed expiry",
+			link:    func(_ *dataExportService, _, expires, _ *string) { *expires = "tomorrow" },
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name: "expired link",
+			link: func(service *dataExportService, id, expires, signature *string) {
+				at := time.Now().Add(-time.Minute).Unix()
+				*expires = strconv.FormatInt(at, 10)
+				*signature = utils.Sign(service.signingKey(), service.linkMessage(*id, at))
+			},
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name:    "signed for another export",
+			link:    func(_ *dataExportService, id, _, _ *string) { *id = "export2" },
+			wantErr: utils.ErrInvalidToken,
+		},
+		{
+			name:    "archive past retention",
+			stored:  func(export *domain.DataExport) { export.ExpiresAt = time.Now().Add(-time.Second) },
+			wantErr: repository.ErrRecordNotFound,
+		},
+		{
+			name:    "archive removed",
+			stored:  func(export *domain.DataExport) { _ = os.Remove(export.FilePath) },
+			wantErr: repository.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestDataExportService(t)
+			export := buildExport(t, service, "1")
+
+			link, err := url.Parse(service.toDataExportResp(export).DownloadURL)
+			if err != nil {
+				t.Fatalf("parse download url: %v", err)
+			}
+			if want := "/v1/exports/" + export.Id + "/download"; link.Path != want {
+				t.Fatalf("expected a link to %s, got %s", want, link.Path)
+			}
+
+			id, expires, signature := export.Id, link.Query().Get("expires"), link.Query().Get("signature")
+			if tt.link != nil {
+				tt.link(service, &id, &expires, &signature)
+			}
+			if tt.stored != nil {
+				tt.stored(service.dataExportRepository.(*fakeDataExportRepository).exports[0])
+			}
+
+			file, opened, err := service.OpenArchive(context.Background(), id, expires, signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			defer file.Close()
+
+			if opened.Id != export.Id || file.Name() != export.FilePath {
+				t.Errorf("expected archive %s at %s, got %s at %s", export.Id, export.FilePath, opened.Id, file.Name())
+			}
+		})
+	}
+}
@@ -0,0 +1,814 @@
+package service
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/mailer"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The fakes below keep state in memory and implement only what the tests
+// exercise. Each embeds its interface, so calling anything else panics.
+
+type fakeUserRepository struct {
+	repository.UserRepository
+
+	mu    sync.Mutex
+	users map[string]*domain.User
+	// beforeCreate runs ahead of CreateUser, standing in for a concurrent request.
+	beforeCreate func()
+}
+
+func newFakeUserRepository(users ...*domain.User) *fakeUserRepository {
+	f := &fakeUserRepository{users: make(map[string]*domain.User)}
+	for _, user := range users {
+		f.users[user.Id] = user
+	}
+	return f
+}
+
+func (f *fakeUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	if f.beforeCreate != nil {
+		f.beforeCreate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.users {
+		if existing.Email == user.Email {
+			return repository.ErrDuplicateEmail
+		}
+		if user.Handle != "" && strings.EqualFold(existing.Handle, user.Handle) {
+			return repository.ErrDuplicateHandle
+		}
+	}
+	user.Id = strconv.Itoa(len(f.users) + 1)
+	f.users[user.Id] = user
+	return nil
+}
+
+func (f *fakeUserRepository) GetUserById(ctx context.Context, id string) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, ok := f.users[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (f *fakeUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return f.find(func(user *domain.User) bool { return user.Email == email })
+}
+
+func (f *fakeUserRepository) GetUserByHandle(ctx context.Context, handle string) (*domain.User, error) {
+	return f.find(func(user *domain.User) bool { return strings.EqualFold(user.Handle, handle) })
+}
+
+func (f *fakeUserRepository) GetUsersByIds(ctx context.Context, ids []string) ([]*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	users := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := f.users[id]; ok && !user.IsDeactivated() {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeUserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.users[user.Id]; !ok {
+		return repository.ErrRecordNotFound
+	}
+	f.users[user.Id] = user
+	return nil
+}
+
+func (f *fakeUserRepository) Deactivate(ctx context.Context, id string, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	user.DeactivatedAt = &at
+	return nil
+}
+
+func (f *fakeUserRepository) find(match func(*domain.User) bool) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+type fakeUserIdentityRepository struct {
+	repository.UserIdentityRepository
+
+	mu         sync.Mutex
+	identities []*domain.UserIdentity
+	// beforeCreate runs ahead of Create, standing in for a concurrent request.
+	beforeCreate func()
+}
+
+func (f *fakeUserIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	if f.beforeCreate != nil {
+		f.beforeCreate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return repository.ErrIdentityLinked
+		}
+	}
+	identity.Id = strconv.Itoa(len(f.identities) + 1)
+	f.identities = append(f.identities, identity)
+	return nil
+}
+
+func (f *fakeUserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (f *fakeUserIdentityRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var identities []*domain.UserIdentity
+	for _, identity := range f.identities {
+		if identity.UserId == userId {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+type fakeOIDCStateRepository struct {
+	mu     sync.Mutex
+	states map[string]*domain.OIDCState
+}
+
+func (f *fakeOIDCStateRepository) Save(ctx context.Context, state string, data *domain.OIDCState, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.states == nil {
+		f.states = make(map[string]*domain.OIDCState)
+	}
+	f.states[state] = data
+	return nil
+}
+
+func (f *fakeOIDCStateRepository) Take(ctx context.Context, state string) (*domain.OIDCState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.states[state]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	delete(f.states, state)
+	return data, nil
+}
+
+// fakeAuthService issues a session without tokens so tests can see which user logged in.
+type fakeAuthService struct {
+	AuthService
+}
+
+func (f *fakeAuthService) CreateSession(ctx context.Context, user *domain.User, method string) (*dto.AuthResp, error) {
+	return &dto.AuthResp{User: dto.UserResp{Id: user.Id, Email: user.Email}}, nil
+}
+
+type fakeAuditLogService struct {
+	AuditLogService
+
+	mu     sync.Mutex
+	events []*domain.AuditEvent
+}
+
+func (f *fakeAuditLogService) Record(ctx context.Context, event *domain.AuditEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+}
+
+// credentialRevocations records whose credentials were revoked, whichever
+// credential repository did it.
+type credentialRevocations struct {
+	mu      sync.Mutex
+	userIds []string
+}
+
+func (c *credentialRevocations) DeleteByUserId(ctx context.Context, userId string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userIds = append(c.userIds, userId)
+	return nil
+}
+
+type fakeTokenRepository struct {
+	repository.TokenRepository
+	*credentialRevocations
+}
+
+func (f *fakeTokenRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	return f.credentialRevocations.DeleteByUserId(ctx, userId)
+}
+
+func (f *fakeTokenRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.RefreshToken, error) {
+	return nil, nil
+}
+
+type fakePersonalAccessTokenRepository struct {
+	repository.PersonalAccessTokenRepository
+	*credentialRevocations
+}
+
+func (f *fakePersonalAccessTokenRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	return f.credentialRevocations.DeleteByUserId(ctx, userId)
+}
+
+func (f *fakePersonalAccessTokenRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.PersonalAccessToken, error) {
+	return nil, nil
+}
+
+type fakeOAuthGrantRepository struct {
+	repository.OAuthGrantRepository
+	*credentialRevocations
+}
+
+func (f *fakeOAuthGrantRepository) Upsert(ctx context.Context, userId, clientId string, scopes []string) error {
+	return nil
+}
+
+func (f *fakeOAuthGrantRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	return f.credentialRevocations.DeleteByUserId(ctx, userId)
+}
+
+func (f *fakeOAuthGrantRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.OAuthGrant, error) {
+	return nil, nil
+}
+
+type fakeOAuthTokenRepository struct {
+	repository.OAuthTokenRepository
+	*credentialRevocations
+
+	mu     sync.Mutex
+	tokens map[string]*domain.OAuthToken
+	nextId int
+}
+
+func (f *fakeOAuthTokenRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	return f.credentialRevocations.DeleteByUserId(ctx, userId)
+}
+
+func (f *fakeOAuthTokenRepository) Create(ctx context.Context, token *domain.OAuthToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tokens == nil {
+		f.tokens = make(map[string]*domain.OAuthToken)
+	}
+	f.nextId++
+	token.Id = strconv.Itoa(f.nextId)
+	f.tokens[token.Id] = token
+	return nil
+}
+
+func (f *fakeOAuthTokenRepository) GetByAccessTokenHash(ctx context.Context, hash string) (*domain.OAuthToken, error) {
+	return f.find(func(token *domain.OAuthToken) bool { return token.AccessTokenHash == hash })
+}
+
+func (f *fakeOAuthTokenRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*domain.OAuthToken, error) {
+	return f.find(func(token *domain.OAuthToken) bool { return token.RefreshTokenHash == hash })
+}
+
+func (f *fakeOAuthTokenRepository) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.tokens, id)
+	return nil
+}
+
+func (f *fakeOAuthTokenRepository) find(match func(*domain.OAuthToken) bool) (*domain.OAuthToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.tokens {
+		if match(token) {
+			return token, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+type fakeOAuthClientRepository struct {
+	repository.OAuthClientRepository
+
+	clients map[string]*domain.OAuthClient
+}
+
+func (f *fakeOAuthClientRepository) GetById(ctx context.Context, id string) (*domain.OAuthClient, error) {
+	if client, ok := f.clients[id]; ok {
+		return client, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (f *fakeOAuthClientRepository) GetByIds(ctx context.Context, ids []string) ([]*domain.OAuthClient, error) {
+	var clients []*domain.OAuthClient
+	for _, id := range ids {
+		if client, ok := f.clients[id]; ok {
+			clients = append(clients, client)
+		}
+	}
+	return clients, nil
+}
+
+type fakeOAuthCodeRepository struct {
+	mu    sync.Mutex
+	codes map[string]*domain.OAuthAuthorizationCode
+}
+
+func (f *fakeOAuthCodeRepository) Save(ctx context.Context, codeHash string, code *domain.OAuthAuthorizationCode, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.codes == nil {
+		f.codes = make(map[string]*domain.OAuthAuthorizationCode)
+	}
+	f.codes[codeHash] = code
+	return nil
+}
+
+func (f *fakeOAuthCodeRepository) Take(ctx context.Context, codeHash string) (*domain.OAuthAuthorizationCode, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	code, ok := f.codes[codeHash]
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+	delete(f.codes, codeHash)
+	return code, nil
+}
+
+type fakeLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]*domain.LoginAttempts
+}
+
+func (f *fakeLoginAttemptRepository) Get(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if attempts, ok := f.attempts[key]; ok {
+		return attempts, nil
+	}
+	return &domain.LoginAttempts{}, nil
+}
+
+func (f *fakeLoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.attempts == nil {
+		f.attempts = make(map[string]*domain.LoginAttempts)
+	}
+	if _, ok := f.attempts[key]; !ok {
+		f.attempts[key] = &domain.LoginAttempts{}
+	}
+	f.attempts[key].Failures++
+	return f.attempts[key].Failures, nil
+}
+
+func (f *fakeLoginAttemptRepository) Block(ctx context.Context, key string, until time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts[key].BlockedUntil = until
+	return nil
+}
+
+func (f *fakeLoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.attempts, key)
+	return nil
+}
+
+type fakeVerificationTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*domain.VerificationToken
+}
+
+func (f *fakeVerificationTokenRepository) Save(ctx context.Context, tokenHash string, token *domain.VerificationToken, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tokens == nil {
+		f.tokens = make(map[string]*domain.VerificationToken)
+	}
+	f.tokens[tokenHash] = token
+	return nil
+}
+
+func (f *fakeVerificationTokenRepository) Take(ctx context.Context, purpose, tokenHash string) (*domain.VerificationToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token, ok := f.tokens[tokenHash]
+	if !ok || token.Purpose != purpose {
+		return nil, repository.ErrRecordNotFound
+	}
+	delete(f.tokens, tokenHash)
+	return token, nil
+}
+
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []*mailer.Message
+}
+
+func (f *fakeMailer) Send(ctx context.Context, msg *mailer.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+type fakePostRepository struct {
+	repository.PostRepository
+
+	posts map[string]*domain.Post
+}
+
+func (f *fakePostRepository) GetPostById(ctx context.Context, id string) (*domain.Post, error) {
+	if post, ok := f.posts[id]; ok {
+		return post, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (f *fakePostRepository) GetPostsByCreator(ctx context.Context, creatorId string) ([]*domain.Post, error) {
+	return f.filter(func(post *domain.Post) bool { return post.Creator == creatorId }), nil
+}
+
+func (f *fakePostRepository) GetLikedByUser(ctx context.Context, userId string) ([]*domain.Post, error) {
+	return f.filter(func(post *domain.Post) bool { return slices.Contains(post.Likes, userId) }), nil
+}
+
+// filter returns the matching posts ordered by id.
+func (f *fakePostRepository) filter(match func(*domain.Post) bool) []*domain.Post {
+	var posts []*domain.Post
+	for _, post := range f.posts {
+		if match(post) {
+			posts = append(posts, post)
+		}
+	}
+	slices.SortFunc(posts, func(a, b *domain.Post) int { return strings.Compare(a.Id, b.Id) })
+	return posts
+}
+
+type fakeCommentRepository struct {
+	repository.CommentRepository
+
+	comments   map[string]*domain.Comment
+	setLikeErr error
+}
+
+func (f *fakeCommentRepository) GetCommentById(ctx context.Context, id string) (*domain.Comment, error) {
+	if comment, ok := f.comments[id]; ok {
+		stored := *comment
+		stored.Likes = slices.Clone(comment.Likes)
+		return &stored, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (f *fakeCommentRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.Comment, error) {
+	var comments []*domain.Comment
+	for _, comment := range f.comments {
+		if comment.UserId == userId {
+			comments = append(comments, comment)
+		}
+	}
+	slices.SortFunc(comments, func(a, b *domain.Comment) int { return strings.Compare(a.Id, b.Id) })
+	return comments, nil
+}
+
+func (f *fakeCommentRepository) SetLike(ctx context.Context, id, userId string, liked bool) error {
+	if f.setLikeErr != nil {
+		return f.setLikeErr
+	}
+	comment, ok := f.comments[id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	comment.Likes = slices.DeleteFunc(comment.Likes, func(like string) bool { return like == userId })
+	if liked {
+		comment.Likes = append(comment.Likes, userId)
+	}
+	return nil
+}
+
+type fakeBlockRepository struct {
+	repository.BlockRepository
+
+	// blocks maps a blocker to the users they blocked.
+	blocks map[string][]string
+}
+
+func (f *fakeBlockRepository) GetRelatedIds(ctx context.Context, userId string) ([]string, error) {
+	related := slices.Clone(f.blocks[userId])
+	for blocker, blocked := range f.blocks {
+		if slices.Contains(blocked, userId) {
+			related = append(related, blocker)
+		}
+	}
+	return related, nil
+}
+
+func (f *fakeBlockRepository) IsBlocked(ctx context.Context, userId, otherId string) (bool, error) {
+	return slices.Contains(f.blocks[userId], otherId) || slices.Contains(f.blocks[otherId], userId), nil
+}
+
+type fakeMuteRepository struct {
+	repository.MuteRepository
+}
+
+func (f *fakeMuteRepository) IsMuted(ctx context.Context, muterId, mutedId string) (bool, error) {
+	return false, nil
+}
+
+type fakeNotificationRepository struct {
+	repository.NotificationRepository
+
+	created []*domain.Notification
+}
+
+func (f *fakeNotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	f.created = append(f.created, notification)
+	return nil
+}
+
+func (f *fakeNotificationRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.Notification, error) {
+	var notifications []*domain.Notification
+	for _, notification := range f.created {
+		if notification.ReceiverId == userId {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications, nil
+}
+
+type fakeFollowRepository struct {
+	repository.FollowRepository
+
+	// follows maps a follower to the users they follow.
+	follows map[string][]string
+}
+
+func (f *fakeFollowRepository) GetFollowedAmong(ctx context.Context, followerId string, ids []string) ([]string, error) {
+	var followed []string
+	for _, id := range ids {
+		if slices.Contains(f.follows[followerId], id) {
+			followed = append(followed, id)
+		}
+	}
+	return followed, nil
+}
+
+func (f *fakeFollowRepository) GetFollowingIds(ctx context.Context, userId string) ([]string, error) {
+	return slices.Clone(f.follows[userId]), nil
+}
+
+func (f *fakeFollowRepository) GetFollowerIds(ctx context.Context, userId string) ([]string, error) {
+	var followers []string
+	for follower, following := range f.follows {
+		if slices.Contains(following, userId) {
+			followers = append(followers, follower)
+		}
+	}
+	slices.Sort(followers)
+	return followers, nil
+}
+
+type fakeMediaRepository struct {
+	repository.MediaRepository
+
+	mu     sync.Mutex
+	media  map[string]*domain.Media
+	nextId int
+	// beforeAddPart runs ahead of AddPart, standing in for a concurrent request.
+	beforeAddPart func()
+}
+
+func (f *fakeMediaRepository) Create(ctx context.Context, media *domain.Media) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.media == nil {
+		f.media = make(map[string]*domain.Media)
+	}
+	f.nextId++
+	media.Id = "media" + strconv.Itoa(f.nextId)
+	stored := *media
+	f.media[media.Id] = &stored
+	return nil
+}
+
+func (f *fakeMediaRepository) GetById(ctx context.Context, id string) (*domain.Media, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if media, ok := f.media[id]; ok {
+		stored := *media
+		stored.Parts = slices.Clone(media.Parts)
+		return &stored, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (f *fakeMediaRepository) AddPart(ctx context.Context, id string, offset, size int64, key string) error {
+	if f.beforeAddPart != nil {
+		f.beforeAddPart()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	media, ok := f.media[id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	if media.IsReady() || media.Received != offset {
+		return repository.ErrUploadOffset
+	}
+	media.Received += size
+	media.Parts = append(media.Parts, key)
+	return nil
+}
+
+func (f *fakeMediaRepository) MarkReady(ctx context.Context, media *domain.Media) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.media[media.Id]
+	if !ok {
+		return repository.ErrRecordNotFound
+	}
+	stored.Status = domain.MediaStatusReady
+	stored.Url, stored.ThumbnailUrl = media.Url, media.ThumbnailUrl
+	stored.Width, stored.Height = media.Width, media.Height
+	stored.Parts = nil
+	return nil
+}
+
+func (f *fakeMediaRepository) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.media[id]; !ok {
+		return repository.ErrRecordNotFound
+	}
+	delete(f.media, id)
+	return nil
+}
+
+type fakeMessageRepository struct {
+	repository.MessageRepository
+
+	mu       sync.Mutex
+	messages []*domain.Message
+}
+
+func (f *fakeMessageRepository) CreateMessage(ctx context.Context, message *domain.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	message.Id = strconv.Itoa(len(f.messages) + 1)
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+func (f *fakeMessageRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var messages []*domain.Message
+	for _, message := range f.messages {
+		if message.Sender == userId || message.Receiver == userId {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+type fakeAuditLogRepository struct {
+	repository.AuditLogRepository
+
+	events []*domain.AuditEvent
+}
+
+func (f *fakeAuditLogRepository) Each(ctx context.Context, filter *domain.AuditLogFilter, fn func(*domain.AuditEvent) error) error {
+	for _, event := range f.events {
+		if event.UserId != filter.UserId {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeDataExportRepository struct {
+	repository.DataExportRepository
+
+	mu      sync.Mutex
+	exports []*domain.DataExport
+	// beforeCreate runs ahead of Create, standing in for a concurrent request.
+	beforeCreate func()
+}
+
+func (f *fakeDataExportRepository) Create(ctx context.Context, export *domain.DataExport) error {
+	if f.beforeCreate != nil {
+		f.beforeCreate()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.exports {
+		if existing.UserId == export.UserId && existing.Status == domain.DataExportStatusPending {
+			return repository.ErrTooManyAttempts
+		}
+	}
+	export.Id = "export" + strconv.Itoa(len(f.exports)+1)
+	stored := *export
+	f.exports = append(f.exports, &stored)
+	return nil
+}
+
+func (f *fakeDataExportRepository) GetById(ctx context.Context, id string) (*domain.DataExport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, export := range f.exports {
+		if export.Id == id {
+			stored := *export
+			return &stored, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+// GetByUserId returns the user's exports newest first.
+func (f *fakeDataExportRepository) GetByUserId(ctx context.Context, userId string) ([]*domain.DataExport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var exports []*domain.DataExport
+	for _, export := range slices.Backward(f.exports) {
+		if export.UserId == userId {
+			stored := *export
+			exports = append(exports, &stored)
+		}
+	}
+	return exports, nil
+}
+
+func (f *fakeDataExportRepository) MarkReady(ctx context.Context, id, filePath string, size int64, completedAt time.Time) error {
+	return f.finish(id, func(export *domain.DataExport) {
+		export.Status, export.FilePath, export.Size, export.CompletedAt = domain.DataExportStatusReady, filePath, size, &completedAt
+	})
+}
+
+func (f *fakeDataExportRepository) MarkFailed(ctx context.Context, id, reason string, completedAt time.Time) error {
+	return f.finish(id, func(export *domain.DataExport) {
+		export.Status, export.Error, export.CompletedAt = domain.DataExportStatusFailed, reason, &completedAt
+	})
+}
+
+func (f *fakeDataExportRepository) FailStale(ctx context.Context, startedBefore time.Time, reason string, completedAt time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var failed int64
+	for _, export := range f.exports {
+		if export.Status == domain.DataExportStatusPending && export.CreatedAt.Before(startedBefore) {
+			export.Status, export.Error, export.CompletedAt = domain.DataExportStatusFailed, reason, &completedAt
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+// finish updates a pending export, as the repository only finishes those.
+func (f *fakeDataExportRepository) finish(id string, update func(*domain.DataExport)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, export := range f.exports {
+		if export.Id == id && export.Status == domain.DataExportStatusPending {
+			update(export)
+			return nil
+		}
+	}
+	return repository.ErrRecordNotFound
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"slices"
+	"time"
+)
+
+type ListService interface {
+	CreateList(ctx context.Context, ownerId string, input *dto.CreateListReq) (*dto.ListResp, error)
+	GetList(ctx context.Context, viewerId, id string) (*dto.ListResp, error)
+	UpdateList(ctx context.Context, actorId, id string, input *dto.UpdateListReq) (*dto.ListResp, error)
+	DeleteList(ctx context.Context, actorId, id string) error
+	GetListsByOwner(ctx context.Context, viewerId, ownerId string) ([]*dto.ListResp, error)
+	GetSubscribedLists(ctx context.Context, userId string) ([]*dto.ListResp, error)
+	AddMember(ctx context.Context, actorId, id, memberId string) (*dto.ListResp, error)
+	RemoveMember(ctx context.Context, actorId, id, memberId string) (*dto.ListResp, error)
+	GetMembers(ctx context.Context, viewerId, id string) ([]*dto.UserResp, error)
+	Subscribe(ctx context.Context, userId, id string) (*dto.ListResp, error)
+	Unsubscribe(ctx context.Context, userId, id string) error
+	GetTimeline(ctx context.Context, viewerId, id string, page, limit int) ([]*dto.PostResp, int64, error)
+	DeleteByUserId(ctx context.Context, userId string) error
+}
+
+type listService struct {
+	listRepository             repository.ListRepository
+	listSubscriptionRepository repository.ListSubscriptionRepository
+	userRepository             repository.UserRepository
+	blockRepository            repository.BlockRepository
+	postService                PostService
+	notifier                   *notifier
+}
+
+func (l *listService) CreateList(ctx context.Context, ownerId string, input *dto.CreateListReq) (*dto.ListResp, error) {
+	now := time.Now()
+	list := &domain.List{
+		OwnerId:     ownerId,
+		Name:        input.Name,
+		Description: input.Description,
+		IsPrivate:   input.IsPrivate,
+		MemberIds:   make([]string, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := l.listRepository.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to create list: %w", err)
+	}
+
+	return l.toListResp(list), nil
+}
+
+func (l *listService) GetList(ctx context.Context, viewerId, id string) (*dto.ListResp, error) {
+	list, err := l.getVisibleList(ctx, viewerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.toListResp(list), nil
+}
+
+func (l *listService) UpdateList(ctx context.Context, actorId, id string, input *dto.UpdateListReq) (*dto.ListResp, error) {
+	list, err := l.getOwnedList(ctx, actorId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		list.Name = *input.Name
+	}
+
+	if input.Description != nil {
+		list.Description = *input.Description
+	}
+
+	if input.IsPrivate != nil {
+		list.IsPrivate = *input.IsPrivate
+	}
+
+	list.UpdatedAt = time.Now()
+	if err := l.listRepository.Update(ctx, list); err != nil {
+		return nil, err
+	}
+
+	return l.toListResp(list), nil
+}
+
+func (l *listService) DeleteList(ctx context.Context, actorId, id string) error {
+	list, err := l.getOwnedList(ctx, actorId, id)
+	if err != nil {
+		return err
+	}
+
+	return l.deleteList(ctx, list.Id)
+}
+
+// GetListsByOwner returns the owner's lists; private ones only when the owner is asking.
+func (l *listService) GetListsByOwner(ctx context.Context, viewerId, ownerId string) ([]*dto.ListResp, error) {
+	if viewerId != "" && viewerId != ownerId {
+		blocked, err := l.blockRepository.IsBlocked(ctx, viewerId, ownerId)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return []*dto.ListResp{}, nil
+		}
+	}
+
+	lists, err := l.listRepository.GetByOwnerId(ctx, ownerId, viewerId == ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.toListResps(lists), nil
+}
+
+// GetSubscribedLists returns the lists the user subscribed to, skipping lists
+// their owner has since made private.
+func (l *listService) GetSubscribedLists(ctx context.Context, userId string) ([]*dto.ListResp, error) {
+	ids, err := l.listSubscriptionRepository.GetListIdsByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := l.listRepository.GetByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lists = slices.DeleteFunc(lists, func(list *domain.List) bool {
+		return list.IsPrivate && list.OwnerId != userId
+	})
+
+	return l.toListResps(lists), nil
+}
+
+// AddMember adds the user to the list and, for public lists, lets them know.
+func (l *listService) AddMember(ctx context.Context, actorId, id, memberId string) (*dto.ListResp, error) {
+	list, err := l.getOwnedList(ctx, actorId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := l.userRepository.GetUserById(ctx, memberId)
+	if err != nil {
+		return nil, err
+	}
+
+	if member.IsDeactivated() {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	if slices.Contains(list.MemberIds, memberId) {
+		return l.toListResp(list), nil
+	}
+
+	blocked, err := l.blockRepository.IsBlocked(ctx, list.OwnerId, memberId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block status: %w", err)
+	}
+	if blocked {
+		return nil, repository.ErrBlocked
+	}
+
+	if err := l.listRepository.AddMember(ctx, list.Id, memberId); err != nil {
+		return nil, err
+	}
+
+	if !list.IsPrivate {
+		l.notify(ctx, list.OwnerId, memberId, list, " added you to their list ")
+	}
+
+	if list, err = l.listRepository.GetById(ctx, list.Id); err != nil {
+		return nil, err
+	}
+
+	return l.toListResp(list), nil
+}
+
+func (l *listService) RemoveMember(ctx context.Context, actorId, id, memberId string) (*dto.ListResp, error) {
+	list, err := l.getOwnedList(ctx, actorId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.listRepository.RemoveMember(ctx, list.Id, memberId); err != nil {
+		return nil, err
+	}
+
+	if list, err = l.listRepository.GetById(ctx, list.Id); err != nil {
+		return nil, err
+	}
+
+	return l.toListResp(list), nil
+}
+
+func (l *listService) GetMembers(ctx context.Context, viewerId, id string) ([]*dto.UserResp, error) {
+	list, err := l.getVisibleList(ctx, viewerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := l.userRepository.GetUsersByIds(ctx, list.MemberIds)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dto.UserResp, len(users))
+	for i, user := range users {
+		resp[i] = &dto.UserResp{
+			Id:             user.Id,
+			FirstName:      user.FirstName,
+			LastName:       user.LastName,
+			Email:          user.Email,
+			Handle:         user.Handle,
+			ImageUrl:       user.ImageUrl,
+			BannerUrl:      user.BannerUrl,
+			Bio:            user.Bio,
+			Role:           user.Role,
+			IsPrivate:      user.IsPrivate,
+			FollowersCount: user.FollowersCount,
+			FollowingCount: user.FollowingCount,
+		}
+	}
+
+	return resp, nil
+}
+
+// Subscribe follows the timeline of someone else's public list and tells the owner.
+func (l *listService) Subscribe(ctx context.Context, userId, id string) (*dto.ListResp, error) {
+	list, err := l.getVisibleList(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.OwnerId == userId {
+		return nil, repository.ErrForbidden
+	}
+
+	subscription := &domain.ListSubscription{
+		ListId:    list.Id,
+		UserId:    userId,
+		CreatedAt: time.Now(),
+	}
+	if err := l.listSubscriptionRepository.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	// An empty id means the user was already subscribed.
+	if subscription.Id != "" {
+		if err := l.listRepository.IncrementSubscribers(ctx, list.Id, 1); err != nil {
+			return nil, err
+		}
+		l.notify(ctx, userId, list.OwnerId, list, " subscribed to your list ")
+	}
+
+	if list, err = l.listRepository.GetById(ctx, list.Id); err != nil {
+		return nil, err
+	}
+
+	return l.toListResp(list), nil
+}
+
+func (l *listService) Unsubscribe(ctx context.Context, userId, id string) error {
+	if err := l.listSubscriptionRepository.Delete(ctx, id, userId); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return l.listRepository.IncrementSubscribers(ctx, id, -1)
+}
+
+// GetTimeline pages through the members' posts like the home feed does.
+func (l *listService) GetTimeline(ctx context.Context, viewerId, id string, page, limit int) ([]*dto.PostResp, int64, error) {
+	list, err := l.getVisibleList(ctx, viewerId, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return l.postService.GetTimeline(ctx, viewerId, list.MemberIds, page, limit)
+}
+
+// DeleteByUserId removes the user's lists, their subscriptions and their
+// memberships in other users' lists.
+func (l *listService) DeleteByUserId(ctx context.Context, userId string) error {
+	lists, err := l.listRepository.GetByOwnerId(ctx, userId, true)
+	if err != nil {
+		return err
+	}
+
+	for _, list := range lists {
+		if err := l.deleteList(ctx, list.Id); err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	listIds, err := l.listSubscriptionRepository.GetListIdsByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, listId := range listIds {
+		if err := l.Unsubscribe(ctx, userId, listId); err != nil {
+			return err
+		}
+	}
+
+	return l.listRepository.RemoveMemberFromAll(ctx, userId)
+}
+
+func (l *listService) deleteList(ctx context.Context, id string) error {
+	if err := l.listSubscriptionRepository.DeleteByListId(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete list subscriptions: %w", err)
+	}
+
+	return l.listRepository.Delete(ctx, id)
+}
+
+// getVisibleList hides private lists from everyone but their owner, and every
+// list from users the owner blocked or was blocked by.
+func (l *listService) getVisibleList(ctx context.Context, viewerId, id string) (*domain.List, error) {
+	list, err := l.listRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewerId == list.OwnerId {
+		return list, nil
+	}
+
+	if list.IsPrivate {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	if viewerId != "" {
+		blocked, err := l.blockRepository.IsBlocked(ctx, viewerId, list.OwnerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block status: %w", err)
+		}
+		if blocked {
+			return nil, repository.ErrRecordNotFound
+		}
+	}
+
+	return list, nil
+}
+
+func (l *listService) getOwnedList(ctx context.Context, actorId, id string) (*domain.List, error) {
+	list, err := l.listRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManageUser(ctx, actorId, list.OwnerId) {
+		if list.IsPrivate {
+			return nil, repository.ErrRecordNotFound
+		}
+		return nil, repository.ErrForbidden
+	}
+
+	return list, nil
+}
+
+// notify tells receiverId about something senderId did with the list, e.g. " subscribed to your list ".
+func (l *listService) notify(ctx context.Context, senderId, receiverId string, list *domain.List, action string) {
+	sender, err := l.userRepository.GetUserById(ctx, senderId)
+	if err != nil {
+		return
+	}
+
+	l.notifier.notify(ctx, &domain.Notification{
+		SenderId:   sender.Id,
+		ReceiverId: receiverId,
+		TargetId:   list.Id,
+		Details:    sender.FirstName + " " + sender.LastName + action + list.Name,
+		IsRead:     false,
+		CreatedAt:  time.Now(),
+		NotificationUser: domain.NotificationUser{
+			Name:   sender.FirstName + " " + sender.LastName,
+			Avatar: sender.ImageUrl,
+		},
+	})
+}
+
+func (l *listService) toListResps(lists []*domain.List) []*dto.ListResp {
+	resp := make([]*dto.ListResp, len(lists))
+	for i, list := range lists {
+		resp[i] = l.toListResp(list)
+	}
+	return resp
+}
+
+func (l *listService) toListResp(input *domain.List) *dto.ListResp {
+	return &dto.ListResp{
+		Id:              input.Id,
+		OwnerId:         input.OwnerId,
+		Name:            input.Name,
+		Description:     input.Description,
+		IsPrivate:       input.IsPrivate,
+		MemberCount:     len(input.MemberIds),
+		SubscriberCount: input.SubscriberCount,
+		CreatedAt:       input.CreatedAt,
+		UpdatedAt:       input.UpdatedAt,
+	}
+}
+
+func NewListService(
+	listRepository repository.ListRepository,
+	listSubscriptionRepository repository.ListSubscriptionRepository,
+	userRepository repository.UserRepository,
+	blockRepository repository.BlockRepository,
+	muteRepository repository.MuteRepository,
+	notificationRepository repository.NotificationRepository,
+	postService PostService,
+) ListService {
+	return &listService{
+		listRepository:             listRepository,
+		listSubscriptionRepository: listSubscriptionRepository,
+		userRepository:             userRepository,
+		blockRepository:            blockRepository,
+		postService:                postService,
+		notifier:                   newNotifier(notificationRepository, blockRepository, muteRepository),
+	}
+}
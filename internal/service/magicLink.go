@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/mailer"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type MagicLinkService interface {
+	RequestLink(ctx context.Context, input *dto.MagicLinkReq) error
+	RedeemLink(ctx context.Context, input *dto.RedeemMagicLinkReq) (*dto.AuthResp, error)
+}
+
+type magicLinkService struct {
+	config                      *config.Config
+	logger                      *slog.Logger
+	mailer                      mailer.Mailer
+	authService                 AuthService
+	userRepository              repository.UserRepository
+	verificationTokenRepository repository.VerificationTokenRepository
+	loginAttemptRepository      repository.LoginAttemptRepository
+}
+
+// RequestLink emails a login link if the address belongs to an account. It
+// reports success either way and does all the work in the background, so
+// neither the response nor its timing reveals whether the email is registered.
+func (m *magicLinkService) RequestLink(ctx context.Context, input *dto.MagicLinkReq) error {
+	if err := m.throttle(ctx, "email:"+strings.ToLower(input.Email), "ip:"+utils.ClientIPFromContext(ctx)); err != nil {
+		return err
+	}
+
+	go m.sendLink(context.WithoutCancel(ctx), input.Email)
+
+	return nil
+}
+
+func (m *magicLinkService) RedeemLink(ctx context.Context, input *dto.RedeemMagicLinkReq) (*dto.AuthResp, error) {
+	token, err := m.verificationTokenRepository.Take(ctx, domain.VerificationPurposeMagicLink, utils.HashToken(input.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, utils.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	user, err := m.userRepository.GetUserById(ctx, token.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, utils.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	// The link was sent to the address on file at the time; reject it if the email changed since.
+	if user.Email != token.Email {
+		return nil, utils.ErrInvalidToken
+	}
+
+	return m.authService.CreateSession(ctx, user, domain.VerificationPurposeMagicLink)
+}
+
+// throttle limits how often links can be requested per email and per IP.
+// Every request counts, registered email or not, so hitting the limit reveals
+// nothing either. Like login throttling it fails open when the attempt store
+// is unavailable.
+func (m *magicLinkService) throttle(ctx context.Context, emailKey, ipKey string) error {
+	var retryAfter time.Duration
+	for _, key := range []string{emailKey, ipKey} {
+		attempts, err := m.loginAttemptRepository.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		retryAfter = max(retryAfter, time.Until(attempts.BlockedUntil))
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	cfg := m.config.MagicLink
+	limits := map[string]int{emailKey: cfg.MaxRequests, ipKey: cfg.IPMaxRequests}
+	for key, limit := range limits {
+		if requests, err := m.loginAttemptRepository.RecordFailure(ctx, key, cfg.Window); err == nil && requests >= limit {
+			_ = m.loginAttemptRepository.Block(ctx, key, time.Now().Add(cfg.Window))
+		}
+	}
+
+	return nil
+}
+
+func (m *magicLinkService) sendLink(ctx context.Context, email string) {
+	user, err := m.userRepository.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, repository.ErrRecordNotFound) {
+			m.logger.Error("failed to get user by email", "error", err)
+		}
+		return
+	}
+
+	token, err := utils.GenerateOpaqueToken(utils.MagicLinkTokenPrefix)
+	if err != nil {
+		m.logger.Error("failed to generate magic link token", "user_id", user.Id, "error", err)
+		return
+	}
+
+	if err := m.verificationTokenRepository.Save(ctx, utils.HashToken(token), &domain.VerificationToken{
+		UserId:    user.Id,
+		Email:     user.Email,
+		Purpose:   domain.VerificationPurposeMagicLink,
+		CreatedAt: time.Now(),
+	}, m.config.MagicLink.TTL); err != nil {
+		m.logger.Error("failed to save magic link token", "user_id", user.Id, "error", err)
+		return
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Your X-Gopher login link",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to log in. It can be used once and expires in %s.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.\n",
+			user.FirstName, m.config.MagicLink.TTL, withQuery(m.config.MagicLink.URL, url.Values{"token": {token}})),
+	}
+
+	if err := m.mailer.Send(ctx, msg); err != nil {
+		m.logger.Error("failed to send magic link", "user_id", user.Id, "error", err)
+	}
+}
+
+func NewMagicLinkService(config *config.Config, logger *slog.Logger, mailer mailer.Mailer, authService AuthService, userRepository repository.UserRepository, verificationTokenRepository repository.VerificationTokenRepository, loginAttemptRepository repository.LoginAttemptRepository) MagicLinkService {
+	return &magicLinkService{
+		config:                      config,
+		logger:                      logger,
+		mailer:                      mailer,
+		authService:                 authService,
+		userRepository:              userRepository,
+		verificationTokenRepository: verificationTokenRepository,
+		loginAttemptRepository:      loginAttemptRepository,
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestMagicLinkService(users ...*domain.User) *magicLinkService {
+	return &magicLinkService{
+		config: &config.Config{
+			MagicLink: config.MagicLink{
+				URL:           "https://app.example.com/auth/magic-link",
+				TTL:           15 * time.Minute,
+				Window:        15 * time.Minute,
+				MaxRequests:   3,
+				IPMaxRequests: 5,
+			},
+		},
+		logger:                      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		mailer:                      &fakeMailer{},
+		authService:                 &fakeAuthService{},
+		userRepository:              newFakeUserRepository(users...),
+		verificationTokenRepository: &fakeVerificationTokenRepository{},
+		loginAttemptRepository:      &fakeLoginAttemptRepository{},
+	}
+}
+
+func TestMagicLinkSendLink(t *testing.T) {
+	user := &domain.User{Id: "1", FirstName: "Jane", Email: "jane@example.com"}
+	service := newTestMagicLinkService(user)
+	ctx := context.Background()
+
+	tokens := service.verificationTokenRepository.(*fakeVerificationTokenRepository)
+	mailer := service.mailer.(*fakeMailer)
+
+	service.sendLink(ctx, "nobody@example.com")
+	if len(tokens.tokens) != 0 || len(mailer.sent) != 0 {
+		t.Fatalf("expected nothing saved or sent for an unknown email, got %d tokens and %d emails", len(tokens.tokens), len(mailer.sent))
+	}
+
+	service.sendLink(ctx, user.Email)
+	if len(mailer.sent) != 1 || mailer.sent[0].To != user.Email {
+		t.Fatalf("expected one email to %s, got %+v", user.Email, mailer.sent)
+	}
+
+	token := linkToken(t, mailer.sent[0].Body)
+	resp, err := service.RedeemLink(ctx, &dto.RedeemMagicLinkReq{Token: token})
+	if err != nil {
+		t.Fatalf("redeem link: %v", err)
+	}
+	if resp.User.Id != user.Id {
+		t.Errorf("expected login as %s, got %s", user.Id, resp.User.Id)
+	}
+
+	if _, err := service.RedeemLink(ctx, &dto.RedeemMagicLinkReq{Token: token}); !errors.Is(err, utils.ErrInvalidToken) {
+		t.Errorf("expected a redeemed link to be rejected, got %v", err)
+	}
+}
+
+func TestMagicLinkRequestLinkThrottle(t *testing.T) {
+	tests := []struct {
+		name  string
+		email func(i int) string
+		ip    func(i int) string
+		// allowed is how many requests pass before the next one is throttled.
+		allowed int
+	}{
+		{
+			name:    "same email from different IPs",
+			email:   func(i int) string { return "Jane@Example.com" },
+			ip:      func(i int) string { return "10.0.0." + strconv.Itoa(i+1) },
+			allowed: 3,
+		},
+		{
+			name:    "different emails from the same IP",
+			email:   func(i int) string { return "user" + strconv.Itoa(i) + "@example.com" },
+			ip:      func(i int) string { return "10.0.0.1" },
+			allowed: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestMagicLinkService()
+
+			for i := range tt.allowed + 1 {
+				ctx := utils.WithClientInfo(context.Background(), tt.ip(i), "test")
+				err := service.RequestLink(ctx, &dto.MagicLinkReq{Email: tt.email(i)})
+
+				if i < tt.allowed {
+					if err != nil {
+						t.Fatalf("request %d: expected success, got %v", i+1, err)
+					}
+					continue
+				}
+
+				var throttled *LoginThrottledError
+				if !errors.As(err, &throttled) || !errors.Is(err, repository.ErrTooManyAttempts) {
+					t.Fatalf("request %d: expected throttling, got %v", i+1, err)
+				}
+				if throttled.RetryAfter <= 0 {
+					t.Errorf("expected a positive retry after, got %s", throttled.RetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func linkToken(t *testing.T, body string) string {
+	t.Helper()
+	for _, field := range strings.Fields(body) {
+		if u, err := url.Parse(field); err == nil && u.Query().Has("token") {
+			return u.Query().Get("token")
+		}
+	}
+	t.Fatalf("no link with a token in %q", body)
+	return ""
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MediaService interface {
+	Upload(ctx context.Context, ownerId string, data []byte) (*dto.MediaResp, error)
+	CreateUpload(ctx context.Context, ownerId string, input *dto.CreateUploadReq) (*dto.MediaResp, error)
+	UploadChunk(ctx context.Context, ownerId, id string, offset int64, data []byte) (*dto.MediaResp, error)
+	GetMedia(ctx context.Context, ownerId, id string) (*dto.MediaResp, error)
+	DeleteMedia(ctx context.Context, ownerId, id string) error
+	Resolve(ctx context.Context, ownerId, postId string, ids []string) ([]domain.Attachment, error)
+	Attach(ctx context.Context, postId string, ids []string) error
+	DeleteByPostId(ctx context.Context, postId string, keepIds []string) error
+	DeleteByOwnerId(ctx context.Context, ownerId string) error
+	DeleteUnattached(ctx context.Context) (int, error)
+	ImportLegacy(ctx context.Context, postId, ownerId, data string) (*domain.Attachment, error)
+}
+
+type mediaService struct {
+	config          *config.Config
+	mediaRepository repository.MediaRepository
+	storage         storage.Storage
+}
+
+// Upload stores an image sent in a single request.
+func (m *mediaService) Upload(ctx context.Context, ownerId string, data []byte) (*dto.MediaResp, error) {
+	if int64(len(data)) > m.config.Media.MaxSize {
+		return nil, repository.ErrImageTooLarge
+	}
+
+	media := &domain.Media{
+		OwnerId:   ownerId,
+		Status:    domain.MediaStatusPending,
+		Size:      int64(len(data)),
+		Received:  int64(len(data)),
+		CreatedAt: time.Now(),
+	}
+
+	if err := m.mediaRepository.Create(ctx, media); err != nil {
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	if err := m.finalize(ctx, media, data); err != nil {
+		return nil, err
+	}
+
+	return m.toMediaResp(media), nil
+}
+
+// CreateUpload starts a resumable upload. The client then sends the bytes in
+// order with UploadChunk and can ask GetMedia where to resume after a failure.
+func (m *mediaService) CreateUpload(ctx context.Context, ownerId string, input *dto.CreateUploadReq) (*dto.MediaResp, error) {
+	if input.Size > m.config.Media.MaxSize {
+		return nil, repository.ErrImageTooLarge
+	}
+
+	media := &domain.Media{
+		OwnerId:   ownerId,
+		Status:    domain.MediaStatusPending,
+		Size:      input.Size,
+		CreatedAt: time.Now(),
+	}
+
+	if err := m.mediaRepository.Create(ctx, media); err != nil {
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	return m.toMediaResp(media), nil
+}
+
+// UploadChunk stores the chunk starting at offset, which must be where the
+// upload left off. The chunk completing the upload turns it into ready media.
+func (m *mediaService) UploadChunk(ctx context.Context, ownerId, id string, offset int64, data []byte) (*dto.MediaResp, error) {
+	media, err := m.mediaRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if media.OwnerId != ownerId {
+		return nil, repository.ErrForbidden
+	}
+
+	if media.IsReady() || offset != media.Received {
+		return nil, repository.ErrUploadOffset
+	}
+
+	// An empty chunk at the end retries a completion that failed midway.
+	if media.Received < media.Size {
+		if int64(len(data)) > m.config.Media.ChunkSize {
+			return nil, repository.ErrImageTooLarge
+		}
+
+		if len(data) == 0 || offset+int64(len(data)) > media.Size {
+			return nil, repository.ErrInvalidMedia
+		}
+
+		// Every attempt gets its own key, so one that loses the race for the
+		// offset only deletes its own chunk, never the one that won.
+		key := m.partKey(media, offset)
+		if err := m.storage.Put(ctx, key, bytes.NewReader(data), "application/octet-stream"); err != nil {
+			return nil, fmt.Errorf("failed to store chunk: %w", err)
+		}
+
+		if err := m.mediaRepository.AddPart(ctx, id, offset, int64(len(data)), key); err != nil {
+			_ = m.storage.Delete(ctx, key)
+			return nil, err
+		}
+
+		media.Received += int64(len(data))
+		media.Parts = append(media.Parts, key)
+
+		if media.Received < media.Size {
+			return m.toMediaResp(media), nil
+		}
+	}
+
+	assembled, err := m.assemble(ctx, media)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.finalize(ctx, media, assembled); err != nil {
+		return nil, err
+	}
+
+	return m.toMediaResp(media), nil
+}
+
+func (m *mediaService) GetMedia(ctx context.Context, ownerId, id string) (*dto.MediaResp, error) {
+	media, err := m.mediaRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if media.OwnerId != ownerId {
+		return nil, repository.ErrForbidden
+	}
+
+	return m.toMediaResp(media), nil
+}
+
+// DeleteMedia deletes media that no post uses yet. Attached media goes away
+// together with its post.
+func (m *mediaService) DeleteMedia(ctx context.Context, ownerId, id string) error {
+	media, err := m.mediaRepository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if media.OwnerId != ownerId {
+		return repository.ErrForbidden
+	}
+
+	if media.PostId != "" {
+		return repository.ErrInvalidMedia
+	}
+
+	return m.delete(ctx, media)
+}
+
+// Resolve checks that the owner may put the media on the post and returns
+// their attachments in the given order. Media must be ready and either
+// unused or already attached to postId, which is empty for a new post.
+func (m *mediaService) Resolve(ctx context.Context, ownerId, postId string, ids []string) ([]domain.Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	media, err := m.mediaRepository.GetByIds(ctx, ids)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidId) {
+			return nil, repository.ErrInvalidMedia
+		}
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+
+	byId := make(map[string]*domain.Media, len(media))
+	for _, item := range media {
+		byId[item.Id] = item
+	}
+
+	attachments := make([]domain.Attachment, len(ids))
+	for i, id := range ids {
+		item, ok := byId[id]
+		if !ok || item.OwnerId != ownerId || !item.IsReady() || (item.PostId != "" && item.PostId != postId) {
+			return nil, repository.ErrInvalidMedia
+		}
+		attachments[i] = toAttachment(item)
+	}
+
+	return attachments, nil
+}
+
+func (m *mediaService) Attach(ctx context.Context, postId string, ids []string) error {
+	return m.mediaRepository.Attach(ctx, ids, postId)
+}
+
+// DeleteByPostId deletes the media attached to the post, except keepIds.
+func (m *mediaService) DeleteByPostId(ctx context.Context, postId string, keepIds []string) error {
+	media, err := m.mediaRepository.GetByPostId(ctx, postId)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range media {
+		if slices.Contains(keepIds, item.Id) {
+			continue
+		}
+		if err := m.delete(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *mediaService) DeleteByOwnerId(ctx context.Context, ownerId string) error {
+	media, err := m.mediaRepository.GetByOwnerId(ctx, ownerId)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range media {
+		if err := m.delete(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteUnattached deletes abandoned uploads and media no post picked up
+// within the configured time, returning how many were deleted.
+func (m *mediaService) DeleteUnattached(ctx context.Context) (int, error) {
+	media, err := m.mediaRepository.GetUnattachedBefore(ctx, time.Now().Add(-m.config.Media.UnattachedTTL))
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, item := range media {
+		if err := m.delete(ctx, item); err != nil {
+			return deleted, fmt.Errorf("failed to delete media %s: %w", item.Id, err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// ImportLegacy turns a base64 image, optionally written as a data URL, into
+// media attached to the post. It backs the migration away from posts
+// carrying their image inline.
+func (m *mediaService) ImportLegacy(ctx context.Context, postId, ownerId, data string) (*domain.Attachment, error) {
+	if _, payload, ok := strings.Cut(data, ","); ok && strings.HasPrefix(data, "data:") {
+		data = payload
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", repository.ErrInvalidImage, err)
+	}
+
+	media := &domain.Media{
+		OwnerId:   ownerId,
+		PostId:    postId,
+		Status:    domain.MediaStatusPending,
+		Size:      int64(len(decoded)),
+		Received:  int64(len(decoded)),
+		CreatedAt: time.Now(),
+	}
+
+	if err := m.mediaRepository.Create(ctx, media); err != nil {
+		return nil, fmt.Errorf("failed to create media: %w", err)
+	}
+
+	if err := m.finalize(ctx, media, decoded); err != nil {
+		return nil, err
+	}
+
+	attachment := toAttachment(media)
+	return &attachment, nil
+}
+
+// finalize renders the full size image and thumbnail from the uploaded bytes
+// and marks the media ready. Re-encoding strips metadata such as EXIF. When
+// the bytes aren't a usable image the media is deleted.
+func (m *mediaService) finalize(ctx context.Context, media *domain.Media, data []byte) error {
+	full, err := utils.FitImage(data, m.config.Media.MaxDimension)
+	if err != nil {
+		_ = m.delete(ctx, media)
+		return fmt.Errorf("%w: %v", repository.ErrInvalidImage, err)
+	}
+
+	thumbnail, err := utils.FitImage(full, m.config.Media.ThumbnailSize)
+	if err != nil {
+		_ = m.delete(ctx, media)
+		return fmt.Errorf("%w: %v", repository.ErrInvalidImage, err)
+	}
+
+	if media.Width, media.Height, err = utils.ImageSize(full); err != nil {
+		_ = m.delete(ctx, media)
+		return fmt.Errorf("%w: %v", repository.ErrInvalidImage, err)
+	}
+
+	prefix := "media/" + media.OwnerId + "/" + media.Id
+	if err := m.storage.Put(ctx, prefix+".jpg", bytes.NewReader(full), "image/jpeg"); err != nil {
+		return fmt.Errorf("failed to store media: %w", err)
+	}
+	if err := m.storage.Put(ctx, prefix+"_thumb.jpg", bytes.NewReader(thumbnail), "image/jpeg"); err != nil {
+		return fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+
+	media.Url = m.storage.URL(prefix + ".jpg")
+	media.ThumbnailUrl = m.storage.URL(prefix + "_thumb.jpg")
+
+	if err := m.mediaRepository.MarkReady(ctx, media); err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+
+	m.deleteParts(ctx, media)
+	media.Status = domain.MediaStatusReady
+	media.Parts = nil
+
+	return nil
+}
+
+// assemble reads the chunks of a completed resumable upload back in order.
+func (m *mediaService) assemble(ctx context.Context, media *domain.Media) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, media.Size))
+	for _, key := range media.Parts {
+		part, err := m.storage.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk: %w", err)
+		}
+		_, err = io.Copy(buf, part)
+		part.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// delete removes the stored files before the record, so a failure leaves
+// the record behind to retry with.
+func (m *mediaService) delete(ctx context.Context, media *domain.Media) error {
+	for _, url := range []string{media.Url, media.ThumbnailUrl} {
+		if key, ok := m.storage.Key(url); ok {
+			if err := m.storage.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to delete media file: %w", err)
+			}
+		}
+	}
+
+	m.deleteParts(ctx, media)
+
+	return m.mediaRepository.Delete(ctx, media.Id)
+}
+
+func (m *mediaService) deleteParts(ctx context.Context, media *domain.Media) {
+	for _, key := range media.Parts {
+		_ = m.storage.Delete(ctx, key)
+	}
+}
+
+// partKey returns a new key for an attempt to store the chunk at offset.
+func (m *mediaService) partKey(media *domain.Media, offset int64) string {
+	return "media/" + media.OwnerId + "/" + media.Id + "/parts/" + strconv.FormatInt(offset, 10) + "-" + strings.ToLower(rand.Text())
+}
+
+func (m *mediaService) toMediaResp(media *domain.Media) *dto.MediaResp {
+	resp := &dto.MediaResp{
+		Id:           media.Id,
+		Status:       media.Status,
+		Url:          media.Url,
+		ThumbnailUrl: media.ThumbnailUrl,
+		Width:        media.Width,
+		Height:       media.Height,
+		Size:         media.Size,
+		Received:     media.Received,
+		CreatedAt:    media.CreatedAt,
+	}
+	if !media.IsReady() {
+		resp.ChunkSize = m.config.Media.ChunkSize
+	}
+	return resp
+}
+
+func toAttachment(media *domain.Media) domain.Attachment {
+	return domain.Attachment{
+		MediaId:      media.Id,
+		Url:          media.Url,
+		ThumbnailUrl: media.ThumbnailUrl,
+		Width:        media.Width,
+		Height:       media.Height,
+	}
+}
+
+func toAttachmentResps(attachments []domain.Attachment) []dto.AttachmentResp {
+	resp := make([]dto.AttachmentResp, len(attachments))
+	for i, attachment := range attachments {
+		resp[i] = dto.AttachmentResp{
+			MediaId:      attachment.MediaId,
+			Url:          attachment.Url,
+			ThumbnailUrl: attachment.ThumbnailUrl,
+			Width:        attachment.Width,
+			Height:       attachment.Height,
+		}
+	}
+	return resp
+}
+
+func NewMediaService(config *config.Config, mediaRepository repository.MediaRepository, storage storage.Storage) MediaService {
+	return &mediaService{
+		config:          config,
+		mediaRepository: mediaRepository,
+		storage:         storage,
+	}
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"testing"
+)
+
+const testMediaBaseURL = "http://localhost:4000/uploads"
+
+func newTestMediaService(t *testing.T) *mediaService {
+	return &mediaService{
+		config: &config.Config{
+			Media: config.Media{
+				MaxSize:       1 << 20,
+				ChunkSize:     4096,
+				MaxDimension:  64,
+				ThumbnailSize: 16,
+			},
+		},
+		mediaRepository: &fakeMediaRepository{},
+		storage:         storage.NewLocal(storage.WithDir(t.TempDir()), storage.WithBaseURL(testMediaBaseURL)),
+	}
+}
+
+// stored returns the object behind a URL the service handed out.
+func stored(t *testing.T, service *mediaService, url string) []byte {
+	t.Helper()
+
+	key, ok := service.storage.Key(url)
+	if !ok {
+		t.Fatalf("url %q is not in storage", url)
+	}
+	r, err := service.storage.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("get %q: %v", key, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %q: %v", key, err)
+	}
+	return data
+}
+
+// testPNG encodes a noisy width x height image, which keeps it from
+// compressing into a single chunk.
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x * y), A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func assertImageSize(t *testing.T, data []byte, width, height int) {
+	t.Helper()
+
+	if contentType, _ := utils.ImageContentType(data); contentType != "image/jpeg" {
+		t.Errorf("expected a jpeg, got %s", contentType)
+	}
+	if w, h, err := utils.ImageSize(data); err != nil || w != width || h != height {
+		t.Errorf("expected %dx%d, got %dx%d (%v)", width, height, w, h, err)
+	}
+}
+
+func TestMediaServiceChunkedUpload(t *testing.T) {
+	service := newTestMediaService(t)
+	ctx := context.Background()
+	mediaRepository := service.mediaRepository.(*fakeMediaRepository)
+
+	data := testPNG(t, 200, 100)
+	chunkSize := int(service.config.Media.ChunkSize)
+	if len(data) <= 2*chunkSize {
+		t.Fatalf("test image is %d bytes, expected more than two chunks", len(data))
+	}
+
+	upload, err := service.CreateUpload(ctx, "owner", &dto.CreateUploadReq{Size: int64(len(data))})
+	if err != nil {
+		t.Fatalf("create upload: %v", err)
+	}
+	if upload.Status != domain.MediaStatusPending || upload.ChunkSize != int64(chunkSize) {
+		t.Fatalf("expected a pending upload taking %d byte chunks, got %+v", chunkSize, upload)
+	}
+
+	var parts []string
+	for offset := 0; offset < len(data); offset += chunkSize {
+		chunk := data[offset:min(offset+chunkSize, len(data))]
+
+		if offset > 0 {
+			// Resending the previous chunk or skipping ahead is rejected
+			// without touching what was stored.
+			if _, err := service.UploadChunk(ctx, "owner", upload.Id, int64(offset-chunkSize), chunk); !errors.Is(err, repository.ErrUploadOffset) {
+				t.Fatalf("resent chunk: expected %v, got %v", repository.ErrUploadOffset, err)
+			}
+		}
+		if _, err := service.UploadChunk(ctx, "owner", upload.Id, int64(offset+1), chunk); !errors.Is(err, repository.ErrUploadOffset) {
+			t.Fatalf("skipped ahead: expected %v, got %v", repository.ErrUploadOffset, err)
+		}
+		if _, err := service.UploadChunk(ctx, "someone-else", upload.Id, int64(offset), chunk); !errors.Is(err, repository.ErrForbidden) {
+			t.Fatalf("other owner: expected %v, got %v", repository.ErrForbidden, err)
+		}
+
+		upload, err = service.UploadChunk(ctx, "owner", upload.Id, int64(offset), chunk)
+		if err != nil {
+			t.Fatalf("chunk at %d: %v", offset, err)
+		}
+		if upload.Received != int64(offset+len(chunk)) {
+			t.Fatalf("expected %d bytes received, got %d", offset+len(chunk), upload.Received)
+		}
+
+		if media, err := mediaRepository.GetById(ctx, upload.Id); err == nil && !media.IsReady() {
+			parts = media.Parts
+		}
+	}
+
+	if upload.Status != domain.MediaStatusReady || upload.Width != 64 || upload.Height != 32 {
+		t.Fatalf("expected ready 64x32 media, got %+v", upload)
+	}
+	assertImageSize(t, stored(t, service, upload.Url), 64, 32)
+	assertImageSize(t, stored(t, service, upload.ThumbnailUrl), 16, 8)
+
+	for _, key := range parts {
+		if _, err := service.storage.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("expected chunk %s to be deleted, got %v", key, err)
+		}
+	}
+
+	media, err := mediaRepository.GetById(ctx, upload.Id)
+	if err != nil || !media.IsReady() || media.Url != upload.Url || len(media.Parts) != 0 {
+		t.Errorf("expected stored media to be ready at %s, got %+v (%v)", upload.Url, media, err)
+	}
+
+	if _, err := service.UploadChunk(ctx, "owner", upload.Id, int64(len(data)), nil); !errors.Is(err, repository.ErrUploadOffset) {
+		t.Errorf("expected a chunk after completion to be rejected, got %v", err)
+	}
+}
+
+func TestMediaServiceChunkedUploadRejects(t *testing.T) {
+	notImage := bytes.Repeat([]byte("not an image "), 500)
+
+	tests := []struct {
+		name string
+		size int64
+		// chunks are sent one after the other from offset 0, and the last
+		// one fails with wantErr.
+		chunks  [][]byte
+		wantErr error
+		// wantDeleted is set when the failure discards the upload.
+		wantDeleted bool
+	}{
+		{name: "chunk larger than the chunk size", size: 10_000, chunks: [][]byte{make([]byte, 4097)}, wantErr: repository.ErrImageTooLarge},
+		{name: "chunk past the declared size", size: 100, chunks: [][]byte{make([]byte, 101)}, wantErr: repository.ErrInvalidMedia},
+		{
+			name:        "assembled bytes are not an image",
+			size:        int64(len(notImage)),
+			chunks:      [][]byte{notImage[:4096], notImage[4096:]},
+			wantErr:     repository.ErrInvalidImage,
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestMediaService(t)
+			ctx := context.Background()
+
+			upload, err := service.CreateUpload(ctx, "owner", &dto.CreateUploadReq{Size: tt.size})
+			if err != nil {
+				t.Fatalf("create upload: %v", err)
+			}
+
+			var offset int64
+			last := len(tt.chunks) - 1
+			for _, chunk := range tt.chunks[:last] {
+				if _, err := service.UploadChunk(ctx, "owner", upload.Id, offset, chunk); err != nil {
+					t.Fatalf("chunk at %d: %v", offset, err)
+				}
+				offset += int64(len(chunk))
+			}
+
+			media, err := service.mediaRepository.GetById(ctx, upload.Id)
+			if err != nil {
+				t.Fatalf("get media: %v", err)
+			}
+
+			if _, err := service.UploadChunk(ctx, "owner", upload.Id, offset, tt.chunks[last]); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			_, err = service.mediaRepository.GetById(ctx, upload.Id)
+			if deleted := errors.Is(err, repository.ErrRecordNotFound); deleted != tt.wantDeleted {
+				t.Errorf("expected deleted %v, got %v", tt.wantDeleted, err)
+			}
+			if !tt.wantDeleted {
+				return
+			}
+			for _, key := range media.Parts {
+				if _, err := service.storage.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+					t.Errorf("expected chunk %s to be deleted, got %v", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMediaServiceChunkedUploadConcurrentChunk(t *testing.T) {
+	service := newTestMediaService(t)
+	ctx := context.Background()
+	mediaRepository := service.mediaRepository.(*fakeMediaRepository)
+
+	data := testPNG(t, 200, 100)
+	chunkSize := int(service.config.Media.ChunkSize)
+
+	upload, err := service.CreateUpload(ctx, "owner", &dto.CreateUploadReq{Size: int64(len(data))})
+	if err != nil {
+		t.Fatalf("create upload: %v", err)
+	}
+
+	// The same first chunk arrives twice. The second send claims the offset
+	// after the first stored its chunk but before the first claimed it.
+	first := data[:chunkSize]
+	mediaRepository.beforeAddPart = func() {
+		mediaRepository.beforeAddPart = nil
+		if _, err := service.UploadChunk(ctx, "owner", upload.Id, 0, first); err != nil {
+			t.Errorf("concurrent chunk: %v", err)
+		}
+	}
+	if _, err := service.UploadChunk(ctx, "owner", upload.Id, 0, first); !errors.Is(err, repository.ErrUploadOffset) {
+		t.Fatalf("expected the losing send to get %v, got %v", repository.ErrUploadOffset, err)
+	}
+
+	for offset := chunkSize; offset < len(data); offset += chunkSize {
+		if upload, err = service.UploadChunk(ctx, "owner", upload.Id, int64(offset), data[offset:min(offset+chunkSize, len(data))]); err != nil {
+			t.Fatalf("chunk at %d: %v", offset, err)
+		}
+	}
+
+	if upload.Status != domain.MediaStatusReady {
+		t.Fatalf("expected ready media, got %+v", upload)
+	}
+	assertImageSize(t, stored(t, service, upload.Url), 64, 32)
+}
+
+// ImportLegacy is what migrate-media hands to PostRepository.MigrateLegacyFiles
+// to turn a post's base64 selected_file into a media reference.
+func TestMediaServiceImportLegacy(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testPNG(t, 100, 100))
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{name: "base64", data: encoded},
+		{name: "base64 with trailing newline", data: encoded + "\n"},
+		{name: "data url", data: "data:image/png;base64," + encoded},
+		{name: "invalid base64", data: "data:image/png;base64,not base64!", wantErr: repository.ErrInvalidImage},
+		{name: "not an image", data: base64.StdEncoding.EncodeToString([]byte("hello")), wantErr: repository.ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestMediaService(t)
+			ctx := context.Background()
+			mediaRepository := service.mediaRepository.(*fakeMediaRepository)
+
+			attachment, err := service.ImportLegacy(ctx, "post", "creator", tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				if len(mediaRepository.media) != 0 {
+					t.Errorf("expected no media to be kept, got %d", len(mediaRepository.media))
+				}
+				return
+			}
+
+			media, err := mediaRepository.GetById(ctx, attachment.MediaId)
+			if err != nil {
+				t.Fatalf("get media: %v", err)
+			}
+			if media.PostId != "post" || media.OwnerId != "creator" || !media.IsReady() {
+				t.Errorf("expected ready media on post owned by creator, got %+v", media)
+			}
+
+			want := domain.Attachment{
+				MediaId:      media.Id,
+				Url:          testMediaBaseURL + "/media/creator/" + media.Id + ".jpg",
+				ThumbnailUrl: testMediaBaseURL + "/media/creator/" + media.Id + "_thumb.jpg",
+				Width:        64,
+				Height:       64,
+			}
+			if *attachment != want {
+				t.Errorf("expected attachment %+v, got %+v", want, *attachment)
+			}
+			assertImageSize(t, stored(t, service, attachment.Url), 64, 64)
+			assertImageSize(t, stored(t, service, attachment.ThumbnailUrl), 16, 16)
+		})
+	}
+}
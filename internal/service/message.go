@@ -17,9 +17,18 @@ type MessageService interface {
 type messageService struct {
 	messageRepository repository.MessageRepository
 	unreadRepository  repository.UnreadMessageRepository
+	blockRepository   repository.BlockRepository
 }
 
 func (m *messageService) SendMessage(ctx context.Context, input *dto.MessageReq) (*dto.MessageResp, error) {
+	blocked, err := m.blockRepository.IsBlocked(ctx, input.Sender, input.Receiver)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, repository.ErrBlocked
+	}
+
 	message := m.toMessage(input)
 	if err := m.messageRepository.CreateMessage(ctx, message); err != nil {
 		return nil, err
@@ -105,9 +114,10 @@ func (m *messageService) toMessageResp(input *domain.Message) *dto.MessageResp {
 	}
 }
 
-func NewMessageService(messageRepository repository.MessageRepository, unreadRepository repository.UnreadMessageRepository) MessageService {
+func NewMessageService(messageRepository repository.MessageRepository, unreadRepository repository.UnreadMessageRepository, blockRepository repository.BlockRepository) MessageService {
 	return &messageService{
 		messageRepository: messageRepository,
 		unreadRepository:  unreadRepository,
+		blockRepository:   blockRepository,
 	}
 }
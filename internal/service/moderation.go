@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"time"
+)
+
+// ModerationService performs privileged actions on other users' content and
+// accounts. Permission checks happen in the route middleware; every action
+// taken here is recorded in the moderation log.
+type ModerationService interface {
+	DeletePost(ctx context.Context, actorId, postId, reason string) error
+	DeleteComment(ctx context.Context, actorId, commentId, reason string) error
+	UpdateRole(ctx context.Context, actorId, userId string, input *dto.UpdateRoleReq) error
+	GetActions(ctx context.Context, page, limit int) ([]*dto.ModerationActionResp, int64, error)
+}
+
+type moderationService struct {
+	userRepository       repository.UserRepository
+	postRepository       repository.PostRepository
+	commentRepository    repository.CommentRepository
+	moderationRepository repository.ModerationRepository
+	mediaService         MediaService
+}
+
+func (m *moderationService) DeletePost(ctx context.Context, actorId, postId, reason string) error {
+	post, err := m.postRepository.GetPostById(ctx, postId)
+	if err != nil {
+		return err
+	}
+
+	if err := m.postRepository.DeletePost(ctx, postId); err != nil {
+		return err
+	}
+
+	if err := deleteShares(ctx, m.postRepository, post); err != nil {
+		return fmt.Errorf("failed to delete reposts: %w", err)
+	}
+
+	if err := m.mediaService.DeleteByPostId(ctx, postId, nil); err != nil {
+		return fmt.Errorf("failed to delete media: %w", err)
+	}
+
+	return m.record(ctx, &domain.ModerationAction{
+		ActorId:       actorId,
+		Action:        domain.ModerationActionDeletePost,
+		TargetId:      post.Id,
+		TargetOwnerId: post.Creator,
+		Reason:        reason,
+		Details:       post.Title,
+	})
+}
+
+func (m *moderationService) DeleteComment(ctx context.Context, actorId, commentId, reason string) error {
+	comment, err := m.commentRepository.GetCommentById(ctx, commentId)
+	if err != nil {
+		return err
+	}
+
+	if comment.Deleted {
+		return repository.ErrRecordNotFound
+	}
+
+	if err := deleteComment(ctx, m.commentRepository, comment); err != nil {
+		return err
+	}
+
+	_ = m.postRepository.RemoveCommentFromPost(ctx, comment.PostId, commentId)
+
+	return m.record(ctx, &domain.ModerationAction{
+		ActorId:       actorId,
+		Action:        domain.ModerationActionDeleteComment,
+		TargetId:      comment.Id,
+		TargetOwnerId: comment.UserId,
+		Reason:        reason,
+		Details:       comment.Value,
+	})
+}
+
+func (m *moderationService) UpdateRole(ctx context.Context, actorId, userId string, input *dto.UpdateRoleReq) error {
+	// Admins cannot change their own role, so the last admin can't lock everyone out.
+	if actorId == userId {
+		return repository.ErrForbidden
+	}
+
+	user, err := m.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := m.userRepository.UpdateRole(ctx, userId, input.Role); err != nil {
+		return err
+	}
+
+	previous := user.Role
+	if previous == "" {
+		previous = domain.RoleUser
+	}
+
+	return m.record(ctx, &domain.ModerationAction{
+		ActorId:       actorId,
+		Action:        domain.ModerationActionChangeRole,
+		TargetId:      user.Id,
+		TargetOwnerId: user.Id,
+		Reason:        input.Reason,
+		Details:       fmt.Sprintf("%s -> %s", previous, input.Role),
+	})
+}
+
+func (m *moderationService) GetActions(ctx context.Context, page, limit int) ([]*dto.ModerationActionResp, int64, error) {
+	actions, total, err := m.moderationRepository.GetActions(ctx, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp := make([]*dto.ModerationActionResp, len(actions))
+	for i, action := range actions {
+		resp[i] = &dto.ModerationActionResp{
+			Id:            action.Id,
+			ActorId:       action.ActorId,
+			Action:        action.Action,
+			TargetId:      action.TargetId,
+			TargetOwnerId: action.TargetOwnerId,
+			Reason:        action.Reason,
+			Details:       action.Details,
+			CreatedAt:     action.CreatedAt,
+		}
+	}
+
+	return resp, total, nil
+}
+
+func (m *moderationService) record(ctx context.Context, action *domain.ModerationAction) error {
+	action.CreatedAt = time.Now()
+	if err := m.moderationRepository.Create(ctx, action); err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+	return nil
+}
+
+func NewModerationService(userRepository repository.UserRepository, postRepository repository.PostRepository, commentRepository repository.CommentRepository, moderationRepository repository.ModerationRepository, mediaService MediaService) ModerationService {
+	return &moderationService{
+		userRepository:       userRepository,
+		postRepository:       postRepository,
+		commentRepository:    commentRepository,
+		moderationRepository: moderationRepository,
+		mediaService:         mediaService,
+	}
+}
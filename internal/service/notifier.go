@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+)
+
+// notifier creates notifications on behalf of the services, dropping those
+// between users who blocked each other or whose receiver muted the sender.
+type notifier struct {
+	notificationRepository repository.NotificationRepository
+	blockRepository        repository.BlockRepository
+	muteRepository         repository.MuteRepository
+}
+
+// notify stores the notification unless it is suppressed. Like before, a
+// failed notification never fails the action that triggered it.
+func (n *notifier) notify(ctx context.Context, notif *domain.Notification) {
+	if notif.SenderId != notif.ReceiverId {
+		if blocked, err := n.blockRepository.IsBlocked(ctx, notif.SenderId, notif.ReceiverId); err != nil || blocked {
+			return
+		}
+		if muted, err := n.muteRepository.IsMuted(ctx, notif.ReceiverId, notif.SenderId); err != nil || muted {
+			return
+		}
+	}
+
+	_ = n.notificationRepository.Create(ctx, notif)
+}
+
+func newNotifier(notificationRepository repository.NotificationRepository, blockRepository repository.BlockRepository, muteRepository repository.MuteRepository) *notifier {
+	return &notifier{
+		notificationRepository: notificationRepository,
+		blockRepository:        blockRepository,
+		muteRepository:         muteRepository,
+	}
+}
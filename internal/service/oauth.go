@@ -0,0 +1,427 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type OAuthService interface {
+	RegisterClient(ctx context.Context, ownerId string, input *dto.CreateOAuthClientReq) (*dto.CreatedOAuthClientResp, error)
+	GetClients(ctx context.Context, ownerId string) ([]*dto.OAuthClientResp, error)
+	DeleteClient(ctx context.Context, ownerId, clientId string) error
+	GetConsent(ctx context.Context, userId string, input *dto.AuthorizeReq) (*dto.ConsentResp, error)
+	Authorize(ctx context.Context, userId string, input *dto.AuthorizeDecisionReq) (*dto.AuthorizeResp, error)
+	Token(ctx context.Context, input *dto.OAuthTokenReq) (*dto.OAuthTokenResp, error)
+	Revoke(ctx context.Context, input *dto.OAuthRevokeReq) error
+	GetAuthorizations(ctx context.Context, userId string) ([]*dto.OAuthAuthorizationResp, error)
+	RevokeAuthorization(ctx context.Context, userId, clientId string) error
+	Authenticate(ctx context.Context, token string) (*domain.OAuthToken, error)
+}
+
+type oauthService struct {
+	config                *config.Config
+	oauthClientRepository repository.OAuthClientRepository
+	oauthGrantRepository  repository.OAuthGrantRepository
+	oauthTokenRepository  repository.OAuthTokenRepository
+	oauthCodeRepository   repository.OAuthCodeRepository
+	auditLogService       AuditLogService
+}
+
+func (o *oauthService) RegisterClient(ctx context.Context, ownerId string, input *dto.CreateOAuthClientReq) (*dto.CreatedOAuthClientResp, error) {
+	client := &domain.OAuthClient{
+		OwnerId:      ownerId,
+		Name:         input.Name,
+		Public:       input.Public,
+		RedirectURIs: input.RedirectURIs,
+		Scopes:       input.Scopes,
+		CreatedAt:    time.Now(),
+	}
+
+	// Public clients such as mobile apps cannot keep a secret and rely on PKCE alone.
+	var secret string
+	if !client.Public {
+		var err error
+		secret, err = utils.GenerateOpaqueToken(utils.OAuthClientSecretPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate client secret: %w", err)
+		}
+		client.ClientSecretHash = utils.HashToken(secret)
+	}
+
+	if err := o.oauthClientRepository.Create(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to create oauth client: %w", err)
+	}
+
+	return &dto.CreatedOAuthClientResp{
+		OAuthClientResp: *o.toOAuthClientResp(client),
+		ClientSecret:    secret,
+	}, nil
+}
+
+func (o *oauthService) GetClients(ctx context.Context, ownerId string) ([]*dto.OAuthClientResp, error) {
+	clients, err := o.oauthClientRepository.GetByOwnerId(ctx, ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dto.OAuthClientResp, len(clients))
+	for i, client := range clients {
+		resp[i] = o.toOAuthClientResp(client)
+	}
+
+	return resp, nil
+}
+
+func (o *oauthService) DeleteClient(ctx context.Context, ownerId, clientId string) error {
+	if err := o.oauthClientRepository.Delete(ctx, clientId, ownerId); err != nil {
+		return err
+	}
+
+	if err := o.oauthGrantRepository.DeleteByClientId(ctx, clientId); err != nil {
+		return fmt.Errorf("failed to delete grants: %w", err)
+	}
+
+	if err := o.oauthTokenRepository.DeleteByClientId(ctx, clientId); err != nil {
+		return fmt.Errorf("failed to delete tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (o *oauthService) GetConsent(ctx context.Context, userId string, input *dto.AuthorizeReq) (*dto.ConsentResp, error) {
+	client, scopes, err := o.resolveAuthorizeReq(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make([]string, 0)
+	grant, err := o.oauthGrantRepository.Get(ctx, userId, client.Id)
+	switch {
+	case err == nil:
+		granted = grant.Scopes
+	case !errors.Is(err, repository.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to get grant: %w", err)
+	}
+
+	return &dto.ConsentResp{
+		Client:          *o.toOAuthClientResp(client),
+		RequestedScopes: scopes,
+		GrantedScopes:   granted,
+		RedirectURI:     input.RedirectURI,
+	}, nil
+}
+
+func (o *oauthService) Authorize(ctx context.Context, userId string, input *dto.AuthorizeDecisionReq) (*dto.AuthorizeResp, error) {
+	client, scopes, err := o.resolveAuthorizeReq(ctx, &input.AuthorizeReq)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	if input.State != "" {
+		params.Set("state", input.State)
+	}
+
+	if !input.Approve {
+		params.Set("error", "access_denied")
+		return &dto.AuthorizeResp{RedirectTo: withQuery(input.RedirectURI, params)}, nil
+	}
+
+	code, err := utils.GenerateOpaqueToken(utils.OAuthCodePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	if err := o.oauthCodeRepository.Save(ctx, utils.HashToken(code), &domain.OAuthAuthorizationCode{
+		ClientId:      client.Id,
+		UserId:        userId,
+		RedirectURI:   input.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: input.CodeChallenge,
+	}, o.config.OAuth.CodeTTL); err != nil {
+		return nil, fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	if err := o.oauthGrantRepository.Upsert(ctx, userId, client.Id, scopes); err != nil {
+		return nil, fmt.Errorf("failed to save grant: %w", err)
+	}
+
+	params.Set("code", code)
+	return &dto.AuthorizeResp{RedirectTo: withQuery(input.RedirectURI, params)}, nil
+}
+
+func (o *oauthService) Token(ctx context.Context, input *dto.OAuthTokenReq) (*dto.OAuthTokenResp, error) {
+	client, err := o.authenticateClient(ctx, input.ClientId, input.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch input.GrantType {
+	case "authorization_code":
+		code, err := o.oauthCodeRepository.Take(ctx, utils.HashToken(input.Code))
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return nil, repository.ErrInvalidGrant
+			}
+			return nil, err
+		}
+
+		if code.ClientId != client.Id || code.RedirectURI != input.RedirectURI {
+			return nil, repository.ErrInvalidGrant
+		}
+
+		if !utils.VerifyCodeChallengeS256(input.CodeVerifier, code.CodeChallenge) {
+			return nil, repository.ErrInvalidGrant
+		}
+
+		return o.issueToken(ctx, client.Id, code.UserId, code.Scopes)
+
+	case "refresh_token":
+		token, err := o.oauthTokenRepository.GetByRefreshTokenHash(ctx, utils.HashToken(input.RefreshToken))
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return nil, repository.ErrInvalidGrant
+			}
+			return nil, err
+		}
+
+		if token.ClientId != client.Id || !time.Now().Before(token.RefreshExpiresAt) {
+			return nil, repository.ErrInvalidGrant
+		}
+
+		// Refresh tokens rotate: the old pair stops working as soon as a new one is issued.
+		if err := o.oauthTokenRepository.Delete(ctx, token.Id); err != nil {
+			return nil, fmt.Errorf("failed to rotate token: %w", err)
+		}
+
+		return o.issueToken(ctx, client.Id, token.UserId, token.Scopes)
+
+	default:
+		return nil, repository.ErrUnsupportedGrantType
+	}
+}
+
+// Revoke implements RFC 7009. Unknown tokens are not an error so callers cannot probe for valid tokens.
+func (o *oauthService) Revoke(ctx context.Context, input *dto.OAuthRevokeReq) error {
+	client, err := o.authenticateClient(ctx, input.ClientId, input.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	hash := utils.HashToken(input.Token)
+	token, err := o.oauthTokenRepository.GetByAccessTokenHash(ctx, hash)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		token, err = o.oauthTokenRepository.GetByRefreshTokenHash(ctx, hash)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if token.ClientId != client.Id {
+		return nil
+	}
+
+	return o.oauthTokenRepository.Delete(ctx, token.Id)
+}
+
+func (o *oauthService) GetAuthorizations(ctx context.Context, userId string) ([]*dto.OAuthAuthorizationResp, error) {
+	grants, err := o.oauthGrantRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIds := make([]string, len(grants))
+	for i, grant := range grants {
+		clientIds[i] = grant.ClientId
+	}
+
+	clients, err := o.oauthClientRepository.GetByIds(ctx, clientIds)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(clients))
+	for _, client := range clients {
+		names[client.Id] = client.Name
+	}
+
+	resp := make([]*dto.OAuthAuthorizationResp, 0, len(grants))
+	for _, grant := range grants {
+		resp = append(resp, &dto.OAuthAuthorizationResp{
+			ClientId:   grant.ClientId,
+			ClientName: names[grant.ClientId],
+			Scopes:     grant.Scopes,
+			CreatedAt:  grant.CreatedAt,
+			UpdatedAt:  grant.UpdatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (o *oauthService) RevokeAuthorization(ctx context.Context, userId, clientId string) error {
+	if err := o.oauthGrantRepository.Delete(ctx, userId, clientId); err != nil {
+		return err
+	}
+
+	if err := o.oauthTokenRepository.DeleteByUserAndClient(ctx, userId, clientId); err != nil {
+		return fmt.Errorf("failed to delete tokens: %w", err)
+	}
+
+	o.auditLogService.Record(ctx, &domain.AuditEvent{
+		UserId:  userId,
+		Event:   domain.AuditEventSessionRevocation,
+		Outcome: domain.AuditOutcomeSuccess,
+		Details: "oauth client " + clientId,
+	})
+
+	return nil
+}
+
+func (o *oauthService) Authenticate(ctx context.Context, token string) (*domain.OAuthToken, error) {
+	oauthToken, err := o.oauthTokenRepository.GetByAccessTokenHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, utils.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if !time.Now().Before(oauthToken.AccessExpiresAt) {
+		return nil, utils.ErrInvalidToken
+	}
+
+	return oauthToken, nil
+}
+
+func (o *oauthService) resolveAuthorizeReq(ctx context.Context, input *dto.AuthorizeReq) (*domain.OAuthClient, []string, error) {
+	client, err := o.oauthClientRepository.GetById(ctx, input.ClientId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, nil, repository.ErrInvalidClient
+		}
+		return nil, nil, err
+	}
+
+	if !slices.Contains(client.RedirectURIs, input.RedirectURI) {
+		return nil, nil, repository.ErrInvalidRedirectURI
+	}
+
+	scopes := strings.Fields(input.Scope)
+	for _, scope := range scopes {
+		if !slices.Contains(client.Scopes, scope) {
+			return nil, nil, repository.ErrInvalidScope
+		}
+	}
+
+	return client, slices.Compact(slices.Sorted(slices.Values(scopes))), nil
+}
+
+func (o *oauthService) authenticateClient(ctx context.Context, clientId, clientSecret string) (*domain.OAuthClient, error) {
+	client, err := o.oauthClientRepository.GetById(ctx, clientId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, repository.ErrInvalidClient
+		}
+		return nil, err
+	}
+
+	if client.Public {
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(client.ClientSecretHash)) != 1 {
+		return nil, repository.ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+func (o *oauthService) issueToken(ctx context.Context, clientId, userId string, scopes []string) (*dto.OAuthTokenResp, error) {
+	accessToken, err := utils.GenerateOpaqueToken(utils.OAuthAccessTokenPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken(utils.OAuthRefreshTokenPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := time.Now()
+	token := &domain.OAuthToken{
+		ClientId:         clientId,
+		UserId:           userId,
+		Scopes:           scopes,
+		AccessTokenHash:  utils.HashToken(accessToken),
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		AccessExpiresAt:  now.Add(o.config.OAuth.AccessTokenTTL),
+		RefreshExpiresAt: now.Add(o.config.OAuth.RefreshTokenTTL),
+		CreatedAt:        now,
+	}
+
+	if err := o.oauthTokenRepository.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	return &dto.OAuthTokenResp{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(o.config.OAuth.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(scopes, " "),
+	}, nil
+}
+
+func (o *oauthService) toOAuthClientResp(input *domain.OAuthClient) *dto.OAuthClientResp {
+	return &dto.OAuthClientResp{
+		ClientId:     input.Id,
+		Name:         input.Name,
+		RedirectURIs: input.RedirectURIs,
+		Scopes:       input.Scopes,
+		Public:       input.Public,
+		CreatedAt:    input.CreatedAt,
+	}
+}
+
+func withQuery(rawURL string, params url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func NewOAuthService(config *config.Config, oauthClientRepository repository.OAuthClientRepository, oauthGrantRepository repository.OAuthGrantRepository, oauthTokenRepository repository.OAuthTokenRepository, oauthCodeRepository repository.OAuthCodeRepository, auditLogService AuditLogService) OAuthService {
+	return &oauthService{
+		config:                config,
+		oauthClientRepository: oauthClientRepository,
+		oauthGrantRepository:  oauthGrantRepository,
+		oauthTokenRepository:  oauthTokenRepository,
+		oauthCodeRepository:   oauthCodeRepository,
+		auditLogService:       auditLogService,
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const (
+	testClientSecret = "xgs_client-secret"
+	testRedirectURI  = "https://app.example.com/callback"
+	testCodeVerifier = "a-code-verifier-long-enough-for-rfc-7636-compliance"
+)
+
+// newTestOAuthService has a confidential client "client", a public client
+// "public" and a confidential client "other", all registered for
+// testRedirectURI.
+func newTestOAuthService() *oauthService {
+	client := func(id string, public bool) *domain.OAuthClient {
+		c := &domain.OAuthClient{Id: id, Public: public, RedirectURIs: []string{testRedirectURI}, Scopes: []string{"posts:read", "posts:write"}}
+		if !public {
+			c.ClientSecretHash = utils.HashToken(testClientSecret)
+		}
+		return c
+	}
+
+	return &oauthService{
+		config: &config.Config{OAuth: config.OAuth{CodeTTL: time.Minute, AccessTokenTTL: time.Hour, RefreshTokenTTL: 24 * time.Hour}},
+		oauthClientRepository: &fakeOAuthClientRepository{clients: map[string]*domain.OAuthClient{
+			"client": client("client", false),
+			"public": client("public", true),
+			"other":  client("other", false),
+		}},
+		oauthGrantRepository: &fakeOAuthGrantRepository{},
+		oauthTokenRepository: &fakeOAuthTokenRepository{},
+		oauthCodeRepository:  &fakeOAuthCodeRepository{},
+		auditLogService:      &fakeAuditLogService{},
+	}
+}
+
+// authorizationCode has user "1" approve clientId and returns the code from
+// the redirect.
+func authorizationCode(t *testing.T, service *oauthService, clientId string) string {
+	t.Helper()
+
+	resp, err := service.Authorize(context.Background(), "1", &dto.AuthorizeDecisionReq{
+		AuthorizeReq: dto.AuthorizeReq{
+			ResponseType:        "code",
+			ClientId:            clientId,
+			RedirectURI:         testRedirectURI,
+			Scope:               "posts:read",
+			State:               "xyz",
+			CodeChallenge:       utils.CodeChallengeS256(testCodeVerifier),
+			CodeChallengeMethod: "S256",
+		},
+		Approve: true,
+	})
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+
+	redirect, err := url.Parse(resp.RedirectTo)
+	if err != nil {
+		t.Fatalf("parse redirect %q: %v", resp.RedirectTo, err)
+	}
+	if redirect.Query().Get("state") != "xyz" || redirect.Query().Get("code") == "" {
+		t.Fatalf("expected state and code in %s", resp.RedirectTo)
+	}
+
+	return redirect.Query().Get("code")
+}
+
+func codeTokenReq(code string) *dto.OAuthTokenReq {
+	return &dto.OAuthTokenReq{
+		GrantType:    "authorization_code",
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: testCodeVerifier,
+		ClientId:     "client",
+		ClientSecret: testClientSecret,
+	}
+}
+
+func TestOAuthAuthorizeRejectsUnregisteredRedirectURI(t *testing.T) {
+	service := newTestOAuthService()
+
+	_, err := service.Authorize(context.Background(), "1", &dto.AuthorizeDecisionReq{
+		AuthorizeReq: dto.AuthorizeReq{ClientId: "client", RedirectURI: "https://evil.example.com/callback", Scope: "posts:read"},
+		Approve:      true,
+	})
+	if !errors.Is(err, repository.ErrInvalidRedirectURI) {
+		t.Errorf("expected %v, got %v", repository.ErrInvalidRedirectURI, err)
+	}
+}
+
+func TestOAuthTokenAuthorizationCode(t *testing.T) {
+	tests := []struct {
+		name string
+		// issuedTo is the client the code is issued to, "client" when empty.
+		issuedTo string
+		tamper   func(req *dto.OAuthTokenReq)
+		wantErr  error
+	}{
+		{name: "valid"},
+		{
+			name:     "public client without a secret",
+			issuedTo: "public",
+			tamper:   func(req *dto.OAuthTokenReq) { req.ClientId, req.ClientSecret = "public", "" },
+		},
+		{
+			name:    "pkce verifier mismatch",
+			tamper:  func(req *dto.OAuthTokenReq) { req.CodeVerifier += "x" },
+			wantErr: repository.ErrInvalidGrant,
+		},
+		{
+			name:    "missing pkce verifier",
+			tamper:  func(req *dto.OAuthTokenReq) { req.CodeVerifier = "" },
+			wantErr: repository.ErrInvalidGrant,
+		},
+		{
+			name:    "redirect uri mismatch",
+			tamper:  func(req *dto.OAuthTokenReq) { req.RedirectURI = "https://app.example.com/other" },
+			wantErr: repository.ErrInvalidGrant,
+		},
+		{
+			name:    "code issued to another client",
+			tamper:  func(req *dto.OAuthTokenReq) { req.ClientId = "other" },
+			wantErr: repository.ErrInvalidGrant,
+		},
+		{
+			name:    "wrong client secret",
+			tamper:  func(req *dto.OAuthTokenReq) { req.ClientSecret = "xgs_wrong" },
+			wantErr: repository.ErrInvalidClient,
+		},
+		{
+			name:    "unknown code",
+			tamper:  func(req *dto.OAuthTokenReq) { req.Code = "xgc_unknown" },
+			wantErr: repository.ErrInvalidGrant,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestOAuthService()
+			ctx := context.Background()
+
+			clientId := tt.issuedTo
+			if clientId == "" {
+				clientId = "client"
+			}
+
+			req := codeTokenReq(authorizationCode(t, service, clientId))
+			if tt.tamper != nil {
+				tt.tamper(req)
+			}
+
+			resp, err := service.Token(ctx, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			token, err := service.Authenticate(ctx, resp.AccessToken)
+			if err != nil {
+				t.Fatalf("authenticate issued token: %v", err)
+			}
+			if token.UserId != "1" || token.ClientId != clientId || resp.Scope != "posts:read" {
+				t.Errorf("expected a posts:read token for user 1 and %s, got %+v (%s)", clientId, token, resp.Scope)
+			}
+		})
+	}
+}
+
+func TestOAuthTokenCodeIsSingleUse(t *testing.T) {
+	service := newTestOAuthService()
+	ctx := context.Background()
+	code := authorizationCode(t, service, "client")
+
+	if _, err := service.Token(ctx, codeTokenReq(code)); err != nil {
+		t.Fatalf("redeem code: %v", err)
+	}
+	if _, err := service.Token(ctx, codeTokenReq(code)); !errors.Is(err, repository.ErrInvalidGrant) {
+		t.Errorf("expected a replayed code to be rejected, got %v", err)
+	}
+
+	// A failed redemption uses the code up too.
+	code = authorizationCode(t, service, "client")
+	bad := codeTokenReq(code)
+	bad.CodeVerifier += "x"
+	if _, err := service.Token(ctx, bad); !errors.Is(err, repository.ErrInvalidGrant) {
+		t.Fatalf("expected pkce mismatch, got %v", err)
+	}
+	if _, err := service.Token(ctx, codeTokenReq(code)); !errors.Is(err, repository.ErrInvalidGrant) {
+		t.Errorf("expected the code to be gone after a failed redemption, got %v", err)
+	}
+}
+
+func TestOAuthTokenRefreshRotates(t *testing.T) {
+	service := newTestOAuthService()
+	ctx := context.Background()
+
+	first, err := service.Token(ctx, codeTokenReq(authorizationCode(t, service, "client")))
+	if err != nil {
+		t.Fatalf("redeem code: %v", err)
+	}
+
+	refresh := func(refreshToken, clientId string) (*dto.OAuthTokenResp, error) {
+		return service.Token(ctx, &dto.OAuthTokenReq{
+			GrantType:    "refresh_token",
+			RefreshToken: refreshToken,
+			ClientId:     clientId,
+			ClientSecret: testClientSecret,
+		})
+	}
+
+	if _, err := refresh(first.RefreshToken, "other"); !errors.Is(err, repository.ErrInvalidGrant) {
+		t.Fatalf("expected another client's refresh to be rejected, got %v", err)
+	}
+
+	second, err := refresh(first.RefreshToken, "client")
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if second.AccessToken == first.AccessToken || second.RefreshToken == first.RefreshToken || second.Scope != first.Scope {
+		t.Errorf("expected a new pair with the same scope, got %+v after %+v", second, first)
+	}
+
+	if _, err := refresh(first.RefreshToken, "client"); !errors.Is(err, repository.ErrInvalidGrant) {
+		t.Errorf("expected the rotated refresh token to be rejected, got %v", err)
+	}
+	if _, err := service.Authenticate(ctx, first.AccessToken); !errors.Is(err, utils.ErrInvalidToken) {
+		t.Errorf("expected the rotated access token to be rejected, got %v", err)
+	}
+	if _, err := service.Authenticate(ctx, second.AccessToken); err != nil {
+		t.Errorf("expected the new access token to work, got %v", err)
+	}
+
+	// An expired refresh token no longer refreshes.
+	tokens := service.oauthTokenRepository.(*fakeOAuthTokenRepository)
+	stored, err := tokens.GetByRefreshTokenHash(ctx, utils.HashToken(second.RefreshToken))
+	if err != nil {
+		t.Fatalf("get token: %v", err)
+	}
+	stored.RefreshExpiresAt = time.Now().Add(-time.Second)
+	if _, err := refresh(second.RefreshToken, "client"); !errors.Is(err, repository.ErrInvalidGrant) {
+		t.Errorf("expected an expired refresh token to be rejected, got %v", err)
+	}
+}
+
+func TestOAuthRevoke(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       func(resp *dto.OAuthTokenResp) string
+		clientId    string
+		wantErr     error
+		wantRevoked bool
+	}{
+		{name: "access token", token: func(resp *dto.OAuthTokenResp) string { return resp.AccessToken }, clientId: "client", wantRevoked: true},
+		{name: "refresh token", token: func(resp *dto.OAuthTokenResp) string { return resp.RefreshToken }, clientId: "client", wantRevoked: true},
+		{name: "another client's token", token: func(resp *dto.OAuthTokenResp) string { return resp.AccessToken }, clientId: "other"},
+		{name: "unknown token", token: func(resp *dto.OAuthTokenResp) string { return "xgo_unknown" }, clientId: "client"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newTestOAuthService()
+			ctx := context.Background()
+
+			issued, err := service.Token(ctx, codeTokenReq(authorizationCode(t, service, "client")))
+			if err != nil {
+				t.Fatalf("redeem code: %v", err)
+			}
+
+			err = service.Revoke(ctx, &dto.OAuthRevokeReq{Token: tt.token(issued), ClientId: tt.clientId, ClientSecret: testClientSecret})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			// Revoking either token ends the whole grant of the pair.
+			_, accessErr := service.Authenticate(ctx, issued.AccessToken)
+			_, refreshErr := service.Token(ctx, &dto.OAuthTokenReq{GrantType: "refresh_token", RefreshToken: issued.RefreshToken, ClientId: "client", ClientSecret: testClientSecret})
+			if revoked := accessErr != nil && refreshErr != nil; revoked != tt.wantRevoked {
+				t.Errorf("expected revoked %v, got access error %v and refresh error %v", tt.wantRevoked, accessErr, refreshErr)
+			}
+		})
+	}
+}
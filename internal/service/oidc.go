@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+type OIDCService interface {
+	BeginLogin(ctx context.Context) (*dto.OIDCLoginResp, error)
+	CompleteLogin(ctx context.Context, input *dto.OIDCCallbackReq) (*dto.AuthResp, error)
+}
+
+type oidcService struct {
+	config                 *config.Config
+	provider               *oidc.Provider
+	authService            AuthService
+	userRepository         repository.UserRepository
+	userIdentityRepository repository.UserIdentityRepository
+	oidcStateRepository    repository.OIDCStateRepository
+	auditLogService        AuditLogService
+}
+
+func (o *oidcService) BeginLogin(ctx context.Context) (*dto.OIDCLoginResp, error) {
+	state, err := utils.GenerateOpaqueToken("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	nonce, err := utils.GenerateOpaqueToken("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	verifier, err := utils.GenerateCodeVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	if err := o.oidcStateRepository.Save(ctx, state, &domain.OIDCState{
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}, o.config.OIDC.StateTTL); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	authURL, err := o.provider.AuthCodeURL(ctx, state, nonce, utils.CodeChallengeS256(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.OIDCLoginResp{
+		AuthorizationURL: authURL,
+		State:            state,
+	}, nil
+}
+
+func (o *oidcService) CompleteLogin(ctx context.Context, input *dto.OIDCCallbackReq) (*dto.AuthResp, error) {
+	state, err := o.oidcStateRepository.Take(ctx, input.State)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := o.provider.Exchange(ctx, input.Code, state.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := o.provider.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", utils.ErrInvalidToken, err)
+	}
+
+	identity, err := o.userIdentityRepository.GetByProviderSubject(ctx, o.config.OIDC.Provider, claims.Subject)
+	if err == nil {
+		return o.loginLinked(ctx, identity)
+	}
+	if !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	// Accounts are only linked or created through an email the provider vouches for.
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, repository.ErrEmailNotVerified
+	}
+
+	user, err := o.userRepository.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound):
+		user = o.toUser(claims)
+		if user.Handle, err = o.availableHandle(ctx, claims.Email); err != nil {
+			return nil, err
+		}
+		if user, err = o.createUser(ctx, user); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	if err := o.userIdentityRepository.Create(ctx, &domain.UserIdentity{
+		UserId:    user.Id,
+		Provider:  o.config.OIDC.Provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		// A concurrent callback for the same subject linked it first.
+		if errors.Is(err, repository.ErrIdentityLinked) {
+			identity, err := o.userIdentityRepository.GetByProviderSubject(ctx, o.config.OIDC.Provider, claims.Subject)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get user identity: %w", err)
+			}
+			return o.loginLinked(ctx, identity)
+		}
+		return nil, fmt.Errorf("failed to link user identity: %w", err)
+	}
+
+	return o.authService.CreateSession(ctx, user, o.config.OIDC.Provider)
+}
+
+// loginLinked starts a session for the user the identity is linked to.
+func (o *oidcService) loginLinked(ctx context.Context, identity *domain.UserIdentity) (*dto.AuthResp, error) {
+	user, err := o.userRepository.GetUserById(ctx, identity.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked user: %w", err)
+	}
+
+	return o.authService.CreateSession(ctx, user, o.config.OIDC.Provider)
+}
+
+// createUser signs up the user. When a concurrent callback for the same email
+// created the account first, that account is returned instead, and when
+// another signup took the handle first, a new one is picked.
+func (o *oidcService) createUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	err := o.userRepository.CreateUser(ctx, user)
+	for attempt := 0; errors.Is(err, repository.ErrDuplicateHandle) && attempt < 3; attempt++ {
+		if user.Handle, err = o.availableHandle(ctx, user.Email); err != nil {
+			return nil, err
+		}
+		err = o.userRepository.CreateUser(ctx, user)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			existing, err := o.userRepository.GetUserByEmail(ctx, user.Email)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get user by email: %w", err)
+			}
+			return existing, nil
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	o.auditLogService.Record(ctx, &domain.AuditEvent{
+		UserId:  user.Id,
+		Email:   user.Email,
+		Event:   domain.AuditEventSignup,
+		Outcome: domain.AuditOutcomeSuccess,
+		Details: o.config.OIDC.Provider,
+	})
+
+	return user, nil
+}
+
+// availableHandle derives a free handle from the local part of the email,
+// adding a random suffix when it is already taken. The user can change it later.
+func (o *oidcService) availableHandle(ctx context.Context, email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+	base := strings.Map(func(r rune) rune {
+		if r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+			return r
+		}
+		return -1
+	}, local)
+	if len(base) > 10 {
+		base = base[:10]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	handle := base
+	for range 5 {
+		_, err := o.userRepository.GetUserByHandle(ctx, handle)
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return handle, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get user by handle: %w", err)
+		}
+		handle = fmt.Sprintf("%s_%04d", base, rand.IntN(10000))
+	}
+
+	return "", repository.ErrDuplicateHandle
+}
+
+func (o *oidcService) toUser(claims *oidc.IDTokenClaims) *domain.User {
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	return &domain.User{
+		FirstName: firstName,
+		LastName:  strings.TrimSpace(lastName),
+		Email:     claims.Email,
+		ImageUrl:  claims.Picture,
+		Role:      domain.RoleUser,
+	}
+}
+
+func NewOIDCService(config *config.Config, provider *oidc.Provider, authService AuthService, userRepository repository.UserRepository, userIdentityRepository repository.UserIdentityRepository, oidcStateRepository repository.OIDCStateRepository, auditLogService AuditLogService) OIDCService {
+	return &oidcService{
+		config:                 config,
+		provider:               provider,
+		authService:            authService,
+		userRepository:         userRepository,
+		userIdentityRepository: userIdentityRepository,
+		oidcStateRepository:    oidcStateRepository,
+		auditLogService:        auditLogService,
+	}
+}
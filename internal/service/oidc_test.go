@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/oidc/oidctest"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestOIDCService returns a service signing in through a test issuer, which
+// is returned too, with the given users already registered.
+func newTestOIDCService(t *testing.T, users ...*domain.User) (*oidcService, *oidctest.Issuer) {
+	t.Helper()
+
+	issuer := oidctest.NewIssuer(t, "client-id", "client-secret")
+	cfg := &config.Config{
+		OIDC: config.OIDC{
+			Enabled:      true,
+			Provider:     "test-idp",
+			IssuerURL:    issuer.URL(),
+			ClientID:     issuer.ClientID,
+			ClientSecret: issuer.ClientSecret,
+			RedirectURL:  "https://app.example.com/callback",
+			Scopes:       []string{"openid", "email", "profile"},
+			StateTTL:     time.Minute,
+		},
+	}
+
+	return &oidcService{
+		config: cfg,
+		provider: oidc.NewProvider(
+			oidc.WithIssuerURL(cfg.OIDC.IssuerURL),
+			oidc.WithClientID(cfg.OIDC.ClientID),
+			oidc.WithClientSecret(cfg.OIDC.ClientSecret),
+			oidc.WithRedirectURL(cfg.OIDC.RedirectURL),
+			oidc.WithScopes(cfg.OIDC.Scopes),
+			oidc.WithHTTPClient(issuer.Client()),
+		),
+		authService:            &fakeAuthService{},
+		userRepository:         newFakeUserRepository(users...),
+		userIdentityRepository: &fakeUserIdentityRepository{},
+		oidcStateRepository:    &fakeOIDCStateRepository{},
+		auditLogService:        &fakeAuditLogService{},
+	}, issuer
+}
+
+// oidcLogin runs the whole authorization code flow, letting tamper change the
+// stored state before the callback is handled.
+func oidcLogin(t *testing.T, service *oidcService, issuer *oidctest.Issuer, claims oidc.IDTokenClaims, tamper func(*domain.OIDCState)) (*dto.AuthResp, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	begin, err := service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	if tamper != nil {
+		tamper(service.oidcStateRepository.(*fakeOIDCStateRepository).states[begin.State])
+	}
+
+	code := issuer.Authorize(t, begin.AuthorizationURL, claims)
+
+	return service.CompleteLogin(ctx, &dto.OIDCCallbackReq{Code: code, State: begin.State})
+}
+
+func idTokenClaims(subject, email string, verified bool) oidc.IDTokenClaims {
+	return oidc.IDTokenClaims{
+		Email:            email,
+		EmailVerified:    verified,
+		GivenName:        "Jane",
+		FamilyName:       "Doe",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+	}
+}
+
+func TestOIDCCompleteLogin(t *testing.T) {
+	existing := &domain.User{Id: "42", Email: "jane@example.com", Handle: "jane", Role: domain.RoleUser}
+
+	tests := []struct {
+		name       string
+		users      []*domain.User
+		email      string
+		wantHandle string
+		// wantSignup is set when the login creates the user.
+		wantSignup bool
+	}{
+		{name: "creates a user", email: "jane.doe@example.com", wantHandle: "janedoe", wantSignup: true},
+		{name: "links the user with the verified email", users: []*domain.User{existing}, email: "jane@example.com", wantHandle: "jane"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, issuer := newTestOIDCService(t, tt.users...)
+			users := service.userRepository.(*fakeUserRepository)
+			identities := service.userIdentityRepository.(*fakeUserIdentityRepository)
+			auditLog := service.auditLogService.(*fakeAuditLogService)
+
+			resp, err := oidcLogin(t, service, issuer, idTokenClaims("subject-1", tt.email, true), nil)
+			if err != nil {
+				t.Fatalf("complete login: %v", err)
+			}
+
+			user, err := users.GetUserByEmail(context.Background(), tt.email)
+			if err != nil {
+				t.Fatalf("get user: %v", err)
+			}
+			if resp.User.Id != user.Id || user.Handle != tt.wantHandle {
+				t.Errorf("unexpected user %+v for session %+v", user, resp.User)
+			}
+
+			if tt.wantSignup {
+				if len(users.users) != len(tt.users)+1 || user.FirstName != "Jane" {
+					t.Errorf("expected Jane to be created, got %+v among %d users", user, len(users.users))
+				}
+				if len(auditLog.events) != 1 || auditLog.events[0].Event != domain.AuditEventSignup {
+					t.Errorf("expected a signup audit event, got %+v", auditLog.events)
+				}
+			} else if len(users.users) != len(tt.users) || len(auditLog.events) != 0 {
+				t.Errorf("expected no signup, got %d users and audit events %+v", len(users.users), auditLog.events)
+			}
+
+			identity, err := identities.GetByProviderSubject(context.Background(), "test-idp", "subject-1")
+			if err != nil || identity.UserId != user.Id {
+				t.Errorf("expected identity linked to %s, got %+v (%v)", user.Id, identity, err)
+			}
+
+			// A second login resolves the user through the linked identity.
+			again, err := oidcLogin(t, service, issuer, idTokenClaims("subject-1", tt.email, true), nil)
+			if err != nil {
+				t.Fatalf("second login: %v", err)
+			}
+			if again.User.Id != user.Id || len(identities.identities) != 1 {
+				t.Errorf("expected the existing link to be reused, got user %s and %d identities", again.User.Id, len(identities.identities))
+			}
+		})
+	}
+}
+
+func TestOIDCCompleteLoginRejects(t *testing.T) {
+	existing := &domain.User{Id: "42", Email: "jane@example.com", Handle: "jane", Role: domain.RoleUser}
+
+	tests := []struct {
+		name    string
+		claims  oidc.IDTokenClaims
+		tamper  func(*domain.OIDCState)
+		wantErr error
+	}{
+		{
+			name:    "unverified email is not linked",
+			claims:  idTokenClaims("subject-1", "jane@example.com", false),
+			wantErr: repository.ErrEmailNotVerified,
+		},
+		{
+			name:    "missing email",
+			claims:  idTokenClaims("subject-1", "", true),
+			wantErr: repository.ErrEmailNotVerified,
+		},
+		{
+			name:   "pkce verifier mismatch",
+			claims: idTokenClaims("subject-1", "jane@example.com", true),
+			tamper: func(state *domain.OIDCState) {
+				state.CodeVerifier += "x"
+			},
+		},
+		{
+			name:   "nonce mismatch",
+			claims: idTokenClaims("subject-1", "jane@example.com", true),
+			tamper: func(state *domain.OIDCState) {
+				state.Nonce = "another-nonce"
+			},
+			wantErr: utils.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, issuer := newTestOIDCService(t, existing)
+			users := service.userRepository.(*fakeUserRepository)
+			identities := service.userIdentityRepository.(*fakeUserIdentityRepository)
+
+			resp, err := oidcLogin(t, service, issuer, tt.claims, tt.tamper)
+			if err == nil {
+				t.Fatalf("expected error, got session for %+v", resp.User)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if len(identities.identities) != 0 {
+				t.Errorf("expected no identity to be linked, got %+v", identities.identities)
+			}
+			if len(users.users) != 1 {
+				t.Errorf("expected no user to be created, got %d users", len(users.users))
+			}
+		})
+	}
+}
+
+func TestOIDCCompleteLoginStateIsSingleUse(t *testing.T) {
+	service, issuer := newTestOIDCService(t)
+	ctx := context.Background()
+
+	begin, err := service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	code := issuer.Authorize(t, begin.AuthorizationURL, idTokenClaims("subject-1", "jane@example.com", true))
+
+	if _, err := service.CompleteLogin(ctx, &dto.OIDCCallbackReq{Code: code, State: begin.State}); err != nil {
+		t.Fatalf("complete login: %v", err)
+	}
+	if _, err := service.CompleteLogin(ctx, &dto.OIDCCallbackReq{Code: code, State: begin.State}); err == nil {
+		t.Fatal("expected replayed state to be rejected")
+	}
+}
+
+// A concurrent callback for the same subject can create the user and link
+// the identity after this one looked them up, and another signup can take the
+// handle after this one found it free.
+func TestOIDCCompleteLoginConcurrentCallback(t *testing.T) {
+	existing := &domain.User{Id: "42", Email: "jane@example.com", Handle: "jane", Role: domain.RoleUser}
+
+	tests := []struct {
+		name  string
+		users []*domain.User
+		race  func(users *fakeUserRepository, identities *fakeUserIdentityRepository)
+		// wantUsers counts every user afterwards, the one logged in included.
+		wantUsers  int
+		wantHandle string
+	}{
+		{
+			name:  "identity linked first",
+			users: []*domain.User{existing},
+			race: func(users *fakeUserRepository, identities *fakeUserIdentityRepository) {
+				identities.beforeCreate = func() {
+					identities.beforeCreate = nil
+					_ = identities.Create(context.Background(), &domain.UserIdentity{UserId: existing.Id, Provider: "test-idp", Subject: "subject-1"})
+				}
+			},
+			wantUsers:  1,
+			wantHandle: "jane",
+		},
+		{
+			name: "user created and linked first",
+			race: func(users *fakeUserRepository, identities *fakeUserIdentityRepository) {
+				users.beforeCreate = func() {
+					users.beforeCreate = nil
+					winner := &domain.User{Email: "jane@example.com", Handle: "jane", Role: domain.RoleUser}
+					_ = users.CreateUser(context.Background(), winner)
+					_ = identities.Create(context.Background(), &domain.UserIdentity{UserId: winner.Id, Provider: "test-idp", Subject: "subject-1"})
+				}
+			},
+			wantUsers:  1,
+			wantHandle: "jane",
+		},
+		{
+			name: "handle taken by another signup",
+			race: func(users *fakeUserRepository, identities *fakeUserIdentityRepository) {
+				users.beforeCreate = func() {
+					users.beforeCreate = nil
+					_ = users.CreateUser(context.Background(), &domain.User{Email: "jane@other.example.com", Handle: "Jane", Role: domain.RoleUser})
+				}
+			},
+			wantUsers:  2,
+			wantHandle: "jane_",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, issuer := newTestOIDCService(t, tt.users...)
+			users := service.userRepository.(*fakeUserRepository)
+			identities := service.userIdentityRepository.(*fakeUserIdentityRepository)
+			tt.race(users, identities)
+
+			resp, err := oidcLogin(t, service, issuer, idTokenClaims("subject-1", "jane@example.com", true), nil)
+			if err != nil {
+				t.Fatalf("complete login: %v", err)
+			}
+			if len(users.users) != tt.wantUsers || len(identities.identities) != 1 {
+				t.Fatalf("expected %d users and one link, got %d and %d", tt.wantUsers, len(users.users), len(identities.identities))
+			}
+
+			linked, err := users.GetUserById(context.Background(), identities.identities[0].UserId)
+			if err != nil {
+				t.Fatalf("get linked user: %v", err)
+			}
+			if resp.User.Id != linked.Id {
+				t.Errorf("expected login as the linked user %s, got %s", linked.Id, resp.User.Id)
+			}
+			if linked.Email != "jane@example.com" || !strings.HasPrefix(linked.Handle, tt.wantHandle) {
+				t.Errorf("expected jane@example.com with a handle starting %q, got %s with %q", tt.wantHandle, linked.Email, linked.Handle)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"time"
+)
+
+type PersonalAccessTokenService interface {
+	CreateToken(ctx context.Context, userId string, input *dto.CreatePersonalAccessTokenReq) (*dto.CreatedPersonalAccessTokenResp, error)
+	GetTokens(ctx context.Context, userId string) ([]*dto.PersonalAccessTokenResp, error)
+	RevokeToken(ctx context.Context, userId, id string) error
+	Authenticate(ctx context.Context, token string) (*domain.PersonalAccessToken, error)
+}
+
+type personalAccessTokenService struct {
+	personalAccessTokenRepository repository.PersonalAccessTokenRepository
+	auditLogService               AuditLogService
+}
+
+func (p *personalAccessTokenService) CreateToken(ctx context.Context, userId string, input *dto.CreatePersonalAccessTokenReq) (*dto.CreatedPersonalAccessTokenResp, error) {
+	plain, err := utils.GenerateOpaqueToken(utils.PersonalAccessTokenPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token := &domain.PersonalAccessToken{
+		UserId:    userId,
+		Name:      input.Name,
+		TokenHash: utils.HashToken(plain),
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := p.personalAccessTokenRepository.Create(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	return &dto.CreatedPersonalAccessTokenResp{
+		PersonalAccessTokenResp: *p.toPersonalAccessTokenResp(token),
+		Token:                   plain,
+	}, nil
+}
+
+func (p *personalAccessTokenService) GetTokens(ctx context.Context, userId string) ([]*dto.PersonalAccessTokenResp, error) {
+	tokens, err := p.personalAccessTokenRepository.GetByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]*dto.PersonalAccessTokenResp, len(tokens))
+	for i, token := range tokens {
+		resp[i] = p.toPersonalAccessTokenResp(token)
+	}
+
+	return resp, nil
+}
+
+func (p *personalAccessTokenService) RevokeToken(ctx context.Context, userId, id string) error {
+	if err := p.personalAccessTokenRepository.Delete(ctx, id, userId); err != nil {
+		return err
+	}
+
+	p.auditLogService.Record(ctx, &domain.AuditEvent{
+		UserId:  userId,
+		Event:   domain.AuditEventSessionRevocation,
+		Outcome: domain.AuditOutcomeSuccess,
+		Details: "personal access token " + id,
+	})
+
+	return nil
+}
+
+func (p *personalAccessTokenService) Authenticate(ctx context.Context, token string) (*domain.PersonalAccessToken, error) {
+	pat, err := p.personalAccessTokenRepository.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return nil, utils.ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if pat.IsExpired(now) {
+		return nil, utils.ErrInvalidToken
+	}
+
+	_ = p.personalAccessTokenRepository.TouchLastUsed(ctx, pat.Id, now)
+
+	return pat, nil
+}
+
+func (p *personalAccessTokenService) toPersonalAccessTokenResp(input *domain.PersonalAccessToken) *dto.PersonalAccessTokenResp {
+	return &dto.PersonalAccessTokenResp{
+		Id:         input.Id,
+		Name:       input.Name,
+		Scopes:     input.Scopes,
+		ExpiresAt:  input.ExpiresAt,
+		LastUsedAt: input.LastUsedAt,
+		CreatedAt:  input.CreatedAt,
+	}
+}
+
+func NewPersonalAccessTokenService(personalAccessTokenRepository repository.PersonalAccessTokenRepository, auditLogService AuditLogService) PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		auditLogService:               auditLogService,
+	}
+}
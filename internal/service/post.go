@@ -2,32 +2,49 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
 	"slices"
+	"strings"
 	"time"
 )
 
 type PostService interface {
 	CreatePost(ctx context.Context, creatorId string, input *dto.CreatePostReq) (*dto.PostResp, error)
-	GetPostById(ctx context.Context, id string) (*dto.PostResp, error)
-	GetPostsUsersBySearch(ctx context.Context, query string) (map[string]any, error)
-	GetAllPosts(ctx context.Context, userId string, page, limit int) ([]*dto.PostResp, int64, error)
-	GetPostsByCreator(ctx context.Context, creatorId string) ([]*dto.PostResp, error)
+	GetPostById(ctx context.Context, viewerId, id string) (*dto.PostResp, error)
+	GetPostsUsersBySearch(ctx context.Context, viewerId, query string) (map[string]any, error)
+	GetAllPosts(ctx context.Context, viewerId, userId string, page, limit int) ([]*dto.PostResp, int64, error)
+	GetPostsByCreator(ctx context.Context, viewerId, creatorId string) ([]*dto.PostResp, error)
+	GetTimeline(ctx context.Context, viewerId string, creatorIds []string, page, limit int) ([]*dto.PostResp, int64, error)
 	CommentPost(ctx context.Context, postId, userId string, input *dto.CommentReq) (*dto.PostResp, error)
+	GetThread(ctx context.Context, viewerId, postId, cursor string, limit int) ([]*dto.CommentResp, string, error)
+	GetComments(ctx context.Context, viewerId, postId string, query *dto.GetCommentsQuery) ([]*dto.CommentResp, string, error)
+	UpdateComment(ctx context.Context, postId, commentId, userId string, input *dto.UpdateCommentReq) (*dto.CommentResp, error)
+	LikeComment(ctx context.Context, postId, commentId, userId string) (*dto.CommentResp, error)
+	GetCommentHistory(ctx context.Context, viewerId, postId, commentId string) ([]dto.CommentRevisionResp, error)
 	LikePost(ctx context.Context, postId, userId string) (*dto.PostResp, error)
+	Repost(ctx context.Context, postId, userId string) (*dto.PostResp, error)
+	UndoRepost(ctx context.Context, postId, userId string) error
 	UpdatePost(ctx context.Context, id, userId string, input *dto.UpdatePostReq) (*dto.PostResp, error)
 	DeletePost(ctx context.Context, postId, userId string) error
 	DeleteComment(ctx context.Context, postId, commentId, userId string) error
 }
 
 type postService struct {
-	userRepository         repository.UserRepository
-	commentRepository      repository.CommentRepository
-	postRepository         repository.PostRepository
-	notificationRepository repository.NotificationRepository
+	config            *config.Config
+	userRepository    repository.UserRepository
+	followRepository  repository.FollowRepository
+	blockRepository   repository.BlockRepository
+	muteRepository    repository.MuteRepository
+	commentRepository repository.CommentRepository
+	postRepository    repository.PostRepository
+	mediaService      MediaService
+	notifier          *notifier
 }
 
 func (p *postService) CreatePost(ctx context.Context, creatorId string, input *dto.CreatePostReq) (*dto.PostResp, error) {
@@ -36,62 +53,126 @@ func (p *postService) CreatePost(ctx context.Context, creatorId string, input *d
 		return nil, fmt.Errorf("failed to get creator: %w", err)
 	}
 
+	mentions, err := p.resolveMentions(ctx, input.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := p.mediaService.Resolve(ctx, creatorId, "", input.MediaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	var quoted *domain.Post
+	if input.QuoteId != "" {
+		if quoted, err = p.shareablePost(ctx, creatorId, input.QuoteId); err != nil {
+			return nil, err
+		}
+	}
+
 	post := &domain.Post{
-		Creator:      creatorId,
-		Title:        input.Title,
-		Message:      input.Message,
-		FirstName:    user.FirstName,
-		LastName:     user.LastName,
-		SelectedFile: input.SelectedFile,
-		Likes:        make([]string, 0),
-		Comments:     make([]string, 0),
-		CreatedAt:    time.Now(),
+		Creator:   creatorId,
+		Kind:      domain.PostKindPost,
+		Title:     input.Title,
+		Message:   input.Message,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Media:     media,
+		Likes:     make([]string, 0),
+		Comments:  make([]string, 0),
+		Mentions:  mentions,
+		CreatedAt: time.Now(),
+	}
+
+	if quoted != nil {
+		post.Kind = domain.PostKindQuote
+		post.OriginalId = quoted.Id
 	}
 
 	if err := p.postRepository.CreatePost(ctx, post); err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
-	return p.toPostResp(post), nil
+	if err := p.mediaService.Attach(ctx, post.Id, input.MediaIds); err != nil {
+		return nil, fmt.Errorf("failed to attach media: %w", err)
+	}
+
+	if quoted != nil {
+		if err := p.postRepository.IncrementQuotes(ctx, quoted.Id, 1); err != nil {
+			return nil, fmt.Errorf("failed to count quote: %w", err)
+		}
+		p.notifyShare(ctx, user, quoted, "Quoted")
+	}
+
+	p.notifyMentions(ctx, user, post.Id, "post", mentions, nil)
+
+	return p.expandPost(ctx, creatorId, post)
 }
 
-func (p *postService) GetPostById(ctx context.Context, id string) (*dto.PostResp, error) {
+func (p *postService) GetPostById(ctx context.Context, viewerId, id string) (*dto.PostResp, error) {
 	post, err := p.postRepository.GetPostById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return p.toPostResp(post), nil
+	visible, err := p.visibleCreators(ctx, viewerId, []string{post.Creator}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !visible[post.Creator] {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	return p.expandPost(ctx, viewerId, post)
 }
 
-func (p *postService) GetPostsUsersBySearch(ctx context.Context, query string) (map[string]any, error) {
+func (p *postService) GetPostsUsersBySearch(ctx context.Context, viewerId, query string) (map[string]any, error) {
 	posts, err := p.postRepository.SearchPosts(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search posts: %w", err)
 	}
 
+	if posts, err = p.filterVisible(ctx, viewerId, posts); err != nil {
+		return nil, err
+	}
+
 	users, err := p.userRepository.GetUsersBySearch(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 
-	postResp := make([]*dto.PostResp, len(posts))
-	for i, post := range posts {
-		postResp[i] = p.toPostResp(post)
+	if viewerId != "" {
+		blockedIds, err := p.blockRepository.GetRelatedIds(ctx, viewerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blocks: %w", err)
+		}
+		users = slices.DeleteFunc(users, func(user *domain.User) bool {
+			return slices.Contains(blockedIds, user.Id)
+		})
+	}
+
+	postResp, err := p.toPostResps(ctx, viewerId, posts)
+	if err != nil {
+		return nil, err
 	}
 
 	userResp := make([]*dto.UserResp, len(users))
 
 	for i, u := range users {
 		userResp[i] = &dto.UserResp{
-			Id:        u.Id,
-			FirstName: u.FirstName,
-			LastName:  u.LastName,
-			Email:     u.Email,
-			ImageUrl:  u.ImageUrl,
-			Bio:       u.Bio,
-			Followers: u.Followers,
-			Following: u.Following,
+			Id:             u.Id,
+			FirstName:      u.FirstName,
+			LastName:       u.LastName,
+			Email:          u.Email,
+			Handle:         u.Handle,
+			ImageUrl:       u.ImageUrl,
+			BannerUrl:      u.BannerUrl,
+			Bio:            u.Bio,
+			Role:           u.Role,
+			IsPrivate:      u.IsPrivate,
+			FollowersCount: u.FollowersCount,
+			FollowingCount: u.FollowingCount,
 		}
 	}
 
@@ -101,61 +182,115 @@ func (p *postService) GetPostsUsersBySearch(ctx context.Context, query string) (
 	}, nil
 }
 
-func (p *postService) GetAllPosts(ctx context.Context, userId string, page, limit int) ([]*dto.PostResp, int64, error) {
-	mainUser, err := p.userRepository.GetUserById(ctx, userId)
+func (p *postService) GetAllPosts(ctx context.Context, viewerId, userId string, page, limit int) ([]*dto.PostResp, int64, error) {
+	followingIds, err := p.followRepository.GetFollowingIds(ctx, userId)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// Build feed: user + everyone they follow
-	feedIds := append([]string{userId}, mainUser.Following...)
+	return p.GetTimeline(ctx, viewerId, append([]string{userId}, followingIds...), page, limit)
+}
+
+// GetTimeline pages through the posts of the given creators, newest first,
+// leaving out accounts the viewer may not see or muted.
+func (p *postService) GetTimeline(ctx context.Context, viewerId string, creatorIds []string, page, limit int) ([]*dto.PostResp, int64, error) {
+	visible, err := p.visibleCreators(ctx, viewerId, creatorIds, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	feedIds := make([]string, 0, len(visible))
+	for id := range visible {
+		feedIds = append(feedIds, id)
+	}
 
 	posts, total, err := p.postRepository.GetFeedPosts(ctx, feedIds, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	resp := make([]*dto.PostResp, len(posts))
-	for i, post := range posts {
-		resp[i] = p.toPostResp(post)
+	resp, err := p.toPostResps(ctx, viewerId, posts)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return resp, total, nil
 }
 
-func (p *postService) GetPostsByCreator(ctx context.Context, creatorId string) ([]*dto.PostResp, error) {
-	posts, err := p.postRepository.GetPostsByCreator(ctx, creatorId)
+func (p *postService) GetPostsByCreator(ctx context.Context, viewerId, creatorId string) ([]*dto.PostResp, error) {
+	visible, err := p.visibleCreators(ctx, viewerId, []string{creatorId}, false)
 	if err != nil {
 		return nil, err
 	}
 
-	resp := make([]*dto.PostResp, len(posts))
-	for i, post := range posts {
-		resp[i] = p.toPostResp(post)
+	if !visible[creatorId] {
+		return []*dto.PostResp{}, nil
 	}
 
-	return resp, nil
+	posts, err := p.postRepository.GetPostsByCreator(ctx, creatorId)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.toPostResps(ctx, viewerId, posts)
 }
 
 func (p *postService) CommentPost(ctx context.Context, postId, userId string, input *dto.CommentReq) (*dto.PostResp, error) {
+	post, err := p.visiblePost(ctx, userId, postId)
+	if err != nil {
+		return nil, err
+	}
+	postId = post.Id
+
+	mentions, err := p.resolveMentions(ctx, input.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := &domain.Comment{
 		PostId:    postId,
 		UserId:    userId,
 		Value:     input.Value,
+		Mentions:  mentions,
 		CreatedAt: time.Now(),
 	}
 
+	var parent *domain.Comment
+	if input.ParentId != "" {
+		if parent, err = p.replyParent(ctx, postId, input.ParentId); err != nil {
+			return nil, err
+		}
+
+		if err := p.checkNotBlocked(ctx, userId, parent.UserId); err != nil {
+			return nil, err
+		}
+
+		comment.ParentId = parent.Id
+		comment.RootId = parent.RootId
+		if comment.RootId == "" {
+			comment.RootId = parent.Id
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
 	if err := p.commentRepository.CreateComment(ctx, comment); err != nil {
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
 
+	if parent != nil {
+		if _, err := p.commentRepository.IncrementReplies(ctx, parent.Id, 1); err != nil {
+			return nil, fmt.Errorf("failed to count reply: %w", err)
+		}
+	}
+
 	if err := p.postRepository.AddComment(ctx, postId, comment.Id); err != nil {
 		return nil, fmt.Errorf("failed to add comment: %w", err)
 	}
 
-	post, err := p.postRepository.GetPostById(ctx, postId)
-	if err == nil && post.Creator != userId {
-		actor, _ := p.userRepository.GetUserById(ctx, userId)
+	actor, _ := p.userRepository.GetUserById(ctx, userId)
+
+	if post.Creator != userId {
 		notif := &domain.Notification{
 			SenderId:   userId,
 			ReceiverId: post.Creator,
@@ -168,18 +303,302 @@ func (p *postService) CommentPost(ctx context.Context, postId, userId string, in
 				Avatar: actor.ImageUrl,
 			},
 		}
-		_ = p.notificationRepository.Create(ctx, notif)
+		p.notifier.notify(ctx, notif)
 	}
 
+	// The post creator already hears about the comment itself.
+	skip := []domain.Mention{{UserId: post.Creator}}
+
+	if parent != nil && !parent.Deleted && parent.UserId != userId && parent.UserId != post.Creator {
+		notif := &domain.Notification{
+			SenderId:   userId,
+			ReceiverId: parent.UserId,
+			TargetId:   postId,
+			Details:    actor.FirstName + " " + actor.LastName + " Replied to your Comment",
+			IsRead:     false,
+			CreatedAt:  time.Now(),
+			NotificationUser: domain.NotificationUser{
+				Name:   actor.FirstName + " " + actor.LastName,
+				Avatar: actor.ImageUrl,
+			},
+		}
+		p.notifier.notify(ctx, notif)
+		skip = append(skip, domain.Mention{UserId: parent.UserId})
+	}
+
+	p.notifyMentions(ctx, actor, postId, "comment", mentions, skip)
+
 	post, _ = p.postRepository.GetPostById(ctx, postId)
-	return p.toPostResp(post), nil
+	return p.expandPost(ctx, userId, post)
+}
+
+// GetThread returns a page of a post's top-level comments, oldest first, each
+// with every reply below it nested by parent.
+func (p *postService) GetThread(ctx context.Context, viewerId, postId, cursor string, limit int) ([]*dto.CommentResp, string, error) {
+	if _, err := p.GetPostById(ctx, viewerId, postId); err != nil {
+		return nil, "", err
+	}
+
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One extra comment tells whether another page exists.
+	roots, err := p.commentRepository.GetRoots(ctx, postId, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(roots) > limit {
+		roots = roots[:limit]
+		last := roots[len(roots)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.Id)
+	}
+
+	rootIds := make([]string, len(roots))
+	for i, root := range roots {
+		rootIds[i] = root.Id
+	}
+
+	replies, err := p.commentRepository.GetByRootIds(ctx, rootIds)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authors, err := p.commentAuthors(ctx, viewerId, slices.Concat(roots, replies))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Comments by authors the viewer can't see are left out along with
+	// everything below them.
+	thread := make([]*dto.CommentResp, 0, len(roots))
+	byId := make(map[string]*dto.CommentResp, len(roots)+len(replies))
+	for _, root := range roots {
+		author, ok := authors[root.UserId]
+		if !ok && !root.Deleted {
+			continue
+		}
+		resp := toCommentResp(root, author, viewerId)
+		thread = append(thread, resp)
+		byId[root.Id] = resp
+	}
+
+	// Replies come oldest first, so a parent is always placed before its replies.
+	for _, reply := range replies {
+		parent, ok := byId[reply.ParentId]
+		if !ok {
+			continue
+		}
+		author, ok := authors[reply.UserId]
+		if !ok && !reply.Deleted {
+			continue
+		}
+		resp := toCommentResp(reply, author, viewerId)
+		parent.Replies = append(parent.Replies, resp)
+		byId[reply.Id] = resp
+	}
+
+	return thread, nextCursor, nil
+}
+
+// GetComments returns a page of the comments directly below query.ParentId,
+// or of the post's top-level comments, each with its author expanded.
+func (p *postService) GetComments(ctx context.Context, viewerId, postId string, query *dto.GetCommentsQuery) ([]*dto.CommentResp, string, error) {
+	if _, err := p.GetPostById(ctx, viewerId, postId); err != nil {
+		return nil, "", err
+	}
+
+	var after *domain.PageCursor
+	if query.Sort == domain.CommentSortTop && query.Cursor != "" {
+		score, createdAt, id, err := utils.DecodeRankedCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &domain.PageCursor{Score: score, CreatedAt: createdAt, Id: id}
+	} else {
+		var err error
+		if after, err = decodePageCursor(query.Cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// One extra comment tells whether another page exists.
+	comments, err := p.commentRepository.GetByParent(ctx, postId, query.ParentId, query.Sort, after, query.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(comments) > query.Limit {
+		comments = comments[:query.Limit]
+		last := comments[len(comments)-1]
+		if query.Sort == domain.CommentSortTop {
+			nextCursor = utils.EncodeRankedCursor(int64(len(last.Likes)), last.CreatedAt, last.Id)
+		} else {
+			nextCursor = utils.EncodeCursor(last.CreatedAt, last.Id)
+		}
+	}
+
+	authors, err := p.commentAuthors(ctx, viewerId, comments)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp := make([]*dto.CommentResp, 0, len(comments))
+	for _, comment := range comments {
+		author, ok := authors[comment.UserId]
+		if !ok && !comment.Deleted {
+			continue
+		}
+		resp = append(resp, toCommentResp(comment, author, viewerId))
+	}
+
+	return resp, nextCursor, nil
+}
+
+// UpdateComment changes the text of the user's own comment, as long as the
+// edit window since it was posted hasn't closed. The previous text is kept.
+func (p *postService) UpdateComment(ctx context.Context, postId, commentId, userId string, input *dto.UpdateCommentReq) (*dto.CommentResp, error) {
+	if _, err := p.visiblePost(ctx, userId, postId); err != nil {
+		return nil, err
+	}
+
+	comment, err := p.getComment(ctx, postId, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.UserId != userId {
+		return nil, repository.ErrForbidden
+	}
+
+	now := time.Now()
+	if now.Sub(comment.CreatedAt) > p.config.Comment.EditWindow {
+		return nil, repository.ErrEditWindowClosed
+	}
+
+	if comment.Value == input.Value {
+		return p.expandComment(ctx, userId, comment)
+	}
+
+	mentions, err := p.resolveMentions(ctx, input.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	// The replaced version was written when the comment was posted or last edited.
+	previous := domain.CommentRevision{Value: comment.Value, CreatedAt: comment.CreatedAt}
+	if comment.EditedAt != nil {
+		previous.CreatedAt = *comment.EditedAt
+	}
+
+	previousMentions := comment.Mentions
+	comment.Value = input.Value
+	comment.Mentions = mentions
+	comment.EditedAt = &now
+
+	if err := p.commentRepository.UpdateComment(ctx, comment, previous); err != nil {
+		return nil, err
+	}
+
+	if actor, err := p.userRepository.GetUserById(ctx, userId); err == nil {
+		p.notifyMentions(ctx, actor, postId, "comment", mentions, previousMentions)
+	}
+
+	return p.expandComment(ctx, userId, comment)
+}
+
+// LikeComment toggles the user's like on a comment, notifying its author of new likes.
+func (p *postService) LikeComment(ctx context.Context, postId, commentId, userId string) (*dto.CommentResp, error) {
+	if _, err := p.visiblePost(ctx, userId, postId); err != nil {
+		return nil, err
+	}
+
+	comment, err := p.getComment(ctx, postId, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	isLiking := !slices.Contains(comment.Likes, userId)
+
+	if isLiking {
+		if err := p.checkNotBlocked(ctx, userId, comment.UserId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.commentRepository.SetLike(ctx, commentId, userId, isLiking); err != nil {
+		return nil, fmt.Errorf("failed to toggle like: %w", err)
+	}
+
+	if isLiking {
+		comment.Likes = append(comment.Likes, userId)
+
+		if comment.UserId != userId {
+			if actor, err := p.userRepository.GetUserById(ctx, userId); err == nil {
+				p.notifier.notify(ctx, &domain.Notification{
+					SenderId:   userId,
+					ReceiverId: comment.UserId,
+					TargetId:   postId,
+					Details:    actor.FirstName + " " + actor.LastName + " Liked your Comment",
+					IsRead:     false,
+					CreatedAt:  time.Now(),
+					NotificationUser: domain.NotificationUser{
+						Name:   actor.FirstName + " " + actor.LastName,
+						Avatar: actor.ImageUrl,
+					},
+				})
+			}
+		}
+	} else {
+		comment.Likes = slices.DeleteFunc(comment.Likes, func(id string) bool {
+			return id == userId
+		})
+	}
+
+	return p.expandComment(ctx, userId, comment)
+}
+
+// GetCommentHistory returns the earlier versions of a comment, oldest first.
+func (p *postService) GetCommentHistory(ctx context.Context, viewerId, postId, commentId string) ([]dto.CommentRevisionResp, error) {
+	if _, err := p.GetPostById(ctx, viewerId, postId); err != nil {
+		return nil, err
+	}
+
+	comment, err := p.getComment(ctx, postId, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := p.commentAuthors(ctx, viewerId, []*domain.Comment{comment})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := authors[comment.UserId]; !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	history := make([]dto.CommentRevisionResp, len(comment.History))
+	for i, revision := range comment.History {
+		history[i] = dto.CommentRevisionResp{
+			Value:     revision.Value,
+			CreatedAt: revision.CreatedAt,
+		}
+	}
+
+	return history, nil
 }
 
 func (p *postService) LikePost(ctx context.Context, postId, userId string) (*dto.PostResp, error) {
-	post, err := p.postRepository.GetPostById(ctx, postId)
+	post, err := p.visiblePost(ctx, userId, postId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
+	postId = post.Id
 
 	isLiking := !slices.Contains(post.Likes, userId)
 
@@ -197,7 +616,7 @@ func (p *postService) LikePost(ctx context.Context, postId, userId string) (*dto
 				Avatar: actor.ImageUrl,
 			},
 		}
-		_ = p.notificationRepository.Create(ctx, notif)
+		p.notifier.notify(ctx, notif)
 	}
 
 	if err := p.postRepository.ToggleLike(ctx, postId, userId); err != nil {
@@ -209,7 +628,63 @@ func (p *postService) LikePost(ctx context.Context, postId, userId string) (*dto
 		return nil, err
 	}
 
-	return p.toPostResp(post), nil
+	return p.expandPost(ctx, userId, post)
+}
+
+// Repost shares a post into the user's followers' feeds. Reposting a repost
+// shares the post it shares.
+func (p *postService) Repost(ctx context.Context, postId, userId string) (*dto.PostResp, error) {
+	original, err := p.shareablePost(ctx, userId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := p.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	repost := &domain.Post{
+		Creator:    userId,
+		Kind:       domain.PostKindRepost,
+		OriginalId: original.Id,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Likes:      make([]string, 0),
+		Comments:   make([]string, 0),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := p.postRepository.CreatePost(ctx, repost); err != nil {
+		return nil, err
+	}
+
+	if err := p.postRepository.IncrementReposts(ctx, original.Id, 1); err != nil {
+		return nil, fmt.Errorf("failed to count repost: %w", err)
+	}
+
+	p.notifyShare(ctx, user, original, "Reposted")
+
+	return p.expandPost(ctx, userId, repost)
+}
+
+// UndoRepost removes the user's repost of a post, given the post or any repost of it.
+func (p *postService) UndoRepost(ctx context.Context, postId, userId string) error {
+	original, err := p.getPost(ctx, postId)
+	if err != nil {
+		return err
+	}
+
+	repost, err := p.postRepository.GetRepost(ctx, userId, original.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := p.postRepository.DeletePost(ctx, repost.Id); err != nil {
+		return err
+	}
+
+	return p.postRepository.IncrementReposts(ctx, original.Id, -1)
 }
 
 func (p *postService) UpdatePost(ctx context.Context, id, userId string, input *dto.UpdatePostReq) (*dto.PostResp, error) {
@@ -218,27 +693,50 @@ func (p *postService) UpdatePost(ctx context.Context, id, userId string, input *
 		return nil, err
 	}
 
-	if post.Creator != userId {
-		return nil, fmt.Errorf("post creator does not match")
+	// A repost has no content of its own to edit.
+	if post.Creator != userId || post.IsRepost() {
+		return nil, repository.ErrForbidden
 	}
 
 	if input.Title != nil {
 		post.Title = *input.Title
 	}
 
+	previousMentions := post.Mentions
 	if input.Message != nil {
 		post.Message = *input.Message
+		if post.Mentions, err = p.resolveMentions(ctx, post.Message); err != nil {
+			return nil, err
+		}
 	}
 
-	if input.SelectedFile != nil {
-		post.SelectedFile = *input.SelectedFile
+	if input.MediaIds != nil {
+		if post.Media, err = p.mediaService.Resolve(ctx, userId, post.Id, *input.MediaIds); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := p.postRepository.UpdatePost(ctx, post); err != nil {
 		return nil, err
 	}
 
-	return p.toPostResp(post), nil
+	// Media dropped from the post is deleted, new media gets attached.
+	if input.MediaIds != nil {
+		if err := p.mediaService.DeleteByPostId(ctx, post.Id, *input.MediaIds); err != nil {
+			return nil, fmt.Errorf("failed to delete media: %w", err)
+		}
+		if err := p.mediaService.Attach(ctx, post.Id, *input.MediaIds); err != nil {
+			return nil, fmt.Errorf("failed to attach media: %w", err)
+		}
+	}
+
+	if input.Message != nil {
+		if actor, err := p.userRepository.GetUserById(ctx, userId); err == nil {
+			p.notifyMentions(ctx, actor, post.Id, "post", post.Mentions, previousMentions)
+		}
+	}
+
+	return p.expandPost(ctx, userId, post)
 }
 
 func (p *postService) DeletePost(ctx context.Context, postId, userId string) error {
@@ -248,14 +746,22 @@ func (p *postService) DeletePost(ctx context.Context, postId, userId string) err
 	}
 
 	if post.Creator != userId {
-		return fmt.Errorf("post creator does not match")
+		return repository.ErrForbidden
+	}
+
+	if err := p.postRepository.DeletePost(ctx, postId); err != nil {
+		return err
+	}
+
+	if err := deleteShares(ctx, p.postRepository, post); err != nil {
+		return fmt.Errorf("failed to delete reposts: %w", err)
 	}
 
-	return p.postRepository.DeletePost(ctx, postId)
+	return p.mediaService.DeleteByPostId(ctx, postId, nil)
 }
 
 func (p *postService) DeleteComment(ctx context.Context, postId, commentId, userId string) error {
-	comment, err := p.commentRepository.GetCommentById(ctx, commentId)
+	comment, err := p.getComment(ctx, postId, commentId)
 	if err != nil {
 		return err
 	}
@@ -266,10 +772,10 @@ func (p *postService) DeleteComment(ctx context.Context, postId, commentId, user
 	}
 
 	if comment.UserId != userId && post.Creator != userId {
-		return repository.ErrUnauthorized
+		return repository.ErrForbidden
 	}
 
-	if err := p.commentRepository.DeleteComment(ctx, commentId); err != nil {
+	if err := deleteComment(ctx, p.commentRepository, comment); err != nil {
 		return err
 	}
 
@@ -278,26 +784,545 @@ func (p *postService) DeleteComment(ctx context.Context, postId, commentId, user
 	return nil
 }
 
+// getPost returns the post that interactions with id apply to: the post
+// itself or, for a repost, the post it shares.
+func (p *postService) getPost(ctx context.Context, id string) (*domain.Post, error) {
+	post, err := p.postRepository.GetPostById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !post.IsRepost() {
+		return post, nil
+	}
+
+	return p.postRepository.GetPostById(ctx, post.OriginalId)
+}
+
+// visiblePost returns the post as getPost does, or ErrRecordNotFound when
+// the viewer may not see its creator, the same as GetPostById.
+func (p *postService) visiblePost(ctx context.Context, viewerId, id string) (*domain.Post, error) {
+	post, err := p.getPost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := p.visibleCreators(ctx, viewerId, []string{post.Creator}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !visible[post.Creator] {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	return post, nil
+}
+
+// shareablePost returns the post a repost or quote of id shares, as getPost
+// does. Posts the user may not see can't be shared, and neither can posts of
+// private accounts, which are only meant for their followers.
+func (p *postService) shareablePost(ctx context.Context, userId, id string) (*domain.Post, error) {
+	post, err := p.getPost(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	creator, err := p.userRepository.GetUserById(ctx, post.Creator)
+	if err != nil {
+		return nil, err
+	}
+
+	if creator.IsDeactivated() {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	if err := p.checkNotBlocked(ctx, userId, creator.Id); err != nil {
+		return nil, err
+	}
+
+	if creator.IsPrivate && creator.Id != userId {
+		return nil, repository.ErrForbidden
+	}
+
+	return post, nil
+}
+
+// notifyShare tells the creator of a post that the actor reposted or quoted it.
+func (p *postService) notifyShare(ctx context.Context, actor *domain.User, post *domain.Post, verb string) {
+	if actor.Id == post.Creator {
+		return
+	}
+
+	notif := &domain.Notification{
+		SenderId:   actor.Id,
+		ReceiverId: post.Creator,
+		TargetId:   post.Id,
+		Details:    actor.FirstName + " " + actor.LastName + " " + verb + " your Post",
+		IsRead:     false,
+		CreatedAt:  time.Now(),
+		NotificationUser: domain.NotificationUser{
+			Name:   actor.FirstName + " " + actor.LastName,
+			Avatar: actor.ImageUrl,
+		},
+	}
+	p.notifier.notify(ctx, notif)
+}
+
+// deleteShares cleans up after deleted posts: the counts on the posts they
+// reposted or quoted go down and their own reposts go away.
+func deleteShares(ctx context.Context, postRepository repository.PostRepository, posts ...*domain.Post) error {
+	ids := make([]string, 0, len(posts))
+	for _, post := range posts {
+		switch post.Kind {
+		case domain.PostKindRepost:
+			if err := postRepository.IncrementReposts(ctx, post.OriginalId, -1); err != nil {
+				return err
+			}
+		case domain.PostKindQuote:
+			if err := postRepository.IncrementQuotes(ctx, post.OriginalId, -1); err != nil {
+				return err
+			}
+		}
+
+		if !post.IsRepost() {
+			ids = append(ids, post.Id)
+		}
+	}
+
+	return postRepository.DeleteReposts(ctx, ids)
+}
+
+// getComment returns a comment of the post, treating tombstones as gone.
+func (p *postService) getComment(ctx context.Context, postId, commentId string) (*domain.Comment, error) {
+	comment, err := p.commentRepository.GetCommentById(ctx, commentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.PostId != postId || comment.Deleted {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	return comment, nil
+}
+
+// expandComment builds the response for a single comment, with its author, for the viewer.
+func (p *postService) expandComment(ctx context.Context, viewerId string, comment *domain.Comment) (*dto.CommentResp, error) {
+	author, err := p.userRepository.GetUserById(ctx, comment.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment author: %w", err)
+	}
+
+	return toCommentResp(comment, author, viewerId), nil
+}
+
+// replyParent returns the comment a reply to parentId attaches to. Replies to
+// a comment at CommentMaxDepth attach to its parent instead, next to it.
+func (p *postService) replyParent(ctx context.Context, postId, parentId string) (*domain.Comment, error) {
+	parent, err := p.commentRepository.GetCommentById(ctx, parentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) || errors.Is(err, repository.ErrInvalidId) {
+			return nil, repository.ErrInvalidParent
+		}
+		return nil, err
+	}
+
+	if parent.PostId != postId || parent.Deleted {
+		return nil, repository.ErrInvalidParent
+	}
+
+	if parent.Depth < domain.CommentMaxDepth {
+		return parent, nil
+	}
+
+	return p.commentRepository.GetCommentById(ctx, parent.ParentId)
+}
+
+// deleteComment takes a comment out of its thread. A comment with replies
+// becomes a tombstone; any other is deleted, along with tombstoned ancestors
+// it was the last remaining reply of.
+func deleteComment(ctx context.Context, commentRepository repository.CommentRepository, comment *domain.Comment) error {
+	if comment.ReplyCount > 0 {
+		return commentRepository.Tombstone(ctx, comment.Id)
+	}
+
+	for {
+		if err := commentRepository.DeleteComment(ctx, comment.Id); err != nil {
+			return err
+		}
+
+		if comment.ParentId == "" {
+			return nil
+		}
+
+		parent, err := commentRepository.IncrementReplies(ctx, comment.ParentId, -1)
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if !parent.Deleted || parent.ReplyCount > 0 {
+			return nil
+		}
+		comment = parent
+	}
+}
+
+// commentAuthors returns the authors of the comments the viewer may see, by
+// id. Deactivated accounts, users blocked either way and users the viewer
+// muted are left out.
+func (p *postService) commentAuthors(ctx context.Context, viewerId string, comments []*domain.Comment) (map[string]*domain.User, error) {
+	ids := make([]string, 0, len(comments))
+	for _, comment := range comments {
+		if !comment.Deleted && !slices.Contains(ids, comment.UserId) {
+			ids = append(ids, comment.UserId)
+		}
+	}
+
+	users, err := p.userRepository.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment authors: %w", err)
+	}
+
+	var hiddenIds []string
+	if viewerId != "" {
+		blockedIds, err := p.blockRepository.GetRelatedIds(ctx, viewerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blocks: %w", err)
+		}
+
+		mutedIds, err := p.muteRepository.GetMutedIds(ctx, viewerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mutes: %w", err)
+		}
+
+		hiddenIds = append(blockedIds, mutedIds...)
+	}
+
+	authors := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		if !slices.Contains(hiddenIds, user.Id) {
+			authors[user.Id] = user
+		}
+	}
+
+	return authors, nil
+}
+
+// checkNotBlocked returns ErrBlocked when either user blocked the other.
+func (p *postService) checkNotBlocked(ctx context.Context, userId, otherId string) error {
+	if userId == otherId {
+		return nil
+	}
+
+	blocked, err := p.blockRepository.IsBlocked(ctx, userId, otherId)
+	if err != nil {
+		return fmt.Errorf("failed to get block status: %w", err)
+	}
+	if blocked {
+		return repository.ErrBlocked
+	}
+
+	return nil
+}
+
+// visibleCreators returns which of the creators the viewer may see posts from:
+// active public accounts, the viewer themselves and private accounts the viewer follows,
+// never counting users blocked either way and, when hideMuted is set, users the viewer muted.
+// An empty viewerId stands for an anonymous caller.
+func (p *postService) visibleCreators(ctx context.Context, viewerId string, creatorIds []string, hideMuted bool) (map[string]bool, error) {
+	users, err := p.userRepository.GetUsersByIds(ctx, creatorIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get creators: %w", err)
+	}
+
+	hidden := make(map[string]bool)
+	if viewerId != "" {
+		blockedIds, err := p.blockRepository.GetRelatedIds(ctx, viewerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blocks: %w", err)
+		}
+		for _, id := range blockedIds {
+			hidden[id] = true
+		}
+
+		if hideMuted {
+			mutedIds, err := p.muteRepository.GetMutedIds(ctx, viewerId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get mutes: %w", err)
+			}
+			for _, id := range mutedIds {
+				hidden[id] = true
+			}
+		}
+	}
+
+	visible := make(map[string]bool, len(users))
+	var private []string
+	for _, user := range users {
+		if hidden[user.Id] {
+			continue
+		}
+		if !user.IsPrivate || user.Id == viewerId {
+			visible[user.Id] = true
+			continue
+		}
+		private = append(private, user.Id)
+	}
+
+	if viewerId == "" || len(private) == 0 {
+		return visible, nil
+	}
+
+	followed, err := p.followRepository.GetFollowedAmong(ctx, viewerId, private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow status: %w", err)
+	}
+
+	for _, id := range followed {
+		visible[id] = true
+	}
+
+	return visible, nil
+}
+
+func (p *postService) filterVisible(ctx context.Context, viewerId string, posts []*domain.Post) ([]*domain.Post, error) {
+	creatorIds := make([]string, len(posts))
+	for i, post := range posts {
+		creatorIds[i] = post.Creator
+	}
+
+	visible, err := p.visibleCreators(ctx, viewerId, creatorIds, true)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*domain.Post, 0, len(posts))
+	for _, post := range posts {
+		if visible[post.Creator] {
+			filtered = append(filtered, post)
+		}
+	}
+
+	return filtered, nil
+}
+
+// resolveMentions finds the @handles in text and keeps the ones that belong to
+// an active user. Unknown handles are left as plain text.
+func (p *postService) resolveMentions(ctx context.Context, text string) ([]domain.Mention, error) {
+	matches := utils.FindMentions(text)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	handles := make([]string, len(matches))
+	for i, match := range matches {
+		handles[i] = match.Handle
+	}
+
+	users, err := p.userRepository.GetUsersByHandles(ctx, handles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+	}
+
+	byHandle := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		byHandle[strings.ToLower(user.Handle)] = user
+	}
+
+	mentions := make([]domain.Mention, 0, len(matches))
+	for _, match := range matches {
+		user, ok := byHandle[strings.ToLower(match.Handle)]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, domain.Mention{
+			UserId: user.Id,
+			Handle: user.Handle,
+			Start:  match.Start,
+			End:    match.End,
+		})
+	}
+
+	return mentions, nil
+}
+
+// notifyMentions notifies each mentioned user once, except the actor and
+// anyone already in skip, e.g. users mentioned before an edit.
+func (p *postService) notifyMentions(ctx context.Context, actor *domain.User, postId, kind string, mentions, skip []domain.Mention) {
+	if actor == nil {
+		return
+	}
+
+	notified := map[string]bool{actor.Id: true}
+	for _, mention := range skip {
+		notified[mention.UserId] = true
+	}
+
+	for _, mention := range mentions {
+		if notified[mention.UserId] {
+			continue
+		}
+		notified[mention.UserId] = true
+
+		notif := &domain.Notification{
+			SenderId:   actor.Id,
+			ReceiverId: mention.UserId,
+			TargetId:   postId,
+			Details:    actor.FirstName + " " + actor.LastName + " mentioned you in a " + kind,
+			IsRead:     false,
+			CreatedAt:  time.Now(),
+			NotificationUser: domain.NotificationUser{
+				Name:   actor.FirstName + " " + actor.LastName,
+				Avatar: actor.ImageUrl,
+			},
+		}
+		p.notifier.notify(ctx, notif)
+	}
+}
+
+// toPostResps builds the responses for posts as the viewer sees them, with
+// the post each repost or quote shares embedded. Reposts of posts that are
+// gone or hidden from the viewer are left out.
+func (p *postService) toPostResps(ctx context.Context, viewerId string, posts []*domain.Post) ([]*dto.PostResp, error) {
+	var originalIds []string
+	for _, post := range posts {
+		if post.OriginalId != "" && !slices.Contains(originalIds, post.OriginalId) {
+			originalIds = append(originalIds, post.OriginalId)
+		}
+	}
+
+	originals, err := p.postRepository.GetPostsByIds(ctx, originalIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared posts: %w", err)
+	}
+
+	if originals, err = p.filterVisible(ctx, viewerId, originals); err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*domain.Post, len(originals))
+	for _, original := range originals {
+		byId[original.Id] = original
+	}
+
+	resp := make([]*dto.PostResp, 0, len(posts))
+	for _, post := range posts {
+		postResp := p.toPostResp(post)
+		if post.OriginalId != "" {
+			original, ok := byId[post.OriginalId]
+			if !ok && post.IsRepost() {
+				continue
+			}
+			if ok {
+				postResp.Original = p.toPostResp(original)
+			}
+		}
+		resp = append(resp, postResp)
+	}
+
+	return resp, nil
+}
+
+// expandPost builds the response for a single post like toPostResps does.
+func (p *postService) expandPost(ctx context.Context, viewerId string, post *domain.Post) (*dto.PostResp, error) {
+	resp, err := p.toPostResps(ctx, viewerId, []*domain.Post{post})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp) == 0 {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	return resp[0], nil
+}
+
 func (p *postService) toPostResp(input *domain.Post) *dto.PostResp {
 	return &dto.PostResp{
-		Id:           input.Id,
-		Creator:      input.Creator,
-		Title:        input.Title,
-		Message:      input.Message,
-		FirstName:    input.FirstName,
-		LastName:     input.LastName,
-		SelectedFile: input.SelectedFile,
-		Likes:        input.Likes,
-		Comments:     input.Comments,
-		CreatedAt:    input.CreatedAt,
+		Id:          input.Id,
+		Creator:     input.Creator,
+		Kind:        input.Kind,
+		OriginalId:  input.OriginalId,
+		Title:       input.Title,
+		Message:     input.Message,
+		FirstName:   input.FirstName,
+		LastName:    input.LastName,
+		Media:       toAttachmentResps(input.Media),
+		Likes:       input.Likes,
+		Comments:    input.Comments,
+		Mentions:    toMentionResps(input.Mentions),
+		RepostCount: input.RepostCount,
+		QuoteCount:  input.QuoteCount,
+		CreatedAt:   input.CreatedAt,
+	}
+}
+
+// toCommentResp builds the response for a comment written by author, as seen
+// by the viewer. Tombstones carry neither content nor author.
+func toCommentResp(input *domain.Comment, author *domain.User, viewerId string) *dto.CommentResp {
+	resp := &dto.CommentResp{
+		Id:         input.Id,
+		PostId:     input.PostId,
+		ParentId:   input.ParentId,
+		Mentions:   []dto.MentionResp{},
+		Depth:      input.Depth,
+		ReplyCount: input.ReplyCount,
+		Deleted:    input.Deleted,
+		Replies:    []*dto.CommentResp{},
+		CreatedAt:  input.CreatedAt,
+	}
+
+	if input.Deleted {
+		return resp
+	}
+
+	resp.UserId = input.UserId
+	resp.Value = input.Value
+	resp.EditedAt = input.EditedAt
+	resp.Mentions = toMentionResps(input.Mentions)
+	resp.LikeCount = int64(len(input.Likes))
+	resp.LikedByMe = viewerId != "" && slices.Contains(input.Likes, viewerId)
+
+	if author != nil {
+		resp.Author = &dto.CommentAuthorResp{
+			Id:        author.Id,
+			FirstName: author.FirstName,
+			LastName:  author.LastName,
+			Handle:    author.Handle,
+			ImageUrl:  author.ImageUrl,
+		}
+	}
+
+	return resp
+}
+
+func toMentionResps(input []domain.Mention) []dto.MentionResp {
+	mentions := make([]dto.MentionResp, len(input))
+	for i, mention := range input {
+		mentions[i] = dto.MentionResp{
+			UserId: mention.UserId,
+			Handle: mention.Handle,
+			Start:  mention.Start,
+			End:    mention.End,
+		}
 	}
+	return mentions
 }
 
-func NewPostService(userRepository repository.UserRepository, commentRepository repository.CommentRepository, postRepository repository.PostRepository, notificationRepository repository.NotificationRepository) PostService {
+func NewPostService(config *config.Config, userRepository repository.UserRepository, followRepository repository.FollowRepository, blockRepository repository.BlockRepository, muteRepository repository.MuteRepository, commentRepository repository.CommentRepository, postRepository repository.PostRepository, notificationRepository repository.NotificationRepository, mediaService MediaService) PostService {
 	return &postService{
-		userRepository:         userRepository,
-		commentRepository:      commentRepository,
-		postRepository:         postRepository,
-		notificationRepository: notificationRepository,
+		config:            config,
+		userRepository:    userRepository,
+		followRepository:  followRepository,
+		blockRepository:   blockRepository,
+		muteRepository:    muteRepository,
+		commentRepository: commentRepository,
+		postRepository:    postRepository,
+		mediaService:      mediaService,
+		notifier:          newNotifier(notificationRepository, blockRepository, muteRepository),
 	}
 }
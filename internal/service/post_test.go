@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"testing"
+	"time"
+)
+
+// newPrivatePostService has a private account "owner" with a post and a
+// comment on it, followed by "follower". "stranger" wrote a comment while they
+// still followed the owner, and "blocked" was blocked by the owner.
+func newPrivatePostService() *postService {
+	return &postService{
+		config: &config.Config{Comment: config.Comment{EditWindow: time.Hour}},
+		userRepository: newFakeUserRepository(
+			&domain.User{Id: "owner", IsPrivate: true},
+			&domain.User{Id: "follower"},
+			&domain.User{Id: "stranger"},
+			&domain.User{Id: "blocked"},
+		),
+		followRepository: &fakeFollowRepository{follows: map[string][]string{"follower": {"owner"}}},
+		blockRepository:  &fakeBlockRepository{blocks: map[string][]string{"owner": {"blocked"}}},
+		postRepository: &fakePostRepository{posts: map[string]*domain.Post{
+			"post": {Id: "post", Creator: "owner", Message: "followers only"},
+		}},
+		commentRepository: &fakeCommentRepository{comments: map[string]*domain.Comment{
+			"owner-comment":    {Id: "owner-comment", PostId: "post", UserId: "owner", Value: "hi", CreatedAt: time.Now()},
+			"stranger-comment": {Id: "stranger-comment", PostId: "post", UserId: "stranger", Value: "hey", CreatedAt: time.Now()},
+		}},
+	}
+}
+
+func TestPostServiceVisiblePost(t *testing.T) {
+	service := newPrivatePostService()
+
+	tests := []struct {
+		viewerId string
+		wantErr  error
+	}{
+		{viewerId: "owner"},
+		{viewerId: "follower"},
+		{viewerId: "stranger", wantErr: repository.ErrRecordNotFound},
+		{viewerId: "blocked", wantErr: repository.ErrRecordNotFound},
+		{viewerId: "", wantErr: repository.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run("viewer "+tt.viewerId, func(t *testing.T) {
+			post, err := service.visiblePost(context.Background(), tt.viewerId, "post")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && post.Id != "post" {
+				t.Errorf("expected post, got %+v", post)
+			}
+		})
+	}
+}
+
+// The fakes panic on writes, so these also show nothing is written for a
+// viewer who may not see the post.
+func TestPostServiceRejectsInteractionsWithHiddenPosts(t *testing.T) {
+	ctx := context.Background()
+
+	interactions := []struct {
+		name     string
+		interact func(service *postService, userId string) error
+	}{
+		{
+			name: "comment",
+			interact: func(service *postService, userId string) error {
+				_, err := service.CommentPost(ctx, "post", userId, &dto.CommentReq{Value: "let me in"})
+				return err
+			},
+		},
+		{
+			name: "reply",
+			interact: func(service *postService, userId string) error {
+				_, err := service.CommentPost(ctx, "post", userId, &dto.CommentReq{Value: "let me in", ParentId: "owner-comment"})
+				return err
+			},
+		},
+		{
+			name: "like post",
+			interact: func(service *postService, userId string) error {
+				_, err := service.LikePost(ctx, "post", userId)
+				return err
+			},
+		},
+		{
+			name: "like comment",
+			interact: func(service *postService, userId string) error {
+				_, err := service.LikeComment(ctx, "post", "owner-comment", userId)
+				return err
+			},
+		},
+		{
+			name: "edit own comment",
+			interact: func(service *postService, userId string) error {
+				_, err := service.UpdateComment(ctx, "post", "stranger-comment", userId, &dto.UpdateCommentReq{Value: "edited"})
+				return err
+			},
+		},
+	}
+
+	for _, interaction := range interactions {
+		for _, userId := range []string{"stranger", "blocked"} {
+			t.Run(interaction.name+" as "+userId, func(t *testing.T) {
+				err := interaction.interact(newPrivatePostService(), userId)
+				if !errors.Is(err, repository.ErrRecordNotFound) {
+					t.Errorf("expected %v, got %v", repository.ErrRecordNotFound, err)
+				}
+			})
+		}
+	}
+}
+
+func TestPostServiceLikeCommentNotifies(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		userId     string
+		setLikeErr error
+		// liked lists the users liking owner-comment before the call.
+		liked      []string
+		wantErr    bool
+		wantLikes  []string
+		wantNotify bool
+	}{
+		{name: "like", userId: "follower", wantLikes: []string{"follower"}, wantNotify: true},
+		{name: "unlike", userId: "follower", liked: []string{"follower"}, wantLikes: []string{}},
+		{name: "like own comment", userId: "owner", wantLikes: []string{"owner"}},
+		{name: "liker without a profile", userId: "ghost", wantLikes: []string{"ghost"}},
+		{name: "like fails", userId: "follower", setLikeErr: errors.New("write failed"), wantErr: true, wantLikes: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPrivatePostService()
+			service.followRepository.(*fakeFollowRepository).follows["ghost"] = []string{"owner"}
+
+			comments := service.commentRepository.(*fakeCommentRepository)
+			comments.setLikeErr = tt.setLikeErr
+			comments.comments["owner-comment"].Likes = tt.liked
+
+			notifications := &fakeNotificationRepository{}
+			service.notifier = newNotifier(notifications, service.blockRepository, &fakeMuteRepository{})
+
+			_, err := service.LikeComment(ctx, "post", "owner-comment", tt.userId)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if likes := comments.comments["owner-comment"].Likes; len(likes) != len(tt.wantLikes) || (len(likes) > 0 && likes[0] != tt.wantLikes[0]) {
+				t.Errorf("expected likes %v, got %v", tt.wantLikes, likes)
+			}
+
+			if !tt.wantNotify {
+				if len(notifications.created) != 0 {
+					t.Errorf("expected no notification, got %+v", notifications.created[0])
+				}
+				return
+			}
+			if len(notifications.created) != 1 {
+				t.Fatalf("expected one notification, got %d", len(notifications.created))
+			}
+			if notif := notifications.created[0]; notif.SenderId != tt.userId || notif.ReceiverId != "owner" || notif.TargetId != "post" {
+				t.Errorf("expected a notification from %s to owner about post, got %+v", tt.userId, notif)
+			}
+		})
+	}
+}
@@ -1,26 +1,68 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"github.com/saleh-ghazimoradi/X-Gopher/infra/storage"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
 	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
 	"slices"
+	"strings"
 	"time"
 )
 
+// suggestionActivityWindow is how far back a candidate's posts count as recent activity.
+const suggestionActivityWindow = 30 * 24 * time.Hour
+
+// Profile images are cropped and scaled to these sizes.
+const (
+	avatarSize   = 400
+	bannerWidth  = 1500
+	bannerHeight = 500
+)
+
 type UserService interface {
 	GetUserById(ctx context.Context, id string) (*dto.UserResp, error)
-	GetSuggestedUsers(ctx context.Context, userId string) ([]*dto.UserResp, error)
-	UpdateUser(ctx context.Context, id string, input *dto.UpdateUserReq) (*dto.UserResp, error)
-	ToggleFollow(ctx context.Context, currentUserId, targetUserId string) (map[string]*dto.UserResp, error)
-	DeleteUser(ctx context.Context, id string) error
+	GetUserByHandle(ctx context.Context, handle string) (*dto.UserResp, error)
+	GetSuggestedUsers(ctx context.Context, userId string, page, limit int) ([]*dto.SuggestionResp, int64, error)
+	UpdateUser(ctx context.Context, actorId, id string, input *dto.UpdateUserReq) (*dto.UserResp, error)
+	UpdateHandle(ctx context.Context, actorId, id string, input *dto.UpdateHandleReq) (*dto.UserResp, error)
+	UploadAvatar(ctx context.Context, actorId, id string, data []byte) (*dto.UserResp, error)
+	UploadBanner(ctx context.Context, actorId, id string, data []byte) (*dto.UserResp, error)
+	DeleteAvatar(ctx context.Context, actorId, id string) (*dto.UserResp, error)
+	DeleteBanner(ctx context.Context, actorId, id string) (*dto.UserResp, error)
+	ToggleFollow(ctx context.Context, currentUserId, targetUserId string) (*dto.FollowStatusResp, error)
+	GetFollowRequests(ctx context.Context, userId, cursor string, limit int) ([]*dto.FollowRequestResp, string, error)
+	ApproveFollowRequest(ctx context.Context, actorId, requestId string) error
+	RejectFollowRequest(ctx context.Context, actorId, requestId string) error
+	GetFollowers(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error)
+	GetFollowing(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error)
+	GetMutuals(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error)
+	GetRelationship(ctx context.Context, viewerId, userId string) (*dto.RelationshipResp, error)
+	Block(ctx context.Context, blockerId, blockedId string) error
+	Unblock(ctx context.Context, blockerId, blockedId string) error
+	GetBlocks(ctx context.Context, userId, cursor string, limit int) ([]*dto.RestrictedUserResp, string, error)
+	Mute(ctx context.Context, muterId, mutedId string) error
+	Unmute(ctx context.Context, muterId, mutedId string) error
+	GetMutes(ctx context.Context, userId, cursor string, limit int) ([]*dto.RestrictedUserResp, string, error)
 }
 
 type userService struct {
-	userRepository         repository.UserRepository
-	notificationRepository repository.NotificationRepository
+	config                  *config.Config
+	userRepository          repository.UserRepository
+	followRepository        repository.FollowRepository
+	followRequestRepository repository.FollowRequestRepository
+	blockRepository         repository.BlockRepository
+	muteRepository          repository.MuteRepository
+	suggestionRepository    repository.SuggestionRepository
+	storage                 storage.Storage
+	notifier                *notifier
 }
 
 func (u *userService) GetUserById(ctx context.Context, id string) (*dto.UserResp, error) {
@@ -29,56 +71,110 @@ func (u *userService) GetUserById(ctx context.Context, id string) (*dto.UserResp
 		return nil, err
 	}
 
+	if user.IsDeactivated() {
+		return nil, repository.ErrRecordNotFound
+	}
+
 	return u.toUserResp(user), nil
 }
 
-func (u *userService) GetSuggestedUsers(ctx context.Context, userId string) ([]*dto.UserResp, error) {
-	mainUser, err := u.userRepository.GetUserById(ctx, userId)
+func (u *userService) GetUserByHandle(ctx context.Context, handle string) (*dto.UserResp, error) {
+	user, err := u.userRepository.GetUserByHandle(ctx, handle)
 	if err != nil {
 		return nil, err
 	}
 
-	suggestionSet := make(map[string]struct{})
+	if user.IsDeactivated() {
+		return nil, repository.ErrRecordNotFound
+	}
 
-	for _, followedID := range mainUser.Following {
-		followedUser, err := u.userRepository.GetUserById(ctx, followedID)
-		if err != nil {
-			continue
-		}
+	return u.toUserResp(user), nil
+}
 
-		for _, id := range followedUser.Following {
-			suggestionSet[id] = struct{}{}
-		}
+// GetSuggestedUsers ranks users followed by the people the user follows,
+// leaving out private accounts and anyone blocked either way.
+func (u *userService) GetSuggestedUsers(ctx context.Context, userId string, page, limit int) ([]*dto.SuggestionResp, int64, error) {
+	followingIds, err := u.followRepository.GetFollowingIds(ctx, userId)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		for _, id := range followedUser.Followers {
-			suggestionSet[id] = struct{}{}
-		}
+	blockedIds, err := u.blockRepository.GetRelatedIds(ctx, userId)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	delete(suggestionSet, userId)
-	for _, id := range mainUser.Following {
-		delete(suggestionSet, id)
+	suggestions, total, err := u.suggestionRepository.GetSuggestions(ctx, &repository.SuggestionQuery{
+		ViewerId:     userId,
+		FollowingIds: followingIds,
+		ExcludeIds:   slices.Concat([]string{userId}, followingIds, blockedIds),
+		ActiveSince:  time.Now().Add(-suggestionActivityWindow),
+		Offset:       (page - 1) * limit,
+		Limit:        limit,
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
-	ids := make([]string, 0, len(suggestionSet))
-	for id := range suggestionSet {
-		ids = append(ids, id)
+	var mutualIds []string
+	for _, suggestion := range suggestions {
+		mutualIds = append(mutualIds, suggestion.MutualIds...)
 	}
 
-	users, err := u.userRepository.GetUsersByIds(ctx, ids)
+	mutuals, err := u.userRepository.GetUsersByIds(ctx, mutualIds)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	resp := make([]*dto.UserResp, 0, len(users))
-	for _, user := range users {
-		resp = append(resp, u.toUserResp(user))
+	names := make(map[string]string, len(mutuals))
+	for _, mutual := range mutuals {
+		names[mutual.Id] = mutual.FirstName + " " + mutual.LastName
 	}
 
-	return resp, nil
+	resp := make([]*dto.SuggestionResp, len(suggestions))
+	for i, suggestion := range suggestions {
+		followedBy := make([]string, 0, len(suggestion.MutualIds))
+		for _, id := range suggestion.MutualIds {
+			if name, ok := names[id]; ok {
+				followedBy = append(followedBy, name)
+			}
+		}
+
+		resp[i] = &dto.SuggestionResp{
+			User:        u.toUserResp(suggestion.User),
+			MutualCount: suggestion.MutualCount,
+			FollowedBy:  followedBy,
+			Reason:      followedByReason(followedBy, suggestion.MutualCount),
+		}
+	}
+
+	return resp, total, nil
+}
+
+// followedByReason phrases the mutual connections, e.g. "Followed by Jane Doe, John Roe and 3 others".
+func followedByReason(names []string, count int64) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	others := count - int64(len(names))
+	switch {
+	case others == 1:
+		return "Followed by " + strings.Join(names, ", ") + " and 1 other"
+	case others > 1:
+		return fmt.Sprintf("Followed by %s and %d others", strings.Join(names, ", "), others)
+	case len(names) == 1:
+		return "Followed by " + names[0]
+	default:
+		return "Followed by " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
 }
 
-func (u *userService) UpdateUser(ctx context.Context, id string, input *dto.UpdateUserReq) (*dto.UserResp, error) {
+func (u *userService) UpdateUser(ctx context.Context, actorId, id string, input *dto.UpdateUserReq) (*dto.UserResp, error) {
+	if !canManageUser(ctx, actorId, id) {
+		return nil, repository.ErrForbidden
+	}
+
 	user, err := u.userRepository.GetUserById(ctx, id)
 	if err != nil {
 		return nil, err
@@ -92,22 +188,207 @@ func (u *userService) UpdateUser(ctx context.Context, id string, input *dto.Upda
 		user.LastName = *input.LastName
 	}
 
-	if input.ImageUrl != nil {
-		user.ImageUrl = *input.ImageUrl
-	}
-
 	if input.Bio != nil {
 		user.Bio = *input.Bio
 	}
 
+	wasPrivate := user.IsPrivate
+	if input.IsPrivate != nil {
+		user.IsPrivate = *input.IsPrivate
+	}
+
 	if err := u.userRepository.UpdateUser(ctx, user); err != nil {
 		return nil, err
 	}
 
+	// Going public lets everyone follow right away, so pending requests are approved.
+	if wasPrivate && !user.IsPrivate {
+		requests, err := u.followRequestRepository.GetAllByTargetId(ctx, user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get follow requests: %w", err)
+		}
+		for _, request := range requests {
+			if err := u.approve(ctx, request); err != nil {
+				return nil, fmt.Errorf("failed to approve follow request: %w", err)
+			}
+		}
+		if user, err = u.userRepository.GetUserById(ctx, user.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.toUserResp(user), nil
+}
+
+// UploadAvatar replaces the user's profile picture with a square crop of the
+// uploaded image and deletes the previous one.
+func (u *userService) UploadAvatar(ctx context.Context, actorId, id string, data []byte) (*dto.UserResp, error) {
+	if !canManageUser(ctx, actorId, id) {
+		return nil, repository.ErrForbidden
+	}
+
+	user, err := u.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := u.storeImage(ctx, "avatars/"+id, data, avatarSize, avatarSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepository.UpdateAvatar(ctx, id, url); err != nil {
+		u.removeImage(ctx, url)
+		return nil, err
+	}
+
+	u.removeImage(ctx, user.ImageUrl)
+	user.ImageUrl = url
+
+	return u.toUserResp(user), nil
+}
+
+// UploadBanner replaces the user's banner with a wide crop of the uploaded
+// image and deletes the previous one.
+func (u *userService) UploadBanner(ctx context.Context, actorId, id string, data []byte) (*dto.UserResp, error) {
+	if !canManageUser(ctx, actorId, id) {
+		return nil, repository.ErrForbidden
+	}
+
+	user, err := u.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := u.storeImage(ctx, "banners/"+id, data, bannerWidth, bannerHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepository.UpdateBanner(ctx, id, url); err != nil {
+		u.removeImage(ctx, url)
+		return nil, err
+	}
+
+	u.removeImage(ctx, user.BannerUrl)
+	user.BannerUrl = url
+
+	return u.toUserResp(user), nil
+}
+
+func (u *userService) DeleteAvatar(ctx context.Context, actorId, id string) (*dto.UserResp, error) {
+	if !canManageUser(ctx, actorId, id) {
+		return nil, repository.ErrForbidden
+	}
+
+	user, err := u.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepository.UpdateAvatar(ctx, id, ""); err != nil {
+		return nil, err
+	}
+
+	u.removeImage(ctx, user.ImageUrl)
+	user.ImageUrl = ""
+
 	return u.toUserResp(user), nil
 }
 
-func (u *userService) ToggleFollow(ctx context.Context, currentUserId, targetUserId string) (map[string]*dto.UserResp, error) {
+func (u *userService) DeleteBanner(ctx context.Context, actorId, id string) (*dto.UserResp, error) {
+	if !canManageUser(ctx, actorId, id) {
+		return nil, repository.ErrForbidden
+	}
+
+	user, err := u.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepository.UpdateBanner(ctx, id, ""); err != nil {
+		return nil, err
+	}
+
+	u.removeImage(ctx, user.BannerUrl)
+	user.BannerUrl = ""
+
+	return u.toUserResp(user), nil
+}
+
+// storeImage processes an uploaded image into a width x height JPEG and
+// stores it under a new random name in dir, returning its URL. New names keep
+// caches from serving the previous image.
+func (u *userService) storeImage(ctx context.Context, dir string, data []byte, width, height int) (string, error) {
+	if int64(len(data)) > u.config.Upload.MaxImageSize {
+		return "", repository.ErrImageTooLarge
+	}
+
+	processed, err := utils.ProcessImage(data, width, height)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", repository.ErrInvalidImage, err)
+	}
+
+	key := dir + "/" + strings.ToLower(rand.Text()) + ".jpg"
+	if err := u.storage.Put(ctx, key, bytes.NewReader(processed), "image/jpeg"); err != nil {
+		return "", fmt.Errorf("failed to store image: %w", err)
+	}
+
+	return u.storage.URL(key), nil
+}
+
+// removeImage deletes a stored image. URLs from elsewhere, such as a picture
+// imported from an identity provider, are left alone, and failures only leave
+// an orphaned file behind, so they don't fail the request.
+func (u *userService) removeImage(ctx context.Context, url string) {
+	if key, ok := u.storage.Key(url); ok {
+		_ = u.storage.Delete(ctx, key)
+	}
+}
+
+// UpdateHandle changes the user's handle. Users can change it once per
+// cooldown period; admins changing it on their behalf are not limited.
+func (u *userService) UpdateHandle(ctx context.Context, actorId, id string, input *dto.UpdateHandleReq) (*dto.UserResp, error) {
+	if !canManageUser(ctx, actorId, id) {
+		return nil, repository.ErrForbidden
+	}
+
+	user, err := u.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Handle == input.Handle {
+		return u.toUserResp(user), nil
+	}
+
+	now := time.Now()
+	if user.HandleChangedAt != nil && now.Sub(*user.HandleChangedAt) < u.config.Account.HandleChangeCooldown &&
+		!utils.HasPermission(ctx, domain.PermissionManageUsers) {
+		return nil, repository.ErrTooManyAttempts
+	}
+
+	owner, err := u.userRepository.GetUserByHandle(ctx, input.Handle)
+	switch {
+	case err == nil && owner.Id != user.Id:
+		return nil, repository.ErrDuplicateHandle
+	case err != nil && !errors.Is(err, repository.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to get user by handle: %w", err)
+	}
+
+	if err := u.userRepository.UpdateHandle(ctx, user.Id, input.Handle, now); err != nil {
+		return nil, err
+	}
+
+	user.Handle = input.Handle
+	user.HandleChangedAt = &now
+
+	return u.toUserResp(user), nil
+}
+
+// ToggleFollow follows or unfollows the target. Following a private account
+// sends a follow request instead, and toggling again withdraws the request.
+func (u *userService) ToggleFollow(ctx context.Context, currentUserId, targetUserId string) (*dto.FollowStatusResp, error) {
 	if currentUserId == targetUserId {
 		return nil, repository.ErrCannotFollowSelf
 	}
@@ -122,72 +403,578 @@ func (u *userService) ToggleFollow(ctx context.Context, currentUserId, targetUse
 		return nil, err
 	}
 
-	isFollowing := slices.Contains(target.Followers, currentUserId)
+	if target.IsDeactivated() {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	blocked, err := u.blockRepository.IsBlocked(ctx, currentUserId, targetUserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block status: %w", err)
+	}
+	if blocked {
+		return nil, repository.ErrBlocked
+	}
+
+	isFollowing, err := u.followRepository.IsFollowing(ctx, currentUserId, targetUserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follow status: %w", err)
+	}
+
+	pending, err := u.followRequestRepository.Get(ctx, currentUserId, targetUserId)
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get follow request: %w", err)
+	}
 
-	if isFollowing {
-		target.Followers = removeString(target.Followers, currentUserId)
-		current.Following = removeString(current.Following, targetUserId)
-		if err := u.userRepository.Unfollow(ctx, currentUserId, targetUserId); err != nil {
+	status := domain.FollowStatusNone
+	switch {
+	case isFollowing:
+		if err := u.followRepository.Unfollow(ctx, currentUserId, targetUserId); err != nil {
 			return nil, fmt.Errorf("failed to unfollow user: %w", err)
 		}
-	} else {
-		target.Followers = append(target.Followers, currentUserId)
-		current.Following = append(current.Following, targetUserId)
-
-		notif := &domain.Notification{
-			SenderId:   currentUserId,
-			ReceiverId: targetUserId,
-			TargetId:   currentUserId,
-			Details:    current.FirstName + " " + current.LastName + " started following you!",
-			IsRead:     false,
-			CreatedAt:  time.Now(),
-			NotificationUser: domain.NotificationUser{
-				Name:   current.FirstName + " " + current.LastName,
-				Avatar: current.ImageUrl,
-			},
+	case pending != nil:
+		if err := u.followRequestRepository.Delete(ctx, pending.Id); err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to withdraw follow request: %w", err)
+		}
+	case target.IsPrivate:
+		if err := u.followRequestRepository.Create(ctx, &domain.FollowRequest{
+			RequesterId: currentUserId,
+			TargetId:    targetUserId,
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to request follow: %w", err)
 		}
-		_ = u.notificationRepository.Create(ctx, notif)
 
-		if err := u.userRepository.Follow(ctx, currentUserId, targetUserId); err != nil {
+		u.notify(ctx, current, targetUserId, " requested to follow you")
+		status = domain.FollowStatusRequested
+	default:
+		if err := u.followRepository.Follow(ctx, &domain.Follow{
+			FollowerId: currentUserId,
+			FolloweeId: targetUserId,
+			CreatedAt:  time.Now(),
+		}); err != nil {
 			return nil, fmt.Errorf("failed to follow: %w", err)
 		}
+
+		u.notify(ctx, current, targetUserId, " started following you!")
+		status = domain.FollowStatusFollowing
+	}
+
+	// Reload both users so the response carries the updated counters.
+	if current, err = u.userRepository.GetUserById(ctx, currentUserId); err != nil {
+		return nil, err
+	}
+
+	if target, err = u.userRepository.GetUserById(ctx, targetUserId); err != nil {
+		return nil, err
 	}
 
-	return map[string]*dto.UserResp{
-		"target_user":  u.toUserResp(target),
-		"current_user": u.toUserResp(current),
+	return &dto.FollowStatusResp{
+		TargetUser:  u.toUserResp(target),
+		CurrentUser: u.toUserResp(current),
+		Status:      status,
 	}, nil
 }
 
-func (u *userService) DeleteUser(ctx context.Context, id string) error {
-	return u.userRepository.DeleteUser(ctx, id)
+func (u *userService) GetFollowRequests(ctx context.Context, userId, cursor string, limit int) ([]*dto.FollowRequestResp, string, error) {
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	requests, err := u.followRequestRepository.GetByTargetId(ctx, userId, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(requests) > limit {
+		requests = requests[:limit]
+		last := requests[len(requests)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.Id)
+	}
+
+	ids := make([]string, len(requests))
+	for i, request := range requests {
+		ids[i] = request.RequesterId
+	}
+
+	users, err := u.userRepository.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+
+	byId := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		byId[user.Id] = user
+	}
+
+	resp := make([]*dto.FollowRequestResp, 0, len(requests))
+	for _, request := range requests {
+		requester, ok := byId[request.RequesterId]
+		if !ok {
+			continue
+		}
+		resp = append(resp, &dto.FollowRequestResp{
+			Id:          request.Id,
+			RequesterId: requester.Id,
+			FirstName:   requester.FirstName,
+			LastName:    requester.LastName,
+			Handle:      requester.Handle,
+			ImageUrl:    requester.ImageUrl,
+			CreatedAt:   request.CreatedAt,
+		})
+	}
+
+	return resp, nextCursor, nil
+}
+
+// ApproveFollowRequest turns the request into a follow. Only the requested user can approve.
+func (u *userService) ApproveFollowRequest(ctx context.Context, actorId, requestId string) error {
+	request, err := u.followRequestRepository.GetById(ctx, requestId)
+	if err != nil {
+		return err
+	}
+
+	if request.TargetId != actorId {
+		return repository.ErrForbidden
+	}
+
+	return u.approve(ctx, request)
+}
+
+func (u *userService) RejectFollowRequest(ctx context.Context, actorId, requestId string) error {
+	request, err := u.followRequestRepository.GetById(ctx, requestId)
+	if err != nil {
+		return err
+	}
+
+	if request.TargetId != actorId {
+		return repository.ErrForbidden
+	}
+
+	return u.followRequestRepository.Delete(ctx, request.Id)
+}
+
+func (u *userService) approve(ctx context.Context, request *domain.FollowRequest) error {
+	if err := u.followRepository.Follow(ctx, &domain.Follow{
+		FollowerId: request.RequesterId,
+		FolloweeId: request.TargetId,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to follow: %w", err)
+	}
+
+	if err := u.followRequestRepository.Delete(ctx, request.Id); err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		return err
+	}
+
+	if target, err := u.userRepository.GetUserById(ctx, request.TargetId); err == nil {
+		u.notify(ctx, target, request.RequesterId, " accepted your follow request")
+	}
+
+	return nil
+}
+
+// notify sends receiverId a notification about something sender did, e.g. " started following you!".
+func (u *userService) notify(ctx context.Context, sender *domain.User, receiverId, action string) {
+	notif := &domain.Notification{
+		SenderId:   sender.Id,
+		ReceiverId: receiverId,
+		TargetId:   sender.Id,
+		Details:    sender.FirstName + " " + sender.LastName + action,
+		IsRead:     false,
+		CreatedAt:  time.Now(),
+		NotificationUser: domain.NotificationUser{
+			Name:   sender.FirstName + " " + sender.LastName,
+			Avatar: sender.ImageUrl,
+		},
+	}
+	u.notifier.notify(ctx, notif)
+}
+
+func (u *userService) GetFollowers(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error) {
+	return u.listFollows(ctx, viewerId, userId, cursor, limit, u.followRepository.GetFollowers, func(follow *domain.Follow) string {
+		return follow.FollowerId
+	})
+}
+
+func (u *userService) GetFollowing(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error) {
+	return u.listFollows(ctx, viewerId, userId, cursor, limit, u.followRepository.GetFollowing, func(follow *domain.Follow) string {
+		return follow.FolloweeId
+	})
+}
+
+// GetMutuals lists the people the viewer follows who also follow the user.
+func (u *userService) GetMutuals(ctx context.Context, viewerId, userId, cursor string, limit int) ([]*dto.FollowEntryResp, string, error) {
+	followingIds, err := u.followRepository.GetFollowingIds(ctx, viewerId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	getPage := func(ctx context.Context, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error) {
+		return u.followRepository.GetFollowersAmong(ctx, userId, followingIds, after, limit)
+	}
+
+	return u.listFollows(ctx, viewerId, userId, cursor, limit, getPage, func(follow *domain.Follow) string {
+		return follow.FollowerId
+	})
+}
+
+func (u *userService) GetRelationship(ctx context.Context, viewerId, userId string) (*dto.RelationshipResp, error) {
+	user, err := u.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsDeactivated() {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	resp := &dto.RelationshipResp{UserId: user.Id}
+	if viewerId == userId {
+		return resp, nil
+	}
+
+	if resp.Following, err = u.followRepository.IsFollowing(ctx, viewerId, userId); err != nil {
+		return nil, err
+	}
+
+	if resp.FollowedBy, err = u.followRepository.IsFollowing(ctx, userId, viewerId); err != nil {
+		return nil, err
+	}
+
+	if resp.Blocking, err = u.blockRepository.HasBlocked(ctx, viewerId, userId); err != nil {
+		return nil, err
+	}
+
+	if resp.BlockedBy, err = u.blockRepository.HasBlocked(ctx, userId, viewerId); err != nil {
+		return nil, err
+	}
+
+	if resp.Muting, err = u.muteRepository.IsMuted(ctx, viewerId, userId); err != nil {
+		return nil, err
+	}
+
+	if resp.FollowRequestSent, err = u.hasFollowRequest(ctx, viewerId, userId); err != nil {
+		return nil, err
+	}
+
+	if resp.FollowRequestReceived, err = u.hasFollowRequest(ctx, userId, viewerId); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (u *userService) hasFollowRequest(ctx context.Context, requesterId, targetId string) (bool, error) {
+	if _, err := u.followRequestRepository.Get(ctx, requesterId, targetId); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// listFollows loads one page of edges and the users on the other side of them
+// in two queries, then flags the users the viewer follows. The returned cursor
+// is empty on the last page.
+func (u *userService) listFollows(
+	ctx context.Context,
+	viewerId, userId, cursor string,
+	limit int,
+	getPage func(ctx context.Context, userId string, after *domain.PageCursor, limit int) ([]*domain.Follow, error),
+	otherSide func(follow *domain.Follow) string,
+) ([]*dto.FollowEntryResp, string, error) {
+	user, err := u.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if user.IsDeactivated() {
+		return nil, "", repository.ErrRecordNotFound
+	}
+
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// One extra edge tells whether another page exists.
+	follows, err := getPage(ctx, userId, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(follows) > limit {
+		follows = follows[:limit]
+		last := follows[len(follows)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.Id)
+	}
+
+	ids := make([]string, len(follows))
+	for i, follow := range follows {
+		ids[i] = otherSide(follow)
+	}
+
+	users, err := u.userRepository.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, "", err
+	}
+
+	byId := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		byId[user.Id] = user
+	}
+
+	followed, err := u.followRepository.GetFollowedAmong(ctx, viewerId, ids)
+	if err != nil {
+		return nil, "", err
+	}
+
+	followedByMe := make(map[string]bool, len(followed))
+	for _, id := range followed {
+		followedByMe[id] = true
+	}
+
+	entries := make([]*dto.FollowEntryResp, 0, len(follows))
+	for _, follow := range follows {
+		// Deactivated users stay in the graph but are not listed.
+		other, ok := byId[otherSide(follow)]
+		if !ok {
+			continue
+		}
+		entries = append(entries, &dto.FollowEntryResp{
+			Id:             other.Id,
+			FirstName:      other.FirstName,
+			LastName:       other.LastName,
+			Handle:         other.Handle,
+			ImageUrl:       other.ImageUrl,
+			Bio:            other.Bio,
+			FollowersCount: other.FollowersCount,
+			FollowingCount: other.FollowingCount,
+			IsFollowedByMe: followedByMe[other.Id],
+			FollowedAt:     follow.CreatedAt,
+		})
+	}
+
+	return entries, nextCursor, nil
+}
+
+// Block blocks the user and removes follows and pending follow requests in both directions.
+func (u *userService) Block(ctx context.Context, blockerId, blockedId string) error {
+	if blockerId == blockedId {
+		return repository.ErrCannotBlockSelf
+	}
+
+	if _, err := u.userRepository.GetUserById(ctx, blockedId); err != nil {
+		return err
+	}
+
+	if err := u.blockRepository.Create(ctx, &domain.Block{
+		BlockerId: blockerId,
+		BlockedId: blockedId,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	for _, pair := range [][2]string{{blockerId, blockedId}, {blockedId, blockerId}} {
+		if err := u.followRepository.Unfollow(ctx, pair[0], pair[1]); err != nil {
+			return fmt.Errorf("failed to unfollow user: %w", err)
+		}
+
+		request, err := u.followRequestRepository.Get(ctx, pair[0], pair[1])
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				continue
+			}
+			return fmt.Errorf("failed to get follow request: %w", err)
+		}
+
+		if err := u.followRequestRepository.Delete(ctx, request.Id); err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+			return fmt.Errorf("failed to delete follow request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Unblock lifts the block. Removed follows are not restored.
+func (u *userService) Unblock(ctx context.Context, blockerId, blockedId string) error {
+	return u.blockRepository.Delete(ctx, blockerId, blockedId)
+}
+
+func (u *userService) GetBlocks(ctx context.Context, userId, cursor string, limit int) ([]*dto.RestrictedUserResp, string, error) {
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blocks, err := u.blockRepository.GetByBlockerId(ctx, userId, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(blocks) > limit {
+		blocks = blocks[:limit]
+		last := blocks[len(blocks)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.Id)
+	}
+
+	ids := make([]string, len(blocks))
+	createdAt := make(map[string]time.Time, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.BlockedId
+		createdAt[block.BlockedId] = block.CreatedAt
+	}
+
+	entries, err := u.toRestrictedUsers(ctx, ids, createdAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entries, nextCursor, nil
 }
 
-func removeString(slice []string, s string) []string {
-	for i, v := range slice {
-		if v == s {
-			return append(slice[:i], slice[i+1:]...)
+// Mute hides the user's posts from the muter's feed and search and drops their notifications.
+func (u *userService) Mute(ctx context.Context, muterId, mutedId string) error {
+	if muterId == mutedId {
+		return repository.ErrCannotMuteSelf
+	}
+
+	if _, err := u.userRepository.GetUserById(ctx, mutedId); err != nil {
+		return err
+	}
+
+	if err := u.muteRepository.Create(ctx, &domain.Mute{
+		MuterId:   muterId,
+		MutedId:   mutedId,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to mute user: %w", err)
+	}
+
+	return nil
+}
+
+func (u *userService) Unmute(ctx context.Context, muterId, mutedId string) error {
+	return u.muteRepository.Delete(ctx, muterId, mutedId)
+}
+
+func (u *userService) GetMutes(ctx context.Context, userId, cursor string, limit int) ([]*dto.RestrictedUserResp, string, error) {
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mutes, err := u.muteRepository.GetByMuterId(ctx, userId, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(mutes) > limit {
+		mutes = mutes[:limit]
+		last := mutes[len(mutes)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.Id)
+	}
+
+	ids := make([]string, len(mutes))
+	createdAt := make(map[string]time.Time, len(mutes))
+	for i, mute := range mutes {
+		ids[i] = mute.MutedId
+		createdAt[mute.MutedId] = mute.CreatedAt
+	}
+
+	entries, err := u.toRestrictedUsers(ctx, ids, createdAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return entries, nextCursor, nil
+}
+
+// toRestrictedUsers builds block or mute list entries in the order of ids, skipping deactivated users.
+func (u *userService) toRestrictedUsers(ctx context.Context, ids []string, createdAt map[string]time.Time) ([]*dto.RestrictedUserResp, error) {
+	users, err := u.userRepository.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*domain.User, len(users))
+	for _, user := range users {
+		byId[user.Id] = user
+	}
+
+	entries := make([]*dto.RestrictedUserResp, 0, len(ids))
+	for _, id := range ids {
+		user, ok := byId[id]
+		if !ok {
+			continue
 		}
+		entries = append(entries, &dto.RestrictedUserResp{
+			UserId:    user.Id,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Handle:    user.Handle,
+			ImageUrl:  user.ImageUrl,
+			CreatedAt: createdAt[id],
+		})
 	}
-	return slice
+
+	return entries, nil
+}
+
+// decodePageCursor turns an opaque list cursor into a page position. An empty cursor starts at the top.
+func decodePageCursor(cursor string) (*domain.PageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	createdAt, id, err := utils.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PageCursor{CreatedAt: createdAt, Id: id}, nil
+}
+
+// canManageUser reports whether the actor may modify the given account:
+// users can manage their own account, admins can manage any account.
+func canManageUser(ctx context.Context, actorId, id string) bool {
+	return actorId == id || utils.HasPermission(ctx, domain.PermissionManageUsers)
 }
 
 func (u *userService) toUserResp(input *domain.User) *dto.UserResp {
 	return &dto.UserResp{
-		Id:        input.Id,
-		FirstName: input.FirstName,
-		LastName:  input.LastName,
-		Email:     input.Email,
-		ImageUrl:  input.ImageUrl,
-		Bio:       input.Bio,
-		Followers: input.Followers,
-		Following: input.Following,
+		Id:             input.Id,
+		FirstName:      input.FirstName,
+		LastName:       input.LastName,
+		Email:          input.Email,
+		Handle:         input.Handle,
+		ImageUrl:       input.ImageUrl,
+		BannerUrl:      input.BannerUrl,
+		Bio:            input.Bio,
+		Role:           input.Role,
+		IsPrivate:      input.IsPrivate,
+		FollowersCount: input.FollowersCount,
+		FollowingCount: input.FollowingCount,
 	}
 }
 
-func NewUserService(userRepository repository.UserRepository, notificationRepository repository.NotificationRepository) UserService {
+func NewUserService(config *config.Config, userRepository repository.UserRepository, followRepository repository.FollowRepository, followRequestRepository repository.FollowRequestRepository, blockRepository repository.BlockRepository, muteRepository repository.MuteRepository, suggestionRepository repository.SuggestionRepository, notificationRepository repository.NotificationRepository, storage storage.Storage) UserService {
 	return &userService{
-		userRepository:         userRepository,
-		notificationRepository: notificationRepository,
+		config:                  config,
+		userRepository:          userRepository,
+		followRepository:        followRepository,
+		followRequestRepository: followRequestRepository,
+		blockRepository:         blockRepository,
+		muteRepository:          muteRepository,
+		suggestionRepository:    suggestionRepository,
+		storage:                 storage,
+		notifier:                newNotifier(notificationRepository, blockRepository, muteRepository),
 	}
 }
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/domain"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/dto"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/repository"
+	"github.com/saleh-ghazimoradi/X-Gopher/utils"
+	"testing"
+)
+
+func actorContext(role string) context.Context {
+	return utils.WithRole(context.Background(), role, domain.PermissionsFor(role))
+}
+
+func TestCanManageUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		actorId string
+		id      string
+		want    bool
+	}{
+		{name: "own account", ctx: actorContext(domain.RoleUser), actorId: "1", id: "1", want: true},
+		{name: "another user's account", ctx: actorContext(domain.RoleUser), actorId: "1", id: "2", want: false},
+		{name: "moderator without manage users", ctx: actorContext(domain.RoleModerator), actorId: "1", id: "2", want: false},
+		{name: "admin with manage users", ctx: actorContext(domain.RoleAdmin), actorId: "1", id: "2", want: true},
+		{
+			name:    "manage users permission alone",
+			ctx:     utils.WithRole(context.Background(), "", []string{domain.PermissionManageUsers}),
+			actorId: "1",
+			id:      "2",
+			want:    true,
+		},
+		{name: "no role in context", ctx: context.Background(), actorId: "1", id: "2", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canManageUser(tt.ctx, tt.actorId, tt.id); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUserServiceUpdateUserAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		actorId string
+		wantErr error
+	}{
+		{name: "own profile", role: domain.RoleUser, actorId: "2"},
+		{name: "another user's profile", role: domain.RoleUser, actorId: "1", wantErr: repository.ErrForbidden},
+		{name: "admin updates another user", role: domain.RoleAdmin, actorId: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(&domain.User{Id: "2", FirstName: "Jane", Role: domain.RoleUser})
+			service := &userService{userRepository: users}
+
+			firstName := "Janet"
+			resp, err := service.UpdateUser(actorContext(tt.role), tt.actorId, "2", &dto.UpdateUserReq{FirstName: &firstName})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			want := "Janet"
+			if tt.wantErr != nil {
+				want = "Jane"
+			} else if resp.FirstName != want {
+				t.Errorf("expected response first name %q, got %q", want, resp.FirstName)
+			}
+			if got := users.users["2"].FirstName; got != want {
+				t.Errorf("expected stored first name %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestAccountServiceDeactivateAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		actorId string
+		wantErr error
+	}{
+		{name: "own account", role: domain.RoleUser, actorId: "2"},
+		{name: "another user's account", role: domain.RoleUser, actorId: "1", wantErr: repository.ErrForbidden},
+		{name: "moderator deletes another user", role: domain.RoleModerator, actorId: "1", wantErr: repository.ErrForbidden},
+		{name: "admin deletes another user", role: domain.RoleAdmin, actorId: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepository(&domain.User{Id: "2", Role: domain.RoleUser})
+			revoked := &credentialRevocations{}
+			service := &accountService{
+				userRepository:                users,
+				tokenRepository:               &fakeTokenRepository{credentialRevocations: revoked},
+				personalAccessTokenRepository: &fakePersonalAccessTokenRepository{credentialRevocations: revoked},
+				oauthGrantRepository:          &fakeOAuthGrantRepository{credentialRevocations: revoked},
+				oauthTokenRepository:          &fakeOAuthTokenRepository{credentialRevocations: revoked},
+			}
+
+			err := service.Deactivate(actorContext(tt.role), tt.actorId, "2")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			deactivated := users.users["2"].IsDeactivated()
+			if tt.wantErr != nil {
+				if deactivated || len(revoked.userIds) != 0 {
+					t.Errorf("expected account untouched, got deactivated=%v revoked=%v", deactivated, revoked.userIds)
+				}
+				return
+			}
+			if !deactivated {
+				t.Error("expected account to be deactivated")
+			}
+			if len(revoked.userIds) != 4 {
+				t.Errorf("expected all four credential stores revoked, got %v", revoked.userIds)
+			}
+		})
+	}
+}
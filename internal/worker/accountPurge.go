@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"log/slog"
+	"time"
+)
+
+// AccountPurge periodically purges accounts whose deactivation grace period has ended.
+type AccountPurge struct {
+	accountService service.AccountService
+	interval       time.Duration
+	logger         *slog.Logger
+}
+
+// Run purges once immediately and then on every interval until ctx is cancelled.
+func (a *AccountPurge) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *AccountPurge) purge(ctx context.Context) {
+	purged, err := a.accountService.PurgeExpired(ctx)
+	if err != nil {
+		a.logger.Error("account purge failed", "purged", purged, "error", err)
+	}
+	if purged > 0 {
+		a.logger.Info("purged deactivated accounts", "purged", purged)
+	}
+}
+
+func NewAccountPurge(accountService service.AccountService, interval time.Duration, logger *slog.Logger) *AccountPurge {
+	return &AccountPurge{
+		accountService: accountService,
+		interval:       interval,
+		logger:         logger,
+	}
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"log/slog"
+	"time"
+)
+
+// DataExportCleanup periodically deletes data export archives past their
+// retention and fails exports whose build never finished.
+type DataExportCleanup struct {
+	dataExportService service.DataExportService
+	interval          time.Duration
+	logger            *slog.Logger
+}
+
+// Run cleans up once immediately and then on every interval until ctx is cancelled.
+func (d *DataExportCleanup) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.cleanup(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *DataExportCleanup) cleanup(ctx context.Context) {
+	failed, err := d.dataExportService.FailStale(ctx)
+	if err != nil {
+		d.logger.Error("failing stale data exports failed", "error", err)
+	} else if failed > 0 {
+		d.logger.Info("failed stale data exports", "failed", failed)
+	}
+
+	deleted, err := d.dataExportService.DeleteExpired(ctx)
+	if err != nil {
+		d.logger.Error("data export cleanup failed", "deleted", deleted, "error", err)
+		return
+	}
+	if deleted > 0 {
+		d.logger.Info("deleted expired data exports", "deleted", deleted)
+	}
+}
+
+func NewDataExportCleanup(dataExportService service.DataExportService, interval time.Duration, logger *slog.Logger) *DataExportCleanup {
+	return &DataExportCleanup{
+		dataExportService: dataExportService,
+		interval:          interval,
+		logger:            logger,
+	}
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/X-Gopher/internal/service"
+	"log/slog"
+	"time"
+)
+
+// MediaCleanup periodically deletes abandoned uploads and media no post uses.
+type MediaCleanup struct {
+	mediaService service.MediaService
+	interval     time.Duration
+	logger       *slog.Logger
+}
+
+// Run cleans up once immediately and then on every interval until ctx is cancelled.
+func (m *MediaCleanup) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.cleanup(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *MediaCleanup) cleanup(ctx context.Context) {
+	deleted, err := m.mediaService.DeleteUnattached(ctx)
+	if err != nil {
+		m.logger.Error("media cleanup failed", "deleted", deleted, "error", err)
+		return
+	}
+	if deleted > 0 {
+		m.logger.Info("deleted unattached media", "deleted", deleted)
+	}
+}
+
+func NewMediaCleanup(mediaService service.MediaService, interval time.Duration, logger *slog.Logger) *MediaCleanup {
+	return &MediaCleanup{
+		mediaService: mediaService,
+		interval:     interval,
+		logger:       logger,
+	}
+}
@@ -1,11 +1,19 @@
 package utils
 
-import "context"
+import (
+	"context"
+	"slices"
+)
 
 type ContextKey string
 
 const (
-	UserIdKey ContextKey = "user_id"
+	UserIdKey      ContextKey = "user_id"
+	ScopesKey      ContextKey = "scopes"
+	ClientIPKey    ContextKey = "client_ip"
+	UserAgentKey   ContextKey = "user_agent"
+	RoleKey        ContextKey = "role"
+	PermissionsKey ContextKey = "permissions"
 )
 
 func WithUserId(ctx context.Context, id string) context.Context {
@@ -16,3 +24,49 @@ func UserIdFromContext(ctx context.Context) (string, bool) {
 	userId, ok := ctx.Value(UserIdKey).(string)
 	return userId, ok
 }
+
+// WithScopes restricts the request to the given scopes. Requests authenticated
+// with a regular session carry no scopes and are not restricted.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, ScopesKey, scopes)
+}
+
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(ScopesKey).([]string)
+	return scopes, ok
+}
+
+func HasScope(ctx context.Context, scope string) bool {
+	scopes, restricted := ScopesFromContext(ctx)
+	return !restricted || slices.Contains(scopes, scope)
+}
+
+func WithClientInfo(ctx context.Context, ip, userAgent string) context.Context {
+	ctx = context.WithValue(ctx, ClientIPKey, ip)
+	return context.WithValue(ctx, UserAgentKey, userAgent)
+}
+
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
+}
+
+func UserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(UserAgentKey).(string)
+	return userAgent
+}
+
+func WithRole(ctx context.Context, role string, permissions []string) context.Context {
+	ctx = context.WithValue(ctx, RoleKey, role)
+	return context.WithValue(ctx, PermissionsKey, permissions)
+}
+
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(RoleKey).(string)
+	return role
+}
+
+func HasPermission(ctx context.Context, permission string) bool {
+	permissions, _ := ctx.Value(PermissionsKey).([]string)
+	return slices.Contains(permissions, permission)
+}
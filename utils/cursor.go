@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor builds an opaque pagination cursor pointing at the item with
+// the given creation time and id. Clients must pass it back unchanged.
+func EncodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(createdAt.UnixNano(), 10) + ":" + id))
+}
+
+// DecodeCursor reverses EncodeCursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	return time.Unix(0, n), id, nil
+}
+
+// EncodeRankedCursor builds a cursor for lists ranked by a count, such as top
+// comments, where ties fall back to creation time and id.
+func EncodeRankedCursor(score int64, createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(score, 10) + ":" + strconv.FormatInt(createdAt.UnixNano(), 10) + ":" + id))
+}
+
+// DecodeRankedCursor reverses EncodeRankedCursor.
+func DecodeRankedCursor(cursor string) (int64, time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, time.Time{}, "", ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || parts[2] == "" {
+		return 0, time.Time{}, "", ErrInvalidCursor
+	}
+
+	score, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, "", ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, "", ErrInvalidCursor
+	}
+
+	return score, time.Unix(0, nanos), parts[2], nil
+}
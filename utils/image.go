@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+// MaxImagePixels bounds the decoded size of an upload, so a small file can't
+// expand into gigabytes of pixels.
+const MaxImagePixels = 25_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("image must be a JPEG, PNG or GIF")
+	ErrImageDimensions  = errors.New("image dimensions are too large")
+)
+
+// ImageContentType sniffs the content type of data and reports whether it is
+// an image format uploads accept. The client's claimed type is never trusted.
+func ImageContentType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return contentType, true
+	default:
+		return contentType, false
+	}
+}
+
+// ProcessImage turns an upload into a width x height JPEG: it is rotated
+// upright according to its EXIF orientation, cropped around the centre to the
+// target aspect ratio and scaled. Re-encoding drops EXIF and every other
+// piece of metadata the original carried.
+func ProcessImage(data []byte, width, height int) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	cropW, cropH := bounds.Dx(), bounds.Dy()
+	if cropW*height > cropH*width {
+		cropW = cropH * width / height
+	} else {
+		cropH = cropW * height / width
+	}
+	crop := image.Rect(0, 0, max(cropW, 1), max(cropH, 1)).Add(image.Pt((bounds.Dx()-cropW)/2, (bounds.Dy()-cropH)/2))
+
+	return encodeJPEG(scaleImage(img, crop, width, height))
+}
+
+// FitImage scales an upload down so it fits within maxSide x maxSide, keeping
+// its aspect ratio, and re-encodes it as JPEG. Smaller images keep their size.
+func FitImage(data []byte, maxSide int) ([]byte, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			width, height = maxSide, max(height*maxSide/width, 1)
+		} else {
+			width, height = max(width*maxSide/height, 1), maxSide
+		}
+	}
+
+	return encodeJPEG(scaleImage(img, bounds, width, height))
+}
+
+// ImageSize returns the pixel dimensions of an encoded image without decoding it.
+func ImageSize(data []byte) (int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return config.Width, config.Height, nil
+}
+
+// decodeImage checks the format and dimensions before decoding and returns
+// the image upright on a white background, ready to be encoded as JPEG.
+func decodeImage(data []byte) (*image.RGBA, error) {
+	contentType, ok := ImageContentType(data)
+	if !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxImagePixels {
+		return nil, ErrImageDimensions
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Over)
+
+	if contentType == "image/jpeg" {
+		return orient(rgba, jpegOrientation(data)), nil
+	}
+	return rgba, nil
+}
+
+// scaleImage resamples the src rectangle of img to width x height, averaging
+// every source pixel that falls into a target pixel.
+func scaleImage(img *image.RGBA, src image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcW, srcH := src.Dx(), src.Dy()
+
+	for y := 0; y < height; y++ {
+		y0 := src.Min.Y + y*srcH/height
+		y1 := max(src.Min.Y+(y+1)*srcH/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := src.Min.X + x*srcW/width
+			x1 := max(src.Min.X+(x+1)*srcW/width, x0+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := img.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(img.Pix[i])
+					g += int(img.Pix[i+1])
+					b += int(img.Pix[i+2])
+					a += int(img.Pix[i+3])
+					i += 4
+					n++
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// orient applies an EXIF orientation (1-8) so the image displays upright.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], img.Pix[img.PixOffset(sx, sy):img.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation reads the orientation tag from a JPEG's EXIF segment. It
+// returns 1, meaning no transformation, when there is none or it can't be read.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			break
+		}
+
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + size
+	}
+
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[offset : offset+2]))
+	for n := 0; n < entries; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+
+	return 1
+}
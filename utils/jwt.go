@@ -7,39 +7,73 @@ import (
 	"time"
 )
 
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidTokenType = errors.New("invalid token type")
+)
+
 type Claims struct {
-	UserId string `json:"user_id"`
-	Email  string `json:"email"`
+	UserId      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role,omitempty"`
+	Permissions []string  `json:"permissions,omitempty"`
+	TokenType   TokenType `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(cfg *config.Config, userId, email string) (accessToken, refreshToken string, err error) {
-	accessClaims := &Claims{
-		UserId: userId,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.ExpiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// audienceFor returns the audience a token of the given type is issued to.
+// Access tokens are meant for the API, refresh tokens only for the issuer itself.
+func audienceFor(cfg *config.Config, tokenType TokenType) string {
+	if tokenType == TokenTypeRefresh {
+		return cfg.JWT.Issuer
 	}
+	return cfg.JWT.Audience
+}
 
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = at.SignedString([]byte(cfg.JWT.Secret))
-	if err != nil {
-		return "", "", err
+func expiryFor(cfg *config.Config, tokenType TokenType) time.Duration {
+	if tokenType == TokenTypeRefresh {
+		return cfg.JWT.RefreshTokenExpires
 	}
+	return cfg.JWT.ExpiresIn
+}
 
-	refreshClaims := &Claims{
-		UserId: userId,
-		Email:  email,
+func signToken(cfg *config.Config, tokenType TokenType, userId, email, role string, permissions []string) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserId:      userId,
+		Email:       email,
+		Role:        role,
+		Permissions: permissions,
+		TokenType:   tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.RefreshTokenExpires)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    cfg.JWT.Issuer,
+			Subject:   userId,
+			Audience:  jwt.ClaimStrings{audienceFor(cfg, tokenType)},
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiryFor(cfg, tokenType))),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = rt.SignedString([]byte(cfg.JWT.Secret))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWT.Secret))
+}
+
+// GenerateToken issues an access token carrying the user's role and permissions,
+// and a refresh token that carries neither so a role change applies on the next refresh.
+func GenerateToken(cfg *config.Config, userId, email, role string, permissions []string) (accessToken, refreshToken string, err error) {
+	accessToken, err = signToken(cfg, TokenTypeAccess, userId, email, role, permissions)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err = signToken(cfg, TokenTypeRefresh, userId, email, "", nil)
 	if err != nil {
 		return "", "", err
 	}
@@ -47,16 +81,31 @@ func GenerateToken(cfg *config.Config, userId, email string) (accessToken, refre
 	return accessToken, refreshToken, nil
 }
 
-func ValidateToken(tokenString, secret string) (*Claims, error) {
+// ValidateToken parses the token and checks its signature, expiry, issuer,
+// audience and type. Clock skew between services is tolerated up to cfg.JWT.Leeway.
+func ValidateToken(cfg *config.Config, tokenString string, tokenType TokenType) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		return []byte(secret), nil
-	})
+		return []byte(cfg.JWT.Secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(cfg.JWT.Issuer),
+		jwt.WithAudience(audienceFor(cfg, tokenType)),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.JWT.Leeway),
+	)
 	if err != nil {
 		return nil, err
 	}
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidTokenType
 	}
 
-	return nil, errors.New("invalid token")
+	return claims, nil
 }
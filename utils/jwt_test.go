@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saleh-ghazimoradi/X-Gopher/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testJWTConfig() *config.Config {
+	return &config.Config{
+		JWT: config.JWT{
+			Secret:              "test-secret",
+			ExpiresIn:           15 * time.Minute,
+			RefreshTokenExpires: 24 * time.Hour,
+			Issuer:              "x-gopher",
+			Audience:            "x-gopher-api",
+			Leeway:              30 * time.Second,
+		},
+	}
+}
+
+func testClaims(cfg *config.Config, tokenType TokenType) Claims {
+	now := time.Now()
+	return Claims{
+		UserId:    "user-id",
+		Email:     "user@example.com",
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.JWT.Issuer,
+			Subject:   "user-id",
+			Audience:  jwt.ClaimStrings{audienceFor(cfg, tokenType)},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiryFor(cfg, tokenType))),
+		},
+	}
+}
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, key any, claims Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestValidateToken(t *testing.T) {
+	cfg := testJWTConfig()
+
+	access, refresh, err := GenerateToken(cfg, "user-id", "user@example.com", "admin", []string{"users:manage"})
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	claims, err := ValidateToken(cfg, access, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("validate access token: %v", err)
+	}
+	if claims.UserId != "user-id" || claims.Role != "admin" || claims.TokenType != TokenTypeAccess {
+		t.Errorf("unexpected access claims: %+v", claims)
+	}
+
+	if _, err := ValidateToken(cfg, refresh, TokenTypeRefresh); err != nil {
+		t.Fatalf("validate refresh token: %v", err)
+	}
+}
+
+func TestValidateTokenRejects(t *testing.T) {
+	cfg := testJWTConfig()
+	secret := []byte(cfg.JWT.Secret)
+
+	_, refresh, err := GenerateToken(cfg, "user-id", "user@example.com", "", nil)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		tokenType TokenType
+		token     func(t *testing.T) string
+		wantErr   error
+	}{
+		{
+			name:      "refresh token used as access token",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				return refresh
+			},
+		},
+		{
+			name:      "refresh typ with access audience",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				claims := testClaims(cfg, TokenTypeAccess)
+				claims.TokenType = TokenTypeRefresh
+				return signTestToken(t, jwt.SigningMethodHS256, secret, claims)
+			},
+			wantErr: ErrInvalidTokenType,
+		},
+		{
+			name:      "wrong audience",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				claims := testClaims(cfg, TokenTypeAccess)
+				claims.Audience = jwt.ClaimStrings{"someone-else"}
+				return signTestToken(t, jwt.SigningMethodHS256, secret, claims)
+			},
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:      "wrong issuer",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				claims := testClaims(cfg, TokenTypeAccess)
+				claims.Issuer = "evil-issuer"
+				return signTestToken(t, jwt.SigningMethodHS256, secret, claims)
+			},
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:      "expired beyond leeway",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				claims := testClaims(cfg, TokenTypeAccess)
+				claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-cfg.JWT.Leeway - time.Minute))
+				return signTestToken(t, jwt.SigningMethodHS256, secret, claims)
+			},
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:      "missing expiry",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				claims := testClaims(cfg, TokenTypeAccess)
+				claims.ExpiresAt = nil
+				return signTestToken(t, jwt.SigningMethodHS256, secret, claims)
+			},
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:      "alg none",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testClaims(cfg, TokenTypeAccess))
+			},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:      "unexpected algorithm",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodHS512, secret, testClaims(cfg, TokenTypeAccess))
+			},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:      "wrong secret",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				return signTestToken(t, jwt.SigningMethodHS256, []byte("other-secret"), testClaims(cfg, TokenTypeAccess))
+			},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:      "tampered signature",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				token := signTestToken(t, jwt.SigningMethodHS256, secret, testClaims(cfg, TokenTypeAccess))
+				i := strings.LastIndex(token, ".") + 1
+				replacement := "A"
+				if token[i] == 'A' {
+					replacement = "B"
+				}
+				return token[:i] + replacement + token[i+1:]
+			},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:      "tampered payload",
+			tokenType: TokenTypeAccess,
+			token: func(t *testing.T) string {
+				token := signTestToken(t, jwt.SigningMethodHS256, secret, testClaims(cfg, TokenTypeAccess))
+				other := testClaims(cfg, TokenTypeAccess)
+				other.UserId = "another-user"
+				forged := signTestToken(t, jwt.SigningMethodHS256, []byte("other-secret"), other)
+				parts, forgedParts := strings.Split(token, "."), strings.Split(forged, ".")
+				return parts[0] + "." + forgedParts[1] + "." + parts[2]
+			},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ValidateToken(cfg, tt.token(t), tt.tokenType)
+			if err == nil {
+				t.Fatalf("expected error, got claims %+v", claims)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
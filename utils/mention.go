@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+// mentionRX matches an @handle that is not part of a longer word, so e-mail
+// addresses like jane@example.com are not taken for mentions.
+var mentionRX = regexp.MustCompile(`(?:^|[^A-Za-z0-9_])@([A-Za-z0-9_]{3,15})`)
+
+// MentionMatch is an @handle found in a piece of text. Start and End are
+// character offsets, not byte offsets, and include the leading @.
+type MentionMatch struct {
+	Handle string
+	Start  int
+	End    int
+}
+
+// FindMentions returns every @handle mentioned in text in the order they appear.
+func FindMentions(text string) []MentionMatch {
+	var matches []MentionMatch
+	for _, loc := range mentionRX.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[2]-1, loc[3]
+		// A handle longer than the limit is not a mention of its prefix.
+		if end < len(text) && isHandleByte(text[end]) {
+			continue
+		}
+		matches = append(matches, MentionMatch{
+			Handle: text[loc[2]:loc[3]],
+			Start:  utf8.RuneCountInString(text[:start]),
+			End:    utf8.RuneCountInString(text[:end]),
+		})
+	}
+	return matches
+}
+
+func isHandleByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// GenerateCodeVerifier returns a random PKCE code verifier (RFC 7636).
+func GenerateCodeVerifier() (string, error) {
+	return GenerateOpaqueToken("")
+}
+
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func VerifyCodeChallengeS256(verifier, challenge string) bool {
+	return subtle.ConstantTimeCompare([]byte(CodeChallengeS256(verifier)), []byte(challenge)) == 1
+}
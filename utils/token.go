@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	PersonalAccessTokenPrefix = "xgp_"
+	OAuthAccessTokenPrefix    = "xgo_"
+	OAuthRefreshTokenPrefix   = "xgr_"
+	OAuthClientSecretPrefix   = "xgs_"
+	OAuthCodePrefix           = "xgc_"
+	MagicLinkTokenPrefix      = "xgm_"
+)
+
+// GenerateOpaqueToken returns a random URL-safe token with the given prefix.
+// Opaque tokens are never stored as-is; persist HashToken(token) instead.
+func GenerateOpaqueToken(prefix string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}